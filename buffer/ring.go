@@ -0,0 +1,197 @@
+package buffer
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/HoyeonS/hephaestus/pkg/hephaestus"
+)
+
+// DefaultMaxEntries is the entry capacity used when no positive limit is configured
+const DefaultMaxEntries = 1000
+
+// minSlots is the number of slots allocated when the first entry is pushed
+const minSlots = 16
+
+// RingBuffer is a FIFO of log entries bounded by entry count and total payload bytes.
+// When either limit is exceeded the oldest entries are evicted. The newest entry is
+// always retained, even when it alone exceeds the byte limit. Slots are allocated as
+// entries arrive, up to the entry limit.
+type RingBuffer struct {
+	mu sync.Mutex
+
+	// entries and sizes are the slots, they grow while every slot is used
+	entries []hephaestus.LogEntry
+	sizes   []int64
+	head    int
+	count   int
+	bytes   int64
+
+	maxEntries int
+	maxBytes   int64
+
+	evictedEntries uint64
+	evictedBytes   uint64
+}
+
+// Stats represents the occupancy and eviction counters of a ring buffer
+type Stats struct {
+	Entries        int    `json:"entries"`
+	Bytes          int64  `json:"bytes"`
+	MaxEntries     int    `json:"max_entries"`
+	MaxBytes       int64  `json:"max_bytes"`
+	EvictedEntries uint64 `json:"evicted_entries"`
+	EvictedBytes   uint64 `json:"evicted_bytes"`
+}
+
+// NewRingBuffer creates a ring buffer holding at most maxEntries entries and maxBytes payload bytes.
+// A non-positive maxBytes disables the byte limit.
+func NewRingBuffer(maxEntries int, maxBytes int64) *RingBuffer {
+	if maxEntries <= 0 {
+		maxEntries = DefaultMaxEntries
+	}
+
+	return &RingBuffer{
+		maxEntries: maxEntries,
+		maxBytes:   maxBytes,
+	}
+}
+
+// Push appends an entry and returns the number of entries evicted to make room for it
+func (b *RingBuffer) Push(entry hephaestus.LogEntry) int {
+	size := EntrySize(entry)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	evicted := 0
+	for b.count > 0 && (b.count == b.maxEntries || (b.maxBytes > 0 && b.bytes+size > b.maxBytes)) {
+		b.evictOldest()
+		evicted++
+	}
+	b.grow()
+
+	tail := (b.head + b.count) % len(b.entries)
+	b.entries[tail] = entry
+	b.sizes[tail] = size
+	b.count++
+	b.bytes += size
+
+	return evicted
+}
+
+// grow doubles the slots once every one is used, up to maxEntries, and moves the
+// entries to the start of the new slots. Callers must hold b.mu.
+func (b *RingBuffer) grow() {
+	if b.count < len(b.entries) || len(b.entries) == b.maxEntries {
+		return
+	}
+
+	size := min(max(2*len(b.entries), minSlots), b.maxEntries)
+	entries := make([]hephaestus.LogEntry, size)
+	sizes := make([]int64, size)
+	for i := 0; i < b.count; i++ {
+		slot := (b.head + i) % len(b.entries)
+		entries[i], sizes[i] = b.entries[slot], b.sizes[slot]
+	}
+	b.entries, b.sizes, b.head = entries, sizes, 0
+}
+
+// evictOldest drops the oldest entry. Callers must hold b.mu.
+func (b *RingBuffer) evictOldest() {
+	size := b.sizes[b.head]
+	// Zero the slot so the evicted entry can be garbage collected
+	b.entries[b.head] = hephaestus.LogEntry{}
+	b.sizes[b.head] = 0
+	b.head = (b.head + 1) % len(b.entries)
+	b.count--
+	b.bytes -= size
+
+	b.evictedEntries++
+	b.evictedBytes += uint64(size)
+}
+
+// Snapshot returns a copy of the buffered entries from oldest to newest
+func (b *RingBuffer) Snapshot() []hephaestus.LogEntry {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.snapshot()
+}
+
+// Drain returns the buffered entries from oldest to newest and empties the buffer
+func (b *RingBuffer) Drain() []hephaestus.LogEntry {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	entries := b.snapshot()
+	b.reset()
+	return entries
+}
+
+// Reset empties the buffer without counting the dropped entries as evictions
+func (b *RingBuffer) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.reset()
+}
+
+// Len returns the number of buffered entries
+func (b *RingBuffer) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.count
+}
+
+// Stats returns the current occupancy and eviction counters
+func (b *RingBuffer) Stats() Stats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return Stats{
+		Entries:        b.count,
+		Bytes:          b.bytes,
+		MaxEntries:     b.maxEntries,
+		MaxBytes:       b.maxBytes,
+		EvictedEntries: b.evictedEntries,
+		EvictedBytes:   b.evictedBytes,
+	}
+}
+
+// snapshot copies the entries in order. Callers must hold b.mu.
+func (b *RingBuffer) snapshot() []hephaestus.LogEntry {
+	entries := make([]hephaestus.LogEntry, b.count)
+	for i := 0; i < b.count; i++ {
+		entries[i] = b.entries[(b.head+i)%len(b.entries)]
+	}
+	return entries
+}
+
+// reset clears the used slots, the others were cleared when their entries were
+// evicted. Callers must hold b.mu.
+func (b *RingBuffer) reset() {
+	for i := 0; i < b.count; i++ {
+		slot := (b.head + i) % len(b.entries)
+		b.entries[slot] = hephaestus.LogEntry{}
+		b.sizes[slot] = 0
+	}
+	b.head = 0
+	b.count = 0
+	b.bytes = 0
+}
+
+// EntrySize approximates the payload size of a log entry in bytes
+func EntrySize(entry hephaestus.LogEntry) int64 {
+	size := len(entry.Level) + len(entry.Message) + len(entry.ErrorTrace)
+	for key, value := range entry.Context {
+		size += len(key)
+		switch v := value.(type) {
+		case string:
+			size += len(v)
+		case []byte:
+			size += len(v)
+		default:
+			size += len(fmt.Sprint(v))
+		}
+	}
+	return int64(size)
+}
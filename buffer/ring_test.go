@@ -0,0 +1,116 @@
+package buffer
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/HoyeonS/hephaestus/pkg/hephaestus"
+	"github.com/stretchr/testify/assert"
+)
+
+func entry(message string) hephaestus.LogEntry {
+	return hephaestus.LogEntry{Message: message}
+}
+
+func messages(entries []hephaestus.LogEntry) []string {
+	result := make([]string, len(entries))
+	for i, e := range entries {
+		result[i] = e.Message
+	}
+	return result
+}
+
+func TestRingBuffer_EntryLimit(t *testing.T) {
+	b := NewRingBuffer(3, 0)
+
+	for i := 0; i < 5; i++ {
+		b.Push(entry(fmt.Sprintf("m%d", i)))
+	}
+
+	assert.Equal(t, []string{"m2", "m3", "m4"}, messages(b.Snapshot()))
+	stats := b.Stats()
+	assert.Equal(t, 3, stats.Entries)
+	assert.Equal(t, uint64(2), stats.EvictedEntries)
+	assert.Equal(t, uint64(4), stats.EvictedBytes)
+}
+
+func TestRingBuffer_ByteLimit(t *testing.T) {
+	b := NewRingBuffer(10, 10)
+
+	assert.Equal(t, 0, b.Push(entry("aaaa")))
+	assert.Equal(t, 0, b.Push(entry("bbbb")))
+	assert.Equal(t, 1, b.Push(entry("cccc")))
+	assert.Equal(t, []string{"bbbb", "cccc"}, messages(b.Snapshot()))
+	assert.Equal(t, int64(8), b.Stats().Bytes)
+
+	// An oversized entry evicts everything else but is itself retained
+	large := strings.Repeat("x", 32)
+	assert.Equal(t, 2, b.Push(entry(large)))
+	assert.Equal(t, []string{large}, messages(b.Snapshot()))
+	assert.Equal(t, uint64(3), b.Stats().EvictedEntries)
+}
+
+func TestRingBuffer_Drain(t *testing.T) {
+	b := NewRingBuffer(2, 0)
+	b.Push(entry("a"))
+	b.Push(entry("b"))
+	b.Push(entry("c"))
+
+	assert.Equal(t, []string{"b", "c"}, messages(b.Drain()))
+	assert.Equal(t, 0, b.Len())
+	assert.Equal(t, int64(0), b.Stats().Bytes)
+
+	// The buffer keeps working after a drain without overshooting the limit
+	for i := 0; i < 5; i++ {
+		b.Push(entry(fmt.Sprintf("n%d", i)))
+	}
+	assert.Equal(t, []string{"n3", "n4"}, messages(b.Snapshot()))
+	assert.Equal(t, uint64(4), b.Stats().EvictedEntries)
+}
+
+func TestRingBuffer_GrowsSlotsLazily(t *testing.T) {
+	b := NewRingBuffer(100, 10*minSlots)
+	assert.Empty(t, b.entries)
+
+	// Under the byte limit the slots stop growing short of the entry limit
+	var want []string
+	for i := 0; i < 3*minSlots; i++ {
+		message := fmt.Sprintf("m%03d", i)
+		b.Push(entry(message))
+		want = append(want, message)
+	}
+	assert.Equal(t, want[len(want)-minSlots*10/4:], messages(b.Snapshot()))
+	assert.Less(t, len(b.entries), 100)
+
+	// Growing past the byte limit keeps the entries in order
+	b.maxBytes = 0
+	for i := 3 * minSlots; i < 200; i++ {
+		message := fmt.Sprintf("m%03d", i)
+		b.Push(entry(message))
+		want = append(want, message)
+	}
+	assert.Equal(t, want[len(want)-100:], messages(b.Snapshot()))
+	assert.Len(t, b.entries, 100)
+
+	// Draining clears the used slots
+	b.Drain()
+	for i := range b.entries {
+		assert.Equal(t, hephaestus.LogEntry{}, b.entries[i])
+	}
+}
+
+func TestRingBuffer_DefaultCapacity(t *testing.T) {
+	b := NewRingBuffer(0, 0)
+	assert.Equal(t, DefaultMaxEntries, b.Stats().MaxEntries)
+}
+
+func TestEntrySize(t *testing.T) {
+	e := hephaestus.LogEntry{
+		Level:      "error",
+		Message:    "boom",
+		ErrorTrace: "trace",
+		Context:    map[string]interface{}{"user": "alice", "attempt": 3},
+	}
+	assert.Equal(t, int64(5+4+5+4+5+7+1), EntrySize(e))
+}
@@ -0,0 +1,390 @@
+// Package ingest turns raw log lines into hephaestus log entries. It understands JSON
+// logs written by zap, logrus and slog, logfmt written by logrus and slog text handlers,
+// and plain text lines starting with a timestamp and a level.
+package ingest
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/HoyeonS/hephaestus/pkg/hephaestus"
+)
+
+// Default keys looked up for each entry field, in order
+var (
+	DefaultTimestampFields = []string{"ts", "time", "timestamp", "@timestamp"}
+	DefaultLevelFields     = []string{"level", "lvl", "severity", "log.level"}
+	DefaultMessageFields   = []string{"msg", "message", "@message"}
+	DefaultStackFields     = []string{"stacktrace", "stack", "stack_trace", "error.stack", "error.stack_trace", "exception"}
+)
+
+// defaultLevel is the level of lines that carry none
+const defaultLevel = "info"
+
+// timestampLayouts are the timestamp layouts tried for string timestamps
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.000Z0700",
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02 15:04:05,999",
+	"2006-01-02T15:04:05.999999999",
+	"2006/01/02 15:04:05.999999",
+	"2006/01/02 15:04:05",
+	time.RFC1123Z,
+	time.RFC1123,
+}
+
+// textTimestamp matches the timestamp a plain text line may start with, as in
+// "2024-01-02 15:04:05,123 ...", "[2024-01-02T15:04:05Z] ..." or "2024/01/02 15:04:05 ..."
+var textTimestamp = regexp.MustCompile(`^\[?(\d{4}[-/]\d{2}[-/]\d{2}[T ]\d{2}:\d{2}:\d{2}(?:[.,]\d+)?(?:Z|[+-]\d{2}:?\d{2})?)\]?\s+`)
+
+// textLevel matches the word that may hold the level of a plain text line, as in
+// "ERROR boom", "[warn] slow query" or "error: boom"
+var textLevel = regexp.MustCompile(`^([\[<]?)([A-Za-z]+)([\]>]?)(:?)\s+`)
+
+// Parser turns single log lines into entries
+type Parser struct {
+	format          hephaestus.LogFormat
+	timestampFields []string
+	levelFields     []string
+	messageFields   []string
+	stackFields     []string
+	defaultLevel    string
+}
+
+// NewParser creates a parser from the log parsing settings
+func NewParser(config hephaestus.ParserConfiguration) (*Parser, error) {
+	if err := hephaestus.ValidateParserConfiguration(config); err != nil {
+		return nil, fmt.Errorf("%w: %v", hephaestus.ErrInvalidConfig, err)
+	}
+
+	p := &Parser{
+		format:          config.Format,
+		timestampFields: orDefault(config.TimestampFields, DefaultTimestampFields),
+		levelFields:     orDefault(config.LevelFields, DefaultLevelFields),
+		messageFields:   orDefault(config.MessageFields, DefaultMessageFields),
+		stackFields:     orDefault(config.StackFields, DefaultStackFields),
+		defaultLevel:    config.DefaultLevel,
+	}
+	if p.format == "" {
+		p.format = hephaestus.LogFormatAuto
+	}
+	if p.defaultLevel == "" {
+		p.defaultLevel = defaultLevel
+	}
+	return p, nil
+}
+
+func orDefault(fields, defaults []string) []string {
+	if len(fields) > 0 {
+		return fields
+	}
+	return defaults
+}
+
+// Parse turns a log line into an entry and reports the format it was read as.
+// Lines that are not valid in the configured format are read as plain text.
+func (p *Parser) Parse(line string) (hephaestus.LogEntry, hephaestus.LogFormat) {
+	line = strings.TrimRight(line, "\r\n")
+	trimmed := strings.TrimSpace(line)
+
+	if p.format == hephaestus.LogFormatAuto || p.format == hephaestus.LogFormatJSON {
+		if strings.HasPrefix(trimmed, "{") {
+			if fields, ok := parseJSON(trimmed); ok {
+				return p.fromFields(fields), hephaestus.LogFormatJSON
+			}
+		}
+	}
+	if p.format == hephaestus.LogFormatAuto || p.format == hephaestus.LogFormatLogfmt {
+		if fields, ok := parseLogfmt(trimmed); ok {
+			return p.fromFields(fields), hephaestus.LogFormatLogfmt
+		}
+	}
+	return p.fromText(trimmed), hephaestus.LogFormatText
+}
+
+// fromFields maps structured fields onto an entry, fields that are not mapped are kept
+// in the entry context
+func (p *Parser) fromFields(fields map[string]interface{}) hephaestus.LogEntry {
+	entry := hephaestus.LogEntry{Level: p.defaultLevel}
+
+	if key, value, ok := lookup(fields, p.timestampFields); ok {
+		if ts, ok := parseTimestamp(value); ok {
+			entry.Timestamp = ts
+			remove(fields, key)
+		}
+	}
+	if key, value, ok := lookup(fields, p.levelFields); ok {
+		if level, ok := value.(string); ok {
+			entry.Level = normalizeLevel(level)
+			remove(fields, key)
+		}
+	}
+	if key, value, ok := lookup(fields, p.messageFields); ok {
+		if message, ok := value.(string); ok {
+			entry.Message = message
+			remove(fields, key)
+		}
+	}
+	if key, value, ok := lookup(fields, p.stackFields); ok {
+		if stack, ok := value.(string); ok {
+			entry.ErrorTrace = stack
+			remove(fields, key)
+		}
+	}
+
+	if len(fields) > 0 {
+		entry.Context = fields
+	}
+	return entry
+}
+
+// fromText reads the timestamp and level a plain text line starts with, the rest is the message
+func (p *Parser) fromText(line string) hephaestus.LogEntry {
+	entry := hephaestus.LogEntry{Level: p.defaultLevel, Message: line}
+
+	rest := line
+	if match := textTimestamp.FindStringSubmatch(line); match != nil {
+		if ts, ok := parseTimestamp(match[1]); ok {
+			entry.Timestamp = ts
+			rest = line[len(match[0]):]
+			entry.Message = rest
+		}
+	}
+
+	// A leading word is only a level when it is marked as one, so a message starting
+	// with "Error" keeps its first word
+	match := textLevel.FindStringSubmatch(rest)
+	if match == nil || !isLevel(match[2]) {
+		return entry
+	}
+	open, word, closing, colon := match[1], match[2], match[3], match[4]
+	bracketed := (open == "[" && closing == "]") || (open == "<" && closing == ">")
+	bare := open == "" && closing == ""
+	if bracketed || (bare && (colon != "" || word == strings.ToUpper(word))) {
+		entry.Level = normalizeLevel(word)
+		entry.Message = rest[len(match[0]):]
+	}
+	return entry
+}
+
+// parseJSON decodes a JSON object keeping numbers exact
+func parseJSON(line string) (map[string]interface{}, bool) {
+	decoder := json.NewDecoder(strings.NewReader(line))
+	decoder.UseNumber()
+
+	var fields map[string]interface{}
+	if err := decoder.Decode(&fields); err != nil || decoder.More() {
+		return nil, false
+	}
+	return normalizeNumbers(fields).(map[string]interface{}), true
+}
+
+// normalizeNumbers converts decoded JSON numbers to int64 when integral and float64 otherwise
+func normalizeNumbers(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = normalizeNumbers(item)
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = normalizeNumbers(item)
+		}
+		return v
+	default:
+		return value
+	}
+}
+
+// parseLogfmt decodes a line of key=value pairs. Values may be double quoted with Go
+// escapes. Lines with fewer than two pairs or any token that is not a pair are rejected
+// so plain text is not mistaken for logfmt.
+func parseLogfmt(line string) (map[string]interface{}, bool) {
+	fields := make(map[string]interface{})
+	rest := line
+	for {
+		rest = strings.TrimLeft(rest, " \t")
+		if rest == "" {
+			break
+		}
+
+		eq := strings.IndexByte(rest, '=')
+		if eq <= 0 || !validLogfmtKey(rest[:eq]) {
+			return nil, false
+		}
+		key := rest[:eq]
+		rest = rest[eq+1:]
+
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			end := closingQuote(rest)
+			if end < 0 {
+				return nil, false
+			}
+			unquoted, err := strconv.Unquote(rest[:end+1])
+			if err != nil {
+				return nil, false
+			}
+			value, rest = unquoted, rest[end+1:]
+			if rest != "" && rest[0] != ' ' && rest[0] != '\t' {
+				return nil, false
+			}
+		} else {
+			end := strings.IndexAny(rest, " \t")
+			if end < 0 {
+				end = len(rest)
+			}
+			value, rest = rest[:end], rest[end:]
+		}
+		fields[key] = value
+	}
+
+	if len(fields) < 2 {
+		return nil, false
+	}
+	return fields, true
+}
+
+// validLogfmtKey reports whether s is a logfmt key
+func validLogfmtKey(s string) bool {
+	for _, r := range s {
+		if r <= ' ' || r == '"' || r == '=' {
+			return false
+		}
+	}
+	return true
+}
+
+// closingQuote returns the index of the quote closing the quoted string s starts with
+func closingQuote(s string) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
+
+// lookup returns the first of keys present in fields. Keys are tried as flat keys first
+// and then as dot separated paths into nested objects.
+func lookup(fields map[string]interface{}, keys []string) (string, interface{}, bool) {
+	for _, key := range keys {
+		if value, ok := fields[key]; ok {
+			return key, value, true
+		}
+		if value, ok := lookupPath(fields, strings.Split(key, ".")); ok {
+			return key, value, true
+		}
+	}
+	return "", nil, false
+}
+
+func lookupPath(fields map[string]interface{}, path []string) (interface{}, bool) {
+	value, ok := fields[path[0]]
+	if !ok || len(path) == 1 {
+		return value, ok
+	}
+	nested, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	return lookupPath(nested, path[1:])
+}
+
+// remove deletes a key found by lookup, dropping nested objects left empty
+func remove(fields map[string]interface{}, key string) {
+	if _, ok := fields[key]; ok {
+		delete(fields, key)
+		return
+	}
+	removePath(fields, strings.Split(key, "."))
+}
+
+func removePath(fields map[string]interface{}, path []string) {
+	if len(path) == 1 {
+		delete(fields, path[0])
+		return
+	}
+	nested, ok := fields[path[0]].(map[string]interface{})
+	if !ok {
+		return
+	}
+	removePath(nested, path[1:])
+	if len(nested) == 0 {
+		delete(fields, path[0])
+	}
+}
+
+// parseTimestamp reads string timestamps in the common layouts and numeric timestamps
+// as Unix epochs, their magnitude telling seconds, milliseconds, microseconds and nanoseconds apart
+func parseTimestamp(value interface{}) (time.Time, bool) {
+	switch v := value.(type) {
+	case string:
+		for _, layout := range timestampLayouts {
+			if ts, err := time.Parse(layout, v); err == nil {
+				return ts, true
+			}
+		}
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return epoch(f), true
+		}
+		return time.Time{}, false
+	case int64:
+		return epoch(float64(v)), true
+	case float64:
+		return epoch(v), true
+	default:
+		return time.Time{}, false
+	}
+}
+
+// epoch converts a Unix timestamp in seconds, milliseconds, microseconds or nanoseconds
+func epoch(value float64) time.Time {
+	switch abs := math.Abs(value); {
+	case abs >= 1e17:
+		return time.Unix(0, int64(value)).UTC()
+	case abs >= 1e14:
+		return time.UnixMicro(int64(value)).UTC()
+	case abs >= 1e11:
+		return time.UnixMilli(int64(value)).UTC()
+	default:
+		sec, frac := math.Modf(value)
+		return time.Unix(int64(sec), int64(frac*1e9)).UTC()
+	}
+}
+
+// isLevel reports whether a word names a log level
+func isLevel(word string) bool {
+	_, err := hephaestus.ParseSeverity(word)
+	return err == nil
+}
+
+// normalizeLevel maps a level onto its canonical severity name. Offsets such as the
+// "ERROR+2" written by slog for custom levels are dropped, unknown levels are kept as is.
+func normalizeLevel(level string) string {
+	if i := strings.IndexAny(level, "+-"); i > 0 {
+		level = level[:i]
+	}
+	if severity, err := hephaestus.ParseSeverity(level); err == nil {
+		return severity.String()
+	}
+	return level
+}
@@ -0,0 +1,143 @@
+package ingest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/HoyeonS/hephaestus/pkg/hephaestus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParser_Parse(t *testing.T) {
+	tests := []struct {
+		name       string
+		line       string
+		wantFormat hephaestus.LogFormat
+		want       hephaestus.LogEntry
+	}{
+		{
+			name:       "zap json",
+			line:       `{"level":"error","ts":1704164645.5,"caller":"api/handler.go:42","msg":"request failed","stacktrace":"main.handle\n\tapi/handler.go:42","attempt":3}`,
+			wantFormat: hephaestus.LogFormatJSON,
+			want: hephaestus.LogEntry{
+				Timestamp:  time.Date(2024, 1, 2, 3, 4, 5, 500000000, time.UTC),
+				Level:      "error",
+				Message:    "request failed",
+				ErrorTrace: "main.handle\n\tapi/handler.go:42",
+				Context:    map[string]interface{}{"caller": "api/handler.go:42", "attempt": int64(3)},
+			},
+		},
+		{
+			name:       "logrus json",
+			line:       `{"level":"warning","msg":"slow query","time":"2024-01-02T03:04:05Z","duration_ms":1.5}`,
+			wantFormat: hephaestus.LogFormatJSON,
+			want: hephaestus.LogEntry{
+				Timestamp: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+				Level:     "warn",
+				Message:   "slow query",
+				Context:   map[string]interface{}{"duration_ms": 1.5},
+			},
+		},
+		{
+			name:       "slog json with custom level",
+			line:       `{"time":"2024-01-02T03:04:05.123Z","level":"ERROR+2","msg":"boom","error":{"stack":"trace"},"user":"u1"}`,
+			wantFormat: hephaestus.LogFormatJSON,
+			want: hephaestus.LogEntry{
+				Timestamp:  time.Date(2024, 1, 2, 3, 4, 5, 123000000, time.UTC),
+				Level:      "error",
+				Message:    "boom",
+				ErrorTrace: "trace",
+				Context:    map[string]interface{}{"user": "u1"},
+			},
+		},
+		{
+			name:       "logfmt",
+			line:       `time=2024-01-02T03:04:05Z level=ERROR msg="connection refused" peer=10.0.0.1:5432`,
+			wantFormat: hephaestus.LogFormatLogfmt,
+			want: hephaestus.LogEntry{
+				Timestamp: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+				Level:     "error",
+				Message:   "connection refused",
+				Context:   map[string]interface{}{"peer": "10.0.0.1:5432"},
+			},
+		},
+		{
+			name:       "plain text with bracketed level",
+			line:       `[2024-01-02 03:04:05] [warn] disk almost full`,
+			wantFormat: hephaestus.LogFormatText,
+			want: hephaestus.LogEntry{
+				Timestamp: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+				Level:     "warn",
+				Message:   "disk almost full",
+			},
+		},
+		{
+			name:       "plain text with upper case level",
+			line:       `2024-01-02 03:04:05,250 ERROR worker crashed`,
+			wantFormat: hephaestus.LogFormatText,
+			want: hephaestus.LogEntry{
+				Timestamp: time.Date(2024, 1, 2, 3, 4, 5, 250000000, time.UTC),
+				Level:     "error",
+				Message:   "worker crashed",
+			},
+		},
+		{
+			name:       "go standard logger",
+			line:       `2024/01/02 03:04:05 Error connecting to database`,
+			wantFormat: hephaestus.LogFormatText,
+			want: hephaestus.LogEntry{
+				Timestamp: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+				Level:     "info",
+				Message:   "Error connecting to database",
+			},
+		},
+		{
+			name:       "plain text without prefix",
+			line:       `something happened = maybe`,
+			wantFormat: hephaestus.LogFormatText,
+			want:       hephaestus.LogEntry{Level: "info", Message: "something happened = maybe"},
+		},
+	}
+
+	parser, err := NewParser(hephaestus.ParserConfiguration{})
+	require.NoError(t, err)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry, format := parser.Parse(tt.line)
+			assert.Equal(t, tt.wantFormat, format)
+			assert.Equal(t, tt.want, entry)
+		})
+	}
+}
+
+func TestParser_FieldOverrides(t *testing.T) {
+	parser, err := NewParser(hephaestus.ParserConfiguration{
+		Format:          hephaestus.LogFormatJSON,
+		TimestampFields: []string{"event.created"},
+		LevelFields:     []string{"sev"},
+		MessageFields:   []string{"text"},
+		DefaultLevel:    "warn",
+	})
+	require.NoError(t, err)
+
+	entry, format := parser.Parse(`{"event":{"created":1704164645000,"id":"e1"},"sev":"fatal","text":"oom","msg":"kept"}`)
+	assert.Equal(t, hephaestus.LogFormatJSON, format)
+	assert.Equal(t, hephaestus.LogEntry{
+		Timestamp: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Level:     "fatal",
+		Message:   "oom",
+		Context:   map[string]interface{}{"event": map[string]interface{}{"id": "e1"}, "msg": "kept"},
+	}, entry)
+
+	// Forced formats read anything else as plain text
+	entry, format = parser.Parse(`level=error msg=boom`)
+	assert.Equal(t, hephaestus.LogFormatText, format)
+	assert.Equal(t, hephaestus.LogEntry{Level: "warn", Message: "level=error msg=boom"}, entry)
+}
+
+func TestNewParser_InvalidConfiguration(t *testing.T) {
+	_, err := NewParser(hephaestus.ParserConfiguration{Format: "xml"})
+	assert.ErrorIs(t, err, hephaestus.ErrInvalidConfig)
+}
@@ -0,0 +1,206 @@
+package ingest
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/HoyeonS/hephaestus/pkg/hephaestus"
+)
+
+const (
+	// maxLineSize is the longest log line kept, longer lines are truncated
+	maxLineSize = 1024 * 1024
+
+	// defaultFlushTimeout is how long a held entry waits for stack trace lines when
+	// no timeout is set
+	defaultFlushTimeout = time.Second
+)
+
+// continuationLine matches the lines of a multi-line stack trace, such as indented
+// frames, Java "Caused by:" chains and the header of Python tracebacks
+var continuationLine = regexp.MustCompile(`^(?:[ \t]|Caused by:|Suppressed:|\.\.\. \d+ more|Traceback \(most recent call last\):)`)
+
+// Reader reads log entries from a line-oriented log stream
+type Reader struct {
+	source       *bufio.Reader
+	assembler    entryAssembler
+	flushTimeout time.Duration
+
+	// lines receives the lines read from source by a background goroutine, so a held
+	// entry can be delivered while the stream is idle
+	lines     chan string
+	done      chan struct{}
+	closeOnce sync.Once
+	// err is the error that ended the stream, set before lines is closed
+	err error
+}
+
+// NewReader creates a reader parsing the lines of r with the given settings
+func NewReader(r io.Reader, config hephaestus.ParserConfiguration) (*Reader, error) {
+	parser, err := NewParser(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Reader{
+		source:       bufio.NewReaderSize(r, 64*1024),
+		assembler:    entryAssembler{parser: parser},
+		flushTimeout: defaultFlushTimeout,
+		done:         make(chan struct{}),
+	}, nil
+}
+
+// SetFlushTimeout sets how long an entry is held waiting for the stack trace lines
+// that may follow it before it is returned. Zero holds it until the next line or the
+// end of the stream. It must be set before the first Read.
+func (r *Reader) SetFlushTimeout(timeout time.Duration) {
+	r.flushTimeout = timeout
+}
+
+// Read returns the next log entry. Empty lines are skipped, and the stack trace lines
+// following a plain text entry, as well as the untimestamped lines following a
+// timestamped one, are added to its error trace. Lines longer than 1 MiB are
+// truncated. Read returns io.EOF once the stream is exhausted.
+func (r *Reader) Read() (hephaestus.LogEntry, error) {
+	if r.lines == nil {
+		r.lines = make(chan string)
+		go r.readLines()
+	}
+
+	for {
+		var timer *time.Timer
+		var flush <-chan time.Time
+		if r.assembler.pending != nil && r.flushTimeout > 0 {
+			timer = time.NewTimer(r.flushTimeout)
+			flush = timer.C
+		}
+
+		select {
+		case line, ok := <-r.lines:
+			if timer != nil {
+				timer.Stop()
+			}
+			if !ok {
+				if entry, ok := r.assembler.flush(); ok {
+					return entry, nil
+				}
+				return hephaestus.LogEntry{}, r.err
+			}
+			if entry, complete, _ := r.assembler.add(line); complete {
+				return entry, nil
+			}
+		case <-flush:
+			entry, _ := r.assembler.flush()
+			return entry, nil
+		}
+	}
+}
+
+// Close stops reading the stream. It does not close the underlying reader, a read
+// blocked on it ends once it returns.
+func (r *Reader) Close() {
+	r.closeOnce.Do(func() { close(r.done) })
+}
+
+// readLines hands the lines of the stream to Read until it ends or the reader is closed
+func (r *Reader) readLines() {
+	defer close(r.lines)
+
+	for {
+		line, err := readLine(r.source)
+		if err == nil || (errors.Is(err, io.EOF) && line != "") {
+			select {
+			case r.lines <- line:
+			case <-r.done:
+				r.err = io.EOF
+				return
+			}
+		}
+		if err != nil {
+			r.err = io.EOF
+			if !errors.Is(err, io.EOF) {
+				r.err = fmt.Errorf("%w: reading log stream: %v", hephaestus.ErrLogError, err)
+			}
+			return
+		}
+	}
+}
+
+// readLine reads the next line without its line ending, keeping at most maxLineSize
+// bytes of it. A last line without a newline is returned with io.EOF.
+func readLine(source *bufio.Reader) (string, error) {
+	var line []byte
+	for {
+		chunk, err := source.ReadSlice('\n')
+		if keep := maxLineSize - len(line); keep > 0 {
+			line = append(line, chunk[:min(len(chunk), keep)]...)
+		}
+		if errors.Is(err, bufio.ErrBufferFull) {
+			continue
+		}
+		return strings.TrimRight(string(line), "\r\n"), err
+	}
+}
+
+// entryAssembler groups the stack trace lines of a stream with the entry they follow.
+// The last entry is held until a line shows it is complete or it is flushed.
+type entryAssembler struct {
+	parser *Parser
+
+	// pending is the entry read ahead, held until the next line shows whether
+	// stack trace lines follow it
+	pending *hephaestus.LogEntry
+	// text is set when the pending entry is a plain text line, which takes the
+	// following stack trace lines as its error trace
+	text bool
+	// timestamped is set when the pending plain text entry has a timestamp, it then
+	// also takes the following lines without one
+	timestamped bool
+}
+
+// add parses a line and returns the entry it completes, if any. started reports
+// whether the line began a new pending entry rather than extending one.
+func (a *entryAssembler) add(line string) (entry hephaestus.LogEntry, complete bool, started bool) {
+	if strings.TrimSpace(line) == "" {
+		return hephaestus.LogEntry{}, false, false
+	}
+
+	parsed, format := a.parser.Parse(line)
+	if a.pending != nil && a.text && (continuationLine.MatchString(line) || (a.timestamped && format == hephaestus.LogFormatText && parsed.Timestamp.IsZero())) {
+		a.pending.ErrorTrace = appendLine(a.pending.ErrorTrace, line)
+		return hephaestus.LogEntry{}, false, false
+	}
+
+	previous := a.pending
+	a.pending = &parsed
+	a.text = format == hephaestus.LogFormatText
+	a.timestamped = a.text && !parsed.Timestamp.IsZero()
+	if previous != nil {
+		return *previous, true, true
+	}
+	return hephaestus.LogEntry{}, false, true
+}
+
+// flush returns the held entry, if any
+func (a *entryAssembler) flush() (hephaestus.LogEntry, bool) {
+	if a.pending == nil {
+		return hephaestus.LogEntry{}, false
+	}
+	entry := *a.pending
+	a.pending = nil
+	a.text, a.timestamped = false, false
+	return entry, true
+}
+
+func appendLine(trace, line string) string {
+	if trace == "" {
+		return line
+	}
+	return trace + "\n" + line
+}
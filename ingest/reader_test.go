@@ -0,0 +1,95 @@
+package ingest
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/HoyeonS/hephaestus/pkg/hephaestus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readAll(t *testing.T, r *Reader) []hephaestus.LogEntry {
+	t.Helper()
+
+	var entries []hephaestus.LogEntry
+	for {
+		entry, err := r.Read()
+		if err == io.EOF {
+			return entries
+		}
+		require.NoError(t, err)
+		entries = append(entries, entry)
+	}
+}
+
+func TestReader_Read(t *testing.T) {
+	input := strings.Join([]string{
+		`{"level":"info","msg":"starting"}`,
+		``,
+		`2024-01-02 03:04:05 ERROR unhandled exception`,
+		`java.lang.IllegalStateException: boom`,
+		`	at com.example.Service.run(Service.java:42)`,
+		`Caused by: java.io.IOException: closed`,
+		`	... 3 more`,
+		`level=warn msg="retrying" attempt=2`,
+		`  indented after structured`,
+	}, "\n")
+
+	reader, err := NewReader(strings.NewReader(input), hephaestus.ParserConfiguration{})
+	require.NoError(t, err)
+	entries := readAll(t, reader)
+
+	require.Len(t, entries, 4)
+	assert.Equal(t, "starting", entries[0].Message)
+	assert.Equal(t, "error", entries[1].Level)
+	assert.Equal(t, "unhandled exception", entries[1].Message)
+	assert.Equal(t, "java.lang.IllegalStateException: boom\n\tat com.example.Service.run(Service.java:42)\nCaused by: java.io.IOException: closed\n\t... 3 more", entries[1].ErrorTrace)
+	assert.Equal(t, "retrying", entries[2].Message)
+	// Structured entries never take continuation lines
+	assert.Equal(t, "indented after structured", entries[3].Message)
+}
+
+func TestReader_GroupsTraceAfterUntimestampedLine(t *testing.T) {
+	input := "ERROR boom\n\tat com.example.Service.run(Service.java:42)\nnext"
+
+	reader, err := NewReader(strings.NewReader(input), hephaestus.ParserConfiguration{})
+	require.NoError(t, err)
+	entries := readAll(t, reader)
+
+	require.Len(t, entries, 2)
+	assert.Equal(t, "\tat com.example.Service.run(Service.java:42)", entries[0].ErrorTrace)
+	// Untimestamped lines only continue timestamped entries
+	assert.Empty(t, entries[1].ErrorTrace)
+}
+
+func TestReader_TruncatesLongLines(t *testing.T) {
+	input := strings.Repeat("x", maxLineSize+10) + "\nafter"
+
+	reader, err := NewReader(strings.NewReader(input), hephaestus.ParserConfiguration{})
+	require.NoError(t, err)
+	entries := readAll(t, reader)
+
+	require.Len(t, entries, 2)
+	assert.Len(t, entries[0].Message, maxLineSize)
+	assert.Equal(t, "after", entries[1].Message)
+}
+
+func TestReader_FlushesHeldEntry(t *testing.T) {
+	source, writer := io.Pipe()
+	defer writer.Close()
+
+	reader, err := NewReader(source, hephaestus.ParserConfiguration{})
+	require.NoError(t, err)
+	reader.SetFlushTimeout(10 * time.Millisecond)
+	defer reader.Close()
+
+	go writer.Write([]byte("2024-01-02 03:04:05 ERROR boom\n"))
+
+	// The stream stays open, the entry is returned once no trace line follows
+	entry, err := reader.Read()
+	require.NoError(t, err)
+	assert.Equal(t, "boom", entry.Message)
+}
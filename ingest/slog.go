@@ -0,0 +1,268 @@
+package ingest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/HoyeonS/hephaestus/pkg/hephaestus"
+)
+
+// SlogHandlerOptions are options for a SlogHandler
+type SlogHandlerOptions struct {
+	// Level is the minimum level of the records forwarded to the node, nil forwards
+	// every record from debug on
+	Level slog.Leveler
+
+	// CaptureStack adds the stack of the logging goroutine as the error trace of
+	// records at or above StackLevel
+	CaptureStack bool
+
+	// StackLevel is the level from which stacks are captured, nil uses error
+	StackLevel slog.Leveler
+}
+
+// SlogHandler is a slog.Handler forwarding records to a node as log entries. Records
+// are handed to the wrapped handler first, so existing output is unchanged.
+type SlogHandler struct {
+	next       slog.Handler
+	sink       LogSink
+	level      slog.Leveler
+	stack      bool
+	stackLevel slog.Leveler
+	// scopes are the attributes and groups added with WithAttrs and WithGroup, in order
+	scopes []slogScope
+}
+
+// slogScope is a group opened with WithGroup or attributes added with WithAttrs
+type slogScope struct {
+	group string
+	attrs []slog.Attr
+}
+
+// NewSlogHandler creates a handler forwarding records to sink and to next. next may be
+// nil to only forward records to the node.
+func NewSlogHandler(next slog.Handler, sink LogSink, opts *SlogHandlerOptions) *SlogHandler {
+	if opts == nil {
+		opts = &SlogHandlerOptions{}
+	}
+
+	h := &SlogHandler{
+		next:       next,
+		sink:       sink,
+		level:      opts.Level,
+		stack:      opts.CaptureStack,
+		stackLevel: opts.StackLevel,
+	}
+	if h.level == nil {
+		h.level = slog.LevelDebug
+	}
+	if h.stackLevel == nil {
+		h.stackLevel = slog.LevelError
+	}
+	return h
+}
+
+// Enabled reports whether the node or the wrapped handler takes records at the level
+func (h *SlogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.level.Level() || (h.next != nil && h.next.Enabled(ctx, level))
+}
+
+// Handle hands the record to the wrapped handler and forwards it to the node
+func (h *SlogHandler) Handle(ctx context.Context, record slog.Record) error {
+	var errs []error
+	if h.next != nil && h.next.Enabled(ctx, record.Level) {
+		errs = append(errs, h.next.Handle(ctx, record))
+	}
+
+	if record.Level >= h.level.Level() {
+		entry := h.entry(record)
+		if h.stack && record.Level >= h.stackLevel.Level() {
+			entry.ErrorTrace = captureStack("log/slog.", "github.com/HoyeonS/hephaestus/ingest.(*SlogHandler)")
+		}
+		errs = append(errs, h.sink.ProcessLog(entry))
+	}
+	return errors.Join(errs...)
+}
+
+// WithAttrs returns a handler adding attrs to every record
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+
+	clone := h.with(slogScope{attrs: attrs})
+	if h.next != nil {
+		clone.next = h.next.WithAttrs(attrs)
+	}
+	return clone
+}
+
+// WithGroup returns a handler nesting the attributes added afterwards under name
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	clone := h.with(slogScope{group: name})
+	if h.next != nil {
+		clone.next = h.next.WithGroup(name)
+	}
+	return clone
+}
+
+func (h *SlogHandler) with(scope slogScope) *SlogHandler {
+	clone := *h
+	clone.scopes = append(h.scopes[:len(h.scopes):len(h.scopes)], scope)
+	return &clone
+}
+
+// entry converts a record to a log entry, attributes are nested in the context by group
+func (h *SlogHandler) entry(record slog.Record) hephaestus.LogEntry {
+	entry := hephaestus.LogEntry{
+		Timestamp: record.Time,
+		Level:     slogLevel(record.Level),
+		Message:   record.Message,
+	}
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now()
+	}
+
+	fields := make(map[string]interface{})
+	current := fields
+	for _, scope := range h.scopes {
+		if scope.group != "" {
+			group := make(map[string]interface{})
+			current[scope.group] = group
+			current = group
+			continue
+		}
+		addSlogAttrs(current, scope.attrs)
+	}
+	record.Attrs(func(attr slog.Attr) bool {
+		addSlogAttrs(current, []slog.Attr{attr})
+		return true
+	})
+
+	pruneEmptyGroups(fields)
+	if len(fields) > 0 {
+		entry.Context = fields
+	}
+	return entry
+}
+
+// addSlogAttrs adds attributes to a context map, following the slog rules: empty
+// attributes are dropped and groups without a key are inlined
+func addSlogAttrs(fields map[string]interface{}, attrs []slog.Attr) {
+	for _, attr := range attrs {
+		value := attr.Value.Resolve()
+		if attr.Equal(slog.Attr{}) {
+			continue
+		}
+
+		if value.Kind() == slog.KindGroup {
+			group := value.Group()
+			if len(group) == 0 {
+				continue
+			}
+			if attr.Key == "" {
+				addSlogAttrs(fields, group)
+				continue
+			}
+			nested := make(map[string]interface{})
+			addSlogAttrs(nested, group)
+			fields[attr.Key] = nested
+			continue
+		}
+		fields[attr.Key] = slogValue(value)
+	}
+}
+
+// slogValue converts a resolved value to a plain Go value
+func slogValue(value slog.Value) interface{} {
+	switch value.Kind() {
+	case slog.KindString:
+		return value.String()
+	case slog.KindInt64:
+		return value.Int64()
+	case slog.KindUint64:
+		return value.Uint64()
+	case slog.KindFloat64:
+		return value.Float64()
+	case slog.KindBool:
+		return value.Bool()
+	case slog.KindDuration:
+		return value.Duration().String()
+	case slog.KindTime:
+		return value.Time()
+	default:
+		if err, ok := value.Any().(error); ok {
+			return err.Error()
+		}
+		return value.Any()
+	}
+}
+
+// pruneEmptyGroups drops groups that received no attributes
+func pruneEmptyGroups(fields map[string]interface{}) {
+	for key, value := range fields {
+		if group, ok := value.(map[string]interface{}); ok {
+			pruneEmptyGroups(group)
+			if len(group) == 0 {
+				delete(fields, key)
+			}
+		}
+	}
+}
+
+// slogLevel maps a slog level onto a severity name, levels between the named ones
+// take the one below
+func slogLevel(level slog.Level) string {
+	switch {
+	case level >= slog.LevelError:
+		return hephaestus.SeverityError.String()
+	case level >= slog.LevelWarn:
+		return hephaestus.SeverityWarn.String()
+	case level >= slog.LevelInfo:
+		return hephaestus.SeverityInfo.String()
+	default:
+		return hephaestus.SeverityDebug.String()
+	}
+}
+
+// captureStack returns the stack of the calling goroutine in the format of a Go panic,
+// without the frames of the functions starting with one of the skipped prefixes
+func captureStack(skip ...string) string {
+	lines := bytes.Split(bytes.TrimSpace(debug.Stack()), []byte("\n"))
+	if len(lines) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.Write(lines[0])
+	skip = append(skip, "runtime/debug.Stack(", "github.com/HoyeonS/hephaestus/ingest.captureStack(")
+
+	// Frames are a function line followed by an indented location line
+	for i := 1; i+1 < len(lines); i += 2 {
+		function := string(lines[i])
+		skipped := false
+		for _, prefix := range skip {
+			if strings.HasPrefix(function, prefix) {
+				skipped = true
+				break
+			}
+		}
+		if skipped {
+			continue
+		}
+		b.WriteByte('\n')
+		b.WriteString(function)
+		b.WriteByte('\n')
+		b.Write(lines[i+1])
+	}
+	return b.String()
+}
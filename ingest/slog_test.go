@@ -0,0 +1,151 @@
+package ingest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/HoyeonS/hephaestus/pkg/hephaestus"
+	"github.com/HoyeonS/hephaestus/pkg/hephaestus/stacktrace"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// entrySink records processed entries
+type entrySink struct {
+	mu      sync.Mutex
+	entries []hephaestus.LogEntry
+	err     error
+}
+
+func (s *entrySink) ProcessLog(entry hephaestus.LogEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, entry)
+	return s.err
+}
+
+func (s *entrySink) all() []hephaestus.LogEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]hephaestus.LogEntry(nil), s.entries...)
+}
+
+// userID is a slog.LogValuer resolved when the record is handled
+type userID string
+
+func (u userID) LogValue() slog.Value {
+	return slog.StringValue("user-" + string(u))
+}
+
+func TestSlogHandler_Context(t *testing.T) {
+	tests := []struct {
+		name string
+		log  func(logger *slog.Logger)
+		want map[string]interface{}
+	}{
+		{
+			name: "attributes",
+			log: func(logger *slog.Logger) {
+				logger.Error("boom", "attempt", 3, "ok", false, "err", errors.New("refused"), "user", userID("42"))
+			},
+			want: map[string]interface{}{"attempt": int64(3), "ok": false, "err": "refused", "user": "user-42"},
+		},
+		{
+			name: "handler attributes and groups",
+			log: func(logger *slog.Logger) {
+				logger.With("service", "checkout").WithGroup("request").With("id", "r1").Error("boom", slog.Group("db", "table", "orders"))
+			},
+			want: map[string]interface{}{
+				"service": "checkout",
+				"request": map[string]interface{}{"id": "r1", "db": map[string]interface{}{"table": "orders"}},
+			},
+		},
+		{
+			name: "inline and empty groups",
+			log: func(logger *slog.Logger) {
+				logger.WithGroup("unused").Error("boom", slog.Group("", "inlined", 1), slog.Group("empty"), slog.Attr{})
+			},
+			want: map[string]interface{}{"unused": map[string]interface{}{"inlined": int64(1)}},
+		},
+		{
+			name: "no attributes",
+			log: func(logger *slog.Logger) {
+				logger.WithGroup("unused").Error("boom")
+			},
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink := &entrySink{}
+			tt.log(slog.New(NewSlogHandler(nil, sink, nil)))
+
+			entries := sink.all()
+			require.Len(t, entries, 1)
+			assert.Equal(t, "error", entries[0].Level)
+			assert.Equal(t, "boom", entries[0].Message)
+			assert.False(t, entries[0].Timestamp.IsZero())
+			assert.Equal(t, tt.want, entries[0].Context)
+		})
+	}
+}
+
+func TestSlogHandler_WrapsHandler(t *testing.T) {
+	var out bytes.Buffer
+	sink := &entrySink{}
+	next := slog.NewTextHandler(&out, &slog.HandlerOptions{Level: slog.LevelInfo})
+	logger := slog.New(NewSlogHandler(next, sink, &SlogHandlerOptions{Level: slog.LevelWarn}))
+
+	logger.Debug("dropped")
+	logger.Info("written only")
+	logger.Warn("both", "key", "value")
+	logger.Log(context.Background(), slog.LevelError+4, "critical")
+
+	assert.Contains(t, out.String(), "msg=\"written only\"")
+	assert.Contains(t, out.String(), "msg=both key=value")
+	assert.NotContains(t, out.String(), "dropped")
+
+	entries := sink.all()
+	require.Len(t, entries, 2)
+	assert.Equal(t, "warn", entries[0].Level)
+	assert.Equal(t, "both", entries[0].Message)
+	assert.Equal(t, "error", entries[1].Level)
+}
+
+func TestSlogHandler_CaptureStack(t *testing.T) {
+	sink := &entrySink{}
+	logger := slog.New(NewSlogHandler(nil, sink, &SlogHandlerOptions{CaptureStack: true}))
+
+	logger.Warn("no stack")
+	logger.Error("with stack")
+
+	entries := sink.all()
+	require.Len(t, entries, 2)
+	assert.Empty(t, entries[0].ErrorTrace)
+
+	trace := entries[1].ErrorTrace
+	assert.True(t, strings.HasPrefix(trace, "goroutine "), trace)
+	assert.Contains(t, trace, "ingest.TestSlogHandler_CaptureStack(")
+	assert.NotContains(t, trace, "log/slog.")
+	assert.NotContains(t, trace, "captureStack")
+
+	// The trace reads as a Go stack so solution flows can pick the files to fetch
+	parsed := stacktrace.Parse(trace)
+	require.NotEmpty(t, parsed.Frames())
+	assert.Equal(t, "TestSlogHandler_CaptureStack", parsed.Frames()[0].Function)
+}
+
+func TestSlogHandler_ReturnsSinkErrors(t *testing.T) {
+	sink := &entrySink{err: hephaestus.ErrNodeError}
+	handler := NewSlogHandler(nil, sink, nil)
+
+	err := handler.Handle(context.Background(), slog.NewRecord(time.Now(), slog.LevelError, "boom", 0))
+	assert.ErrorIs(t, err, hephaestus.ErrNodeError)
+}
@@ -0,0 +1,303 @@
+package ingest
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/HoyeonS/hephaestus/pkg/hephaestus"
+)
+
+const (
+	// SyslogContextKey is the context key holding the syslog header fields of an entry
+	SyslogContextKey = "syslog"
+
+	// StructuredDataContextKey is the context key holding the structured data elements
+	// of an entry by SD-ID
+	StructuredDataContextKey = "structured_data"
+)
+
+// syslogNil is the RFC 5424 NILVALUE
+const syslogNil = "-"
+
+// utf8BOM may prefix RFC 5424 messages
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// syslogFacilities are the facility names by code
+var syslogFacilities = []string{
+	"kern", "user", "mail", "daemon", "auth", "syslog", "lpr", "news",
+	"uucp", "cron", "authpriv", "ftp", "ntp", "security", "console", "solaris-cron",
+	"local0", "local1", "local2", "local3", "local4", "local5", "local6", "local7",
+}
+
+// SyslogMessage is a parsed RFC 5424 or RFC 3164 message. Header fields the
+// message does not carry are empty.
+type SyslogMessage struct {
+	Facility  int
+	Severity  int
+	Timestamp time.Time
+	Hostname  string
+	AppName   string
+	ProcID    string
+	MsgID     string
+	// StructuredData holds the parameters of each structured data element by SD-ID.
+	// A parameter given more than once holds all its values.
+	StructuredData map[string]map[string]interface{}
+	Message        string
+}
+
+// ParseSyslog parses an RFC 5424 or RFC 3164 message. now fills in the timestamp of
+// messages without one and the year of RFC 3164 timestamps.
+func ParseSyslog(data []byte, now time.Time) (*SyslogMessage, error) {
+	data = bytes.TrimRight(data, "\r\n\x00")
+
+	priority, rest, err := parsePriority(string(data))
+	if err != nil {
+		return nil, err
+	}
+	msg := &SyslogMessage{Facility: priority / 8, Severity: priority % 8}
+
+	if strings.HasPrefix(rest, "1 ") {
+		err = msg.parseRFC5424(rest[2:], now)
+	} else {
+		msg.parseRFC3164(rest, now)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// parsePriority reads the <PRI> part starting every message
+func parsePriority(s string) (int, string, error) {
+	end := strings.IndexByte(s, '>')
+	if !strings.HasPrefix(s, "<") || end < 2 || end > 4 {
+		return 0, "", fmt.Errorf("%w: syslog message without priority", hephaestus.ErrInvalidArgument)
+	}
+	priority, err := strconv.Atoi(s[1:end])
+	if err != nil || priority < 0 || priority > 191 {
+		return 0, "", fmt.Errorf("%w: invalid syslog priority %q", hephaestus.ErrInvalidArgument, s[1:end])
+	}
+	return priority, s[end+1:], nil
+}
+
+// parseRFC5424 reads the header, structured data and message following "<PRI>1 "
+func (m *SyslogMessage) parseRFC5424(s string, now time.Time) error {
+	var header [5]string
+	for i := range header {
+		field, rest, ok := strings.Cut(s, " ")
+		if !ok {
+			return fmt.Errorf("%w: truncated RFC 5424 header", hephaestus.ErrInvalidArgument)
+		}
+		if field != syslogNil {
+			header[i] = field
+		}
+		s = rest
+	}
+
+	m.Timestamp = now
+	if header[0] != "" {
+		ts, err := time.Parse(time.RFC3339Nano, header[0])
+		if err != nil {
+			return fmt.Errorf("%w: invalid RFC 5424 timestamp %q", hephaestus.ErrInvalidArgument, header[0])
+		}
+		m.Timestamp = ts
+	}
+	m.Hostname, m.AppName, m.ProcID, m.MsgID = header[1], header[2], header[3], header[4]
+
+	rest, err := m.parseStructuredData(s)
+	if err != nil {
+		return err
+	}
+	if rest != "" {
+		if rest[0] != ' ' {
+			return fmt.Errorf("%w: invalid RFC 5424 structured data", hephaestus.ErrInvalidArgument)
+		}
+		m.Message = strings.TrimPrefix(rest[1:], string(utf8BOM))
+	}
+	return nil
+}
+
+// parseStructuredData reads the structured data elements and returns the rest
+func (m *SyslogMessage) parseStructuredData(s string) (string, error) {
+	if strings.HasPrefix(s, syslogNil) {
+		return s[1:], nil
+	}
+
+	invalid := fmt.Errorf("%w: invalid RFC 5424 structured data", hephaestus.ErrInvalidArgument)
+	for strings.HasPrefix(s, "[") {
+		end := strings.IndexAny(s, " ]")
+		if end < 2 {
+			return "", invalid
+		}
+		id := s[1:end]
+		params := make(map[string]interface{})
+		s = s[end:]
+
+		for strings.HasPrefix(s, " ") {
+			name, rest, ok := strings.Cut(s[1:], `="`)
+			if !ok || name == "" {
+				return "", invalid
+			}
+			value, rest, ok := readParamValue(rest)
+			if !ok {
+				return "", invalid
+			}
+			addParam(params, name, value)
+			s = rest
+		}
+		if !strings.HasPrefix(s, "]") {
+			return "", invalid
+		}
+		s = s[1:]
+
+		if m.StructuredData == nil {
+			m.StructuredData = make(map[string]map[string]interface{})
+		}
+		m.StructuredData[id] = params
+	}
+	if m.StructuredData == nil {
+		return "", invalid
+	}
+	return s, nil
+}
+
+// readParamValue reads a parameter value up to its closing quote, unescaping \", \\ and \]
+func readParamValue(s string) (string, string, bool) {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\\' && i+1 < len(s) && strings.IndexByte(`"\]`, s[i+1]) >= 0:
+			b.WriteByte(s[i+1])
+			i++
+		case c == '"':
+			return b.String(), s[i+1:], true
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", "", false
+}
+
+// addParam adds a parameter value, collecting the values of repeated parameters
+func addParam(params map[string]interface{}, name, value string) {
+	switch existing := params[name].(type) {
+	case nil:
+		params[name] = value
+	case string:
+		params[name] = []string{existing, value}
+	case []string:
+		params[name] = append(existing, value)
+	}
+}
+
+// parseRFC3164 reads the "Mmm dd hh:mm:ss HOSTNAME TAG[PID]: MSG" layout. Like
+// RFC 3164 relays, parts that do not follow the layout are kept in the message.
+func (m *SyslogMessage) parseRFC3164(s string, now time.Time) {
+	m.Timestamp = now
+	m.Message = s
+
+	rest, ok := m.parseRFC3164Timestamp(s, now)
+	if !ok {
+		return
+	}
+
+	// The hostname is left out by some senders, a tag is recognized by its colon or PID
+	if field, after, ok := strings.Cut(rest, " "); ok && !isSyslogTag(field) {
+		m.Hostname = field
+		rest = after
+	}
+
+	m.Message = rest
+	field, after, ok := strings.Cut(rest, " ")
+	if !ok {
+		field, after = rest, ""
+	}
+	if !isSyslogTag(field) {
+		return
+	}
+	tag := strings.TrimSuffix(field, ":")
+	if open := strings.IndexByte(tag, '['); open > 0 && strings.HasSuffix(tag, "]") {
+		m.ProcID = tag[open+1 : len(tag)-1]
+		tag = tag[:open]
+	}
+	m.AppName = tag
+	m.Message = after
+}
+
+// parseRFC3164Timestamp reads the timestamp at the start of s, in the RFC 3164 layout
+// or as RFC 3339 as sent by newer daemons. RFC 3164 timestamps carry no year, the one
+// placing them closest before now is used. The year is parsed along with the rest so
+// Feb 29 is only accepted in leap years.
+func (m *SyslogMessage) parseRFC3164Timestamp(s string, now time.Time) (string, bool) {
+	if len(s) > len(time.Stamp) && s[len(time.Stamp)] == ' ' {
+		for _, year := range []int{now.Year(), now.Year() - 1} {
+			stamp := strconv.Itoa(year) + " " + s[:len(time.Stamp)]
+			ts, err := time.ParseInLocation("2006 "+time.Stamp, stamp, now.Location())
+			if err != nil || ts.After(now.Add(24*time.Hour)) {
+				continue
+			}
+			m.Timestamp = ts
+			return s[len(time.Stamp)+1:], true
+		}
+	}
+
+	if field, rest, ok := strings.Cut(s, " "); ok {
+		if ts, err := time.Parse(time.RFC3339Nano, field); err == nil {
+			m.Timestamp = ts
+			return rest, true
+		}
+	}
+	return s, false
+}
+
+// isSyslogTag reports whether a word is an RFC 3164 tag such as "sshd[42]:" or "cron:"
+func isSyslogTag(word string) bool {
+	return strings.HasSuffix(word, ":") || (strings.Contains(word, "[") && strings.HasSuffix(word, "]"))
+}
+
+// FacilityName returns the name of the facility, or its code when it has none
+func (m *SyslogMessage) FacilityName() string {
+	if m.Facility >= 0 && m.Facility < len(syslogFacilities) {
+		return syslogFacilities[m.Facility]
+	}
+	return strconv.Itoa(m.Facility)
+}
+
+// LogEntry converts the message to a log entry. The severity gives the level, the
+// header fields are kept under the "syslog" context key and the structured data
+// elements by SD-ID under the "structured_data" key.
+func (m *SyslogMessage) LogEntry() hephaestus.LogEntry {
+	header := map[string]interface{}{
+		"facility": m.FacilityName(),
+		"severity": m.Severity,
+	}
+	for key, value := range map[string]string{
+		"hostname": m.Hostname,
+		"app_name": m.AppName,
+		"proc_id":  m.ProcID,
+		"msg_id":   m.MsgID,
+	} {
+		if value != "" {
+			header[key] = value
+		}
+	}
+
+	fields := map[string]interface{}{SyslogContextKey: header}
+	if len(m.StructuredData) > 0 {
+		elements := make(map[string]interface{}, len(m.StructuredData))
+		for id, params := range m.StructuredData {
+			elements[id] = params
+		}
+		fields[StructuredDataContextKey] = elements
+	}
+
+	return hephaestus.LogEntry{
+		Timestamp: m.Timestamp,
+		Level:     hephaestus.SeverityFromSyslog(m.Severity).String(),
+		Message:   m.Message,
+		Context:   fields,
+	}
+}
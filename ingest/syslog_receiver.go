@@ -0,0 +1,386 @@
+package ingest
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/HoyeonS/hephaestus/pkg/hephaestus"
+	"go.uber.org/zap"
+)
+
+const (
+	// defaultSyslogMessageSize is the largest message accepted when no size is set
+	defaultSyslogMessageSize = 64 * 1024
+
+	// defaultSyslogConnections is the number of open TCP connections allowed when no
+	// limit is set
+	defaultSyslogConnections = 1024
+
+	// defaultSyslogIdleTimeout is how long a TCP connection may stay silent when no
+	// timeout is set
+	defaultSyslogIdleTimeout = 5 * time.Minute
+)
+
+// NodeResolver looks up nodes by ID, a node manager is one
+type NodeResolver interface {
+	GetNode(nodeID string) (hephaestus.ClientNode, error)
+}
+
+// SyslogReceiver receives syslog messages over UDP and TCP and hands them to the
+// nodes selected by the routes. TCP connections may frame messages by octet counting
+// or by newlines (RFC 6587), message by message.
+type SyslogReceiver struct {
+	config      hephaestus.SyslogConfiguration
+	nodes       NodeResolver
+	maxSize     int
+	maxConns    int
+	idleTimeout time.Duration
+	logger      *zap.Logger
+	now         func() time.Time
+
+	mu       sync.Mutex
+	udp      net.PacketConn
+	tcp      net.Listener
+	conns    map[net.Conn]struct{}
+	started  bool
+	stopped  bool
+	stopping chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewSyslogReceiver creates a receiver routing messages to the nodes found by nodes
+func NewSyslogReceiver(config hephaestus.SyslogConfiguration, nodes NodeResolver) (*SyslogReceiver, error) {
+	if err := hephaestus.ValidateSyslogConfiguration(config); err != nil {
+		return nil, fmt.Errorf("%w: %v", hephaestus.ErrInvalidConfig, err)
+	}
+	if config.UDPAddress == "" && config.TCPAddress == "" {
+		return nil, fmt.Errorf("%w: no syslog address to listen on", hephaestus.ErrInvalidConfig)
+	}
+	if nodes == nil {
+		return nil, fmt.Errorf("%w: node resolver is required", hephaestus.ErrInvalidArgument)
+	}
+
+	r := &SyslogReceiver{
+		config:      config,
+		nodes:       nodes,
+		maxSize:     config.MaxMessageSize,
+		maxConns:    config.MaxConnections,
+		idleTimeout: config.IdleTimeout,
+		logger:      zap.NewNop(),
+		now:         time.Now,
+		conns:       make(map[net.Conn]struct{}),
+		stopping:    make(chan struct{}),
+	}
+	if r.maxSize == 0 {
+		r.maxSize = defaultSyslogMessageSize
+	}
+	if r.maxConns == 0 {
+		r.maxConns = defaultSyslogConnections
+	}
+	if r.idleTimeout == 0 {
+		r.idleTimeout = defaultSyslogIdleTimeout
+	}
+	return r, nil
+}
+
+// SetLogger sets the logger for the receiver
+func (r *SyslogReceiver) SetLogger(logger *zap.Logger) {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.logger = logger
+}
+
+// Start listens on the configured addresses and receives messages until ctx is done
+// or Stop is called
+func (r *SyslogReceiver) Start(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.started {
+		return fmt.Errorf("%w: syslog receiver already started", hephaestus.ErrInvalidArgument)
+	}
+
+	if r.config.UDPAddress != "" {
+		udp, err := net.ListenPacket("udp", r.config.UDPAddress)
+		if err != nil {
+			return fmt.Errorf("%w: listening on udp %s: %v", hephaestus.ErrUnavailable, r.config.UDPAddress, err)
+		}
+		r.udp = udp
+	}
+	if r.config.TCPAddress != "" {
+		tcp, err := net.Listen("tcp", r.config.TCPAddress)
+		if err != nil {
+			if r.udp != nil {
+				r.udp.Close()
+				r.udp = nil
+			}
+			return fmt.Errorf("%w: listening on tcp %s: %v", hephaestus.ErrUnavailable, r.config.TCPAddress, err)
+		}
+		r.tcp = tcp
+	}
+	r.started = true
+
+	if r.udp != nil {
+		r.wg.Add(1)
+		go r.serveUDP()
+	}
+	if r.tcp != nil {
+		r.wg.Add(1)
+		go r.serveTCP()
+	}
+	go func() {
+		select {
+		case <-ctx.Done():
+			r.Stop()
+		case <-r.stopping:
+		}
+	}()
+	return nil
+}
+
+// Stop closes the listeners and connections and waits for the messages being handled
+func (r *SyslogReceiver) Stop() error {
+	r.mu.Lock()
+	if !r.started || r.stopped {
+		r.mu.Unlock()
+		return nil
+	}
+	r.stopped = true
+	close(r.stopping)
+
+	var errs []error
+	if r.udp != nil {
+		errs = append(errs, r.udp.Close())
+	}
+	if r.tcp != nil {
+		errs = append(errs, r.tcp.Close())
+	}
+	for conn := range r.conns {
+		conn.Close()
+	}
+	r.mu.Unlock()
+
+	r.wg.Wait()
+	return errors.Join(errs...)
+}
+
+// UDPAddr returns the address datagrams are received on, nil when UDP is not listening
+func (r *SyslogReceiver) UDPAddr() net.Addr {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.udp == nil {
+		return nil
+	}
+	return r.udp.LocalAddr()
+}
+
+// TCPAddr returns the address connections are accepted on, nil when TCP is not listening
+func (r *SyslogReceiver) TCPAddr() net.Addr {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.tcp == nil {
+		return nil
+	}
+	return r.tcp.Addr()
+}
+
+// serveUDP handles every datagram as one message, dropping those longer than the
+// largest message size
+func (r *SyslogReceiver) serveUDP() {
+	defer r.wg.Done()
+
+	// One spare byte tells a datagram of the largest size from a longer one the read
+	// truncated
+	buf := make([]byte, r.maxSize+1)
+	for {
+		n, addr, err := r.udp.ReadFrom(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			r.log().Warn("Failed to read syslog datagram", zap.Error(err))
+			continue
+		}
+		if n > r.maxSize {
+			r.log().Warn("Dropped oversized syslog datagram", zap.Stringer("remote", addr), zap.Int("max_size", r.maxSize))
+			continue
+		}
+		r.handle(buf[:n], addr)
+	}
+}
+
+// serveTCP accepts connections until the listener is closed, closing those over the
+// connection limit right away
+func (r *SyslogReceiver) serveTCP() {
+	defer r.wg.Done()
+
+	for {
+		conn, err := r.tcp.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			r.log().Warn("Failed to accept syslog connection", zap.Error(err))
+			continue
+		}
+
+		r.mu.Lock()
+		if r.stopped {
+			r.mu.Unlock()
+			conn.Close()
+			return
+		}
+		if len(r.conns) >= r.maxConns {
+			r.mu.Unlock()
+			r.log().Warn("Rejected syslog connection over the limit", zap.Stringer("remote", conn.RemoteAddr()), zap.Int("max_connections", r.maxConns))
+			conn.Close()
+			continue
+		}
+		r.conns[conn] = struct{}{}
+		r.wg.Add(1)
+		r.mu.Unlock()
+
+		go r.serveConn(conn)
+	}
+}
+
+// serveConn handles the messages of a connection until it is closed, stays idle for
+// longer than the idle timeout or sends a message that cannot be framed
+func (r *SyslogReceiver) serveConn(conn net.Conn) {
+	defer r.wg.Done()
+	defer func() {
+		r.mu.Lock()
+		delete(r.conns, conn)
+		r.mu.Unlock()
+		conn.Close()
+	}()
+
+	reader := bufio.NewReaderSize(conn, r.maxSize)
+	for {
+		if err := conn.SetReadDeadline(time.Now().Add(r.idleTimeout)); err != nil {
+			return
+		}
+		frame, err := readSyslogFrame(reader, r.maxSize)
+		if len(frame) > 0 {
+			r.handle(frame, conn.RemoteAddr())
+		}
+		if err != nil {
+			if errors.Is(err, os.ErrDeadlineExceeded) {
+				r.log().Debug("Closing idle syslog connection", zap.Stringer("remote", conn.RemoteAddr()))
+			} else if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+				r.log().Warn("Closing syslog connection", zap.Stringer("remote", conn.RemoteAddr()), zap.Error(err))
+			}
+			return
+		}
+	}
+}
+
+// readSyslogFrame reads the next message of a TCP stream. A message starting with a
+// digit is octet counted ("LEN SP MSG"), any other message ends at a newline.
+func readSyslogFrame(reader *bufio.Reader, maxSize int) ([]byte, error) {
+	first, err := reader.Peek(1)
+	if err != nil {
+		return nil, err
+	}
+
+	if first[0] >= '0' && first[0] <= '9' {
+		prefix, err := reader.ReadSlice(' ')
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid syslog octet count", hephaestus.ErrInvalidArgument)
+		}
+		size, err := strconv.Atoi(string(prefix[:len(prefix)-1]))
+		if err != nil || size <= 0 {
+			return nil, fmt.Errorf("%w: invalid syslog octet count %q", hephaestus.ErrInvalidArgument, prefix)
+		}
+		if size > maxSize {
+			return nil, fmt.Errorf("%w: syslog message of %d bytes exceeds %d", hephaestus.ErrInvalidArgument, size, maxSize)
+		}
+		frame := make([]byte, size)
+		if _, err := io.ReadFull(reader, frame); err != nil {
+			return nil, err
+		}
+		return frame, nil
+	}
+
+	line, err := reader.ReadSlice('\n')
+	if errors.Is(err, bufio.ErrBufferFull) {
+		return nil, fmt.Errorf("%w: syslog message exceeds %d bytes", hephaestus.ErrInvalidArgument, maxSize)
+	}
+	// A last message without a newline ends with the stream
+	return bytes.Clone(bytes.TrimRight(line, "\r\n")), err
+}
+
+// handle parses a message and hands it to the node it is routed to
+func (r *SyslogReceiver) handle(data []byte, remote net.Addr) {
+	msg, err := ParseSyslog(data, r.now())
+	if err != nil {
+		r.log().Debug("Dropped invalid syslog message", zap.Stringer("remote", remote), zap.Error(err))
+		return
+	}
+	if msg.Hostname == "" && remote != nil {
+		msg.Hostname = remoteHost(remote)
+	}
+
+	nodeID := r.route(msg)
+	if nodeID == "" {
+		r.log().Debug("Dropped unrouted syslog message", zap.String("hostname", msg.Hostname), zap.String("app_name", msg.AppName))
+		return
+	}
+
+	node, err := r.nodes.GetNode(nodeID)
+	if err != nil {
+		r.log().Warn("Failed to route syslog message", zap.String("node_id", nodeID), zap.Error(err))
+		return
+	}
+	if err := node.ProcessLog(msg.LogEntry()); err != nil {
+		r.log().Warn("Failed to process syslog message", zap.String("node_id", nodeID), zap.Error(err))
+	}
+}
+
+// route returns the node of the first route matching the message, or the default node
+func (r *SyslogReceiver) route(msg *SyslogMessage) string {
+	for _, route := range r.config.Routes {
+		if matchPattern(route.Hostname, msg.Hostname) && matchPattern(route.AppName, msg.AppName) {
+			return route.NodeID
+		}
+	}
+	return r.config.DefaultNodeID
+}
+
+func matchPattern(pattern, value string) bool {
+	if pattern == "" {
+		return true
+	}
+	matched, _ := path.Match(pattern, value)
+	return matched
+}
+
+func remoteHost(addr net.Addr) string {
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return addr.String()
+	}
+	return host
+}
+
+func (r *SyslogReceiver) log() *zap.Logger {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.logger
+}
@@ -0,0 +1,229 @@
+package ingest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/HoyeonS/hephaestus/pkg/hephaestus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClientNode records the entries it processes
+type fakeClientNode struct {
+	hephaestus.ClientNode
+	entrySink
+}
+
+func (n *fakeClientNode) ProcessLog(entry hephaestus.LogEntry) error {
+	return n.entrySink.ProcessLog(entry)
+}
+
+// fakeResolver returns fake nodes by ID
+type fakeResolver map[string]*fakeClientNode
+
+func (r fakeResolver) GetNode(nodeID string) (hephaestus.ClientNode, error) {
+	node, ok := r[nodeID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", hephaestus.ErrNodeNotFound, nodeID)
+	}
+	return node, nil
+}
+
+func (r fakeResolver) messages(nodeID string) []string {
+	var messages []string
+	for _, entry := range r[nodeID].all() {
+		messages = append(messages, entry.Message)
+	}
+	return messages
+}
+
+func startReceiver(t *testing.T, config hephaestus.SyslogConfiguration, nodes NodeResolver) *SyslogReceiver {
+	t.Helper()
+
+	receiver, err := NewSyslogReceiver(config, nodes)
+	require.NoError(t, err)
+	require.NoError(t, receiver.Start(context.Background()))
+	t.Cleanup(func() { receiver.Stop() })
+	return receiver
+}
+
+func TestSyslogReceiver_Routing(t *testing.T) {
+	nodes := fakeResolver{"payments": {}, "web": {}, "default": {}}
+	receiver := startReceiver(t, hephaestus.SyslogConfiguration{
+		UDPAddress: "127.0.0.1:0",
+		Routes: []hephaestus.SyslogRoute{
+			{AppName: "pay*", NodeID: "payments"},
+			{Hostname: "web-?", NodeID: "web"},
+			{Hostname: "db", AppName: "postgres", NodeID: "missing"},
+		},
+		DefaultNodeID: "default",
+	}, nodes)
+
+	conn, err := net.Dial("udp", receiver.UDPAddr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	for _, msg := range []string{
+		"<11>1 - web-1 payments-api - - - charge failed",
+		"<11>1 - web-2 nginx - - - bad gateway",
+		"<11>1 - db postgres - - - lost",
+		"<11>1 - db other - - - fallback",
+		"not syslog",
+	} {
+		_, err := conn.Write([]byte(msg))
+		require.NoError(t, err)
+	}
+
+	require.Eventually(t, func() bool { return len(nodes.messages("default")) == 1 }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, []string{"charge failed"}, nodes.messages("payments"))
+	assert.Equal(t, []string{"bad gateway"}, nodes.messages("web"))
+	assert.Equal(t, []string{"fallback"}, nodes.messages("default"))
+}
+
+func TestSyslogReceiver_TCPFraming(t *testing.T) {
+	nodes := fakeResolver{"app": {}}
+	receiver := startReceiver(t, hephaestus.SyslogConfiguration{TCPAddress: "127.0.0.1:0", DefaultNodeID: "app"}, nodes)
+
+	conn, err := net.Dial("tcp", receiver.TCPAddr().String())
+	require.NoError(t, err)
+
+	counted := "<11>1 - host app - - - multi\nline"
+	stream := fmt.Sprintf("%d %s<13>Jan  5 11:00:00 host cron: newline framed\r\n%d %s<14>last", len(counted), counted, len(counted), counted)
+	_, err = conn.Write([]byte(stream))
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+
+	require.Eventually(t, func() bool { return len(nodes.messages("app")) == 4 }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, []string{"multi\nline", "newline framed", "multi\nline", "last"}, nodes.messages("app"))
+
+	entry := nodes["app"].all()[0]
+	assert.Equal(t, "error", entry.Level)
+	assert.Equal(t, "host", entry.Context[SyslogContextKey].(map[string]interface{})["hostname"])
+}
+
+func TestSyslogReceiver_HostnameFromSender(t *testing.T) {
+	nodes := fakeResolver{"local": {}}
+	receiver := startReceiver(t, hephaestus.SyslogConfiguration{
+		UDPAddress: "127.0.0.1:0",
+		Routes:     []hephaestus.SyslogRoute{{Hostname: "127.0.0.1", NodeID: "local"}},
+	}, nodes)
+
+	conn, err := net.Dial("udp", receiver.UDPAddr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Write([]byte("<14>no header at all"))
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool { return len(nodes.messages("local")) == 1 }, time.Second, 5*time.Millisecond)
+}
+
+func TestSyslogReceiver_RejectsOversizedFrames(t *testing.T) {
+	nodes := fakeResolver{"app": {}}
+	receiver := startReceiver(t, hephaestus.SyslogConfiguration{TCPAddress: "127.0.0.1:0", DefaultNodeID: "app", MaxMessageSize: 16}, nodes)
+
+	conn, err := net.Dial("tcp", receiver.TCPAddr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Write([]byte("1000 <11>1 - - - - - - too long"))
+	require.NoError(t, err)
+
+	// The connection is closed without processing anything
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	_, err = conn.Read(make([]byte, 1))
+	assert.Error(t, err)
+	assert.Empty(t, nodes.messages("app"))
+}
+
+func TestSyslogReceiver_DropsOversizedDatagrams(t *testing.T) {
+	nodes := fakeResolver{"app": {}}
+	receiver := startReceiver(t, hephaestus.SyslogConfiguration{UDPAddress: "127.0.0.1:0", DefaultNodeID: "app", MaxMessageSize: 24}, nodes)
+
+	conn, err := net.Dial("udp", receiver.UDPAddr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	for _, msg := range []string{"<11>1 - - - - - - far too long", "<11>1 - - - - - - fits"} {
+		_, err := conn.Write([]byte(msg))
+		require.NoError(t, err)
+	}
+
+	require.Eventually(t, func() bool { return len(nodes.messages("app")) == 1 }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, []string{"fits"}, nodes.messages("app"))
+}
+
+func TestSyslogReceiver_ClosesIdleConnections(t *testing.T) {
+	receiver := startReceiver(t, hephaestus.SyslogConfiguration{TCPAddress: "127.0.0.1:0", DefaultNodeID: "app", IdleTimeout: 20 * time.Millisecond}, fakeResolver{"app": {}})
+
+	conn, err := net.Dial("tcp", receiver.TCPAddr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	_, err = conn.Read(make([]byte, 1))
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestSyslogReceiver_LimitsConnections(t *testing.T) {
+	nodes := fakeResolver{"app": {}}
+	receiver := startReceiver(t, hephaestus.SyslogConfiguration{TCPAddress: "127.0.0.1:0", DefaultNodeID: "app", MaxConnections: 1}, nodes)
+
+	first, err := net.Dial("tcp", receiver.TCPAddr().String())
+	require.NoError(t, err)
+	defer first.Close()
+	_, err = first.Write([]byte("<11>1 - - - - - - first\n"))
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return len(nodes.messages("app")) == 1 }, time.Second, 5*time.Millisecond)
+
+	// The second connection is closed while the first is open
+	second, err := net.Dial("tcp", receiver.TCPAddr().String())
+	require.NoError(t, err)
+	defer second.Close()
+	second.SetReadDeadline(time.Now().Add(time.Second))
+	_, err = second.Read(make([]byte, 1))
+	assert.ErrorIs(t, err, io.EOF)
+	assert.Equal(t, []string{"first"}, nodes.messages("app"))
+}
+
+func TestSyslogReceiver_StopsWithContext(t *testing.T) {
+	receiver, err := NewSyslogReceiver(hephaestus.SyslogConfiguration{UDPAddress: "127.0.0.1:0", TCPAddress: "127.0.0.1:0"}, fakeResolver{})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	require.NoError(t, receiver.Start(ctx))
+	address := receiver.TCPAddr().String()
+	cancel()
+
+	require.Eventually(t, func() bool {
+		conn, err := net.Dial("tcp", address)
+		if err == nil {
+			conn.Close()
+		}
+		return err != nil
+	}, time.Second, 5*time.Millisecond)
+	assert.NoError(t, receiver.Stop())
+}
+
+func TestNewSyslogReceiver_InvalidConfiguration(t *testing.T) {
+	tests := []struct {
+		name   string
+		config hephaestus.SyslogConfiguration
+	}{
+		{name: "no address", config: hephaestus.SyslogConfiguration{}},
+		{name: "route without node", config: hephaestus.SyslogConfiguration{UDPAddress: ":0", Routes: []hephaestus.SyslogRoute{{AppName: "api"}}}},
+		{name: "negative connection limit", config: hephaestus.SyslogConfiguration{TCPAddress: ":0", MaxConnections: -1}},
+		{name: "negative idle timeout", config: hephaestus.SyslogConfiguration{TCPAddress: ":0", IdleTimeout: -time.Second}},
+		{name: "bad pattern", config: hephaestus.SyslogConfiguration{UDPAddress: ":0", Routes: []hephaestus.SyslogRoute{{AppName: "[", NodeID: "a"}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewSyslogReceiver(tt.config, fakeResolver{})
+			assert.ErrorIs(t, err, hephaestus.ErrInvalidConfig)
+		})
+	}
+}
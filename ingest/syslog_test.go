@@ -0,0 +1,164 @@
+package ingest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/HoyeonS/hephaestus/pkg/hephaestus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSyslog(t *testing.T) {
+	now := time.Date(2024, 1, 5, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		data string
+		want *SyslogMessage
+	}{
+		{
+			name: "rfc5424 with structured data",
+			data: `<165>1 2003-10-11T22:14:15.003Z mymachine.example.com evntslog - ID47 [exampleSDID@32473 iut="3" eventSource="Application" eventID="1011"][examplePriority@32473 class="high \"x\\y\]"] ` + "\xEF\xBB\xBF" + `An application event log entry...`,
+			want: &SyslogMessage{
+				Facility:  20,
+				Severity:  5,
+				Timestamp: time.Date(2003, 10, 11, 22, 14, 15, 3000000, time.UTC),
+				Hostname:  "mymachine.example.com",
+				AppName:   "evntslog",
+				MsgID:     "ID47",
+				StructuredData: map[string]map[string]interface{}{
+					"exampleSDID@32473":     {"iut": "3", "eventSource": "Application", "eventID": "1011"},
+					"examplePriority@32473": {"class": `high "x\y]`},
+				},
+				Message: "An application event log entry...",
+			},
+		},
+		{
+			name: "rfc5424 with nil values and repeated parameters",
+			data: `<11>1 - - app 42 - [origin ip="10.0.0.1" ip="10.0.0.2"]`,
+			want: &SyslogMessage{
+				Facility:       1,
+				Severity:       3,
+				Timestamp:      now,
+				AppName:        "app",
+				ProcID:         "42",
+				StructuredData: map[string]map[string]interface{}{"origin": {"ip": []string{"10.0.0.1", "10.0.0.2"}}},
+			},
+		},
+		{
+			name: "rfc3164",
+			data: "<34>Oct 11 22:14:15 mymachine su[123]: 'su root' failed for lonvick on /dev/pts/8\n",
+			want: &SyslogMessage{
+				Facility:  4,
+				Severity:  2,
+				Timestamp: time.Date(2023, 10, 11, 22, 14, 15, 0, time.UTC),
+				Hostname:  "mymachine",
+				AppName:   "su",
+				ProcID:    "123",
+				Message:   "'su root' failed for lonvick on /dev/pts/8",
+			},
+		},
+		{
+			name: "rfc3164 without hostname",
+			data: "<13>Jan  5 11:00:00 cron: job started",
+			want: &SyslogMessage{
+				Facility:  1,
+				Severity:  5,
+				Timestamp: time.Date(2024, 1, 5, 11, 0, 0, 0, time.UTC),
+				AppName:   "cron",
+				Message:   "job started",
+			},
+		},
+		{
+			name: "rfc3164 with rfc3339 timestamp",
+			data: "<14>2024-01-05T10:00:00Z web nginx: upstream timed out",
+			want: &SyslogMessage{
+				Facility:  1,
+				Severity:  6,
+				Timestamp: time.Date(2024, 1, 5, 10, 0, 0, 0, time.UTC),
+				Hostname:  "web",
+				AppName:   "nginx",
+				Message:   "upstream timed out",
+			},
+		},
+		{
+			name: "rfc3164 without header",
+			data: "<12>free form text",
+			want: &SyslogMessage{Facility: 1, Severity: 4, Timestamp: now, Message: "free form text"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := ParseSyslog([]byte(tt.data), now)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, msg)
+		})
+	}
+}
+
+func TestParseSyslog_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "no priority", data: "hello"},
+		{name: "priority out of range", data: "<192>1 - - - - - -"},
+		{name: "truncated header", data: "<13>1 - host"},
+		{name: "bad timestamp", data: "<13>1 yesterday host app - - -"},
+		{name: "unterminated structured data", data: `<13>1 - host app - - [id a="1"`},
+		{name: "unquoted parameter", data: `<13>1 - host app - - [id a=1]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseSyslog([]byte(tt.data), time.Now())
+			assert.ErrorIs(t, err, hephaestus.ErrInvalidArgument)
+		})
+	}
+}
+
+func TestParseSyslog_RFC3164LeapDay(t *testing.T) {
+	tests := []struct {
+		name string
+		now  time.Time
+	}{
+		{name: "in the leap year", now: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)},
+		{name: "in the year after", now: time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := ParseSyslog([]byte("<13>Feb 29 08:00:00 web app: leap"), tt.now)
+			require.NoError(t, err)
+			assert.Equal(t, time.Date(2024, 2, 29, 8, 0, 0, 0, time.UTC), msg.Timestamp)
+			assert.Equal(t, "leap", msg.Message)
+		})
+	}
+}
+
+func TestSyslogMessage_LogEntry(t *testing.T) {
+	msg := &SyslogMessage{
+		Facility:       16,
+		Severity:       3,
+		Timestamp:      time.Date(2024, 1, 5, 10, 0, 0, 0, time.UTC),
+		Hostname:       "web",
+		AppName:        "api",
+		StructuredData: map[string]map[string]interface{}{"trace": {"trace_id": "t1"}, "syslog": {"trace_id": "t2"}},
+		Message:        "boom",
+	}
+
+	assert.Equal(t, hephaestus.LogEntry{
+		Timestamp: msg.Timestamp,
+		Level:     "error",
+		Message:   "boom",
+		Context: map[string]interface{}{
+			SyslogContextKey: map[string]interface{}{"facility": "local0", "severity": 3, "hostname": "web", "app_name": "api"},
+			StructuredDataContextKey: map[string]interface{}{
+				"trace":  map[string]interface{}{"trace_id": "t1"},
+				"syslog": map[string]interface{}{"trace_id": "t2"},
+			},
+		},
+	}, msg.LogEntry())
+}
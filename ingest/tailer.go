@@ -0,0 +1,492 @@
+package ingest
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/HoyeonS/hephaestus/pkg/hephaestus"
+	"go.uber.org/zap"
+)
+
+// LogSink receives the entries read from log files, a node is one
+type LogSink interface {
+	ProcessLog(entry hephaestus.LogEntry) error
+}
+
+const (
+	// defaultPollInterval is how often files are polled when no interval is set
+	defaultPollInterval = time.Second
+
+	// fingerprintSize is the number of leading bytes that identify a file, so a saved
+	// offset is only reused for the file it was read from
+	fingerprintSize = 512
+
+	// readChunkSize is the number of bytes read from a file at once
+	readChunkSize = 64 * 1024
+
+	// FileContextKey is the context key holding the path an entry was read from
+	FileContextKey = "log_file"
+)
+
+// Tailer follows log files the way tail -F does. Files are found with glob patterns and
+// checked on every poll: a followed file renamed to another matched path keeps being
+// followed there, one renamed away is read to its end first, and a file that shrank or
+// whose first bytes changed was truncated in place and is read again from the start.
+// Read offsets may be persisted so a restart resumes where the previous run stopped.
+//
+// Stack trace lines are grouped with the entry they follow like the Reader does, the
+// last entry of a file is held until a poll finds no new lines in it.
+//
+// The tailer only polls and works the same on every platform.
+type Tailer struct {
+	patterns   []string
+	interval   time.Duration
+	offsetFile string
+	startAtEnd bool
+	parser     *Parser
+	sink       LogSink
+	logger     *zap.Logger
+
+	files map[string]*tailedFile
+	// saved holds the offsets loaded from the offset file until their files are opened
+	saved     map[string]savedOffset
+	lastSaved []byte
+	polled    bool
+}
+
+// tailedFile is an open file being followed
+type tailedFile struct {
+	path string
+	file *os.File
+	info os.FileInfo
+	// offset is the position after the last complete line read
+	offset int64
+	// assembler holds the last entry read until it is known to be complete, and
+	// entryStart is the position of its first line
+	assembler  entryAssembler
+	entryStart int64
+	// fingerprint is the hash of the first fingerprintLen bytes of the file
+	fingerprint    string
+	fingerprintLen int64
+	// skipping is set while the rest of a line cut at maxLineSize is skipped
+	skipping bool
+}
+
+// savedOffset is the persisted read position of a file
+type savedOffset struct {
+	Offset          int64  `json:"offset"`
+	Fingerprint     string `json:"fingerprint"`
+	FingerprintSize int64  `json:"fingerprint_size"`
+}
+
+// NewTailer creates a tailer handing the lines of the configured files, parsed with the
+// given settings, to sink
+func NewTailer(config hephaestus.TailConfiguration, parserConfig hephaestus.ParserConfiguration, sink LogSink) (*Tailer, error) {
+	if err := hephaestus.ValidateTailConfiguration(config); err != nil {
+		return nil, fmt.Errorf("%w: %v", hephaestus.ErrInvalidConfig, err)
+	}
+	if len(config.Paths) == 0 {
+		return nil, fmt.Errorf("%w: no paths to tail", hephaestus.ErrInvalidConfig)
+	}
+	if sink == nil {
+		return nil, fmt.Errorf("%w: log sink is required", hephaestus.ErrInvalidArgument)
+	}
+
+	parser, err := NewParser(parserConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Tailer{
+		patterns:   config.Paths,
+		interval:   config.PollInterval,
+		offsetFile: config.OffsetFile,
+		startAtEnd: config.StartAtEnd,
+		parser:     parser,
+		sink:       sink,
+		logger:     zap.NewNop(),
+		files:      make(map[string]*tailedFile),
+		saved:      make(map[string]savedOffset),
+	}
+	if t.interval == 0 {
+		t.interval = defaultPollInterval
+	}
+	if err := t.loadOffsets(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// SetLogger sets the logger for the tailer
+func (t *Tailer) SetLogger(logger *zap.Logger) {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	t.logger = logger
+}
+
+// Run polls the files until ctx is done, then saves the offsets and closes the files.
+// Run must not be called concurrently.
+func (t *Tailer) Run(ctx context.Context) error {
+	defer t.closeFiles()
+
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := t.poll(ctx); err != nil {
+			t.logger.Warn("Failed to save tail offsets", zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return t.saveOffsets()
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll follows rotated files, opens newly matched files and reads the lines appended
+// to every followed file since the last poll
+func (t *Tailer) poll(ctx context.Context) error {
+	found := t.discover()
+
+	// Followed files are matched to the paths naming them now by identity, as numbered
+	// rotation renames several of them at once (app.log.1 to app.log.2 and app.log to
+	// app.log.1). A file no matched path names anymore is read to its end and closed.
+	followed := make(map[string]*tailedFile, len(t.files))
+	var rotated []*tailedFile
+	for _, path := range sortedKeys(t.files) {
+		tf := t.files[path]
+		moved := findMoved(tf, found, followed)
+		if moved == "" {
+			rotated = append(rotated, tf)
+			continue
+		}
+		if moved != path {
+			t.logger.Info("Followed rotated log file", zap.String("from", path), zap.String("to", moved))
+		}
+		tf.path = moved
+		followed[moved] = tf
+	}
+	t.files = followed
+
+	for _, tf := range rotated {
+		t.logger.Info("Log file rotated", zap.String("path", tf.path))
+		t.read(ctx, tf, true)
+		tf.file.Close()
+	}
+
+	for _, path := range sortedKeys(found) {
+		if _, ok := t.files[path]; ok {
+			continue
+		}
+		if err := t.open(path); err != nil {
+			t.logger.Warn("Failed to open log file", zap.String("path", path), zap.Error(err))
+		}
+	}
+
+	for _, path := range sortedKeys(t.files) {
+		tf := t.files[path]
+		if err := t.checkTruncated(tf); err != nil {
+			t.logger.Warn("Failed to check log file", zap.String("path", path), zap.Error(err))
+			continue
+		}
+		t.read(ctx, tf, false)
+	}
+
+	t.polled = true
+	return t.saveOffsets()
+}
+
+// discover returns the regular files matching the patterns by path
+func (t *Tailer) discover() map[string]os.FileInfo {
+	found := make(map[string]os.FileInfo)
+	for _, pattern := range t.patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			continue
+		}
+		for _, path := range matches {
+			if info, err := os.Stat(path); err == nil && info.Mode().IsRegular() {
+				found[path] = info
+			}
+		}
+	}
+	return found
+}
+
+// findMoved returns the matched path naming a followed file, preferring the path it is
+// followed under, or "" when none does. Paths already taken are skipped.
+func findMoved(tf *tailedFile, found map[string]os.FileInfo, taken map[string]*tailedFile) string {
+	if info, ok := found[tf.path]; ok && os.SameFile(info, tf.info) {
+		return tf.path
+	}
+	for _, path := range sortedKeys(found) {
+		if _, ok := taken[path]; ok {
+			continue
+		}
+		if os.SameFile(found[path], tf.info) {
+			return path
+		}
+	}
+	return ""
+}
+
+// open starts following a file, from its saved offset when it is the file the offset
+// was saved for
+func (t *Tailer) open(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	tf := &tailedFile{path: path, file: file, info: info}
+	if saved, ok := t.saved[path]; ok {
+		delete(t.saved, path)
+		tf.fingerprint, tf.fingerprintLen = saved.Fingerprint, saved.FingerprintSize
+		if matches, err := tf.identify(); err == nil && matches && saved.Offset <= info.Size() {
+			tf.offset = saved.Offset
+		} else {
+			t.logger.Info("Log file changed since offset was saved, reading from start", zap.String("path", path))
+			tf.fingerprint, tf.fingerprintLen = "", 0
+		}
+	} else if t.startAtEnd && !t.polled {
+		tf.offset = info.Size()
+	}
+	tf.assembler.parser = t.parser
+
+	if _, err := tf.identify(); err != nil {
+		file.Close()
+		return err
+	}
+	t.files[path] = tf
+	return nil
+}
+
+// checkTruncated restarts a file from the beginning when it was truncated in place
+func (t *Tailer) checkTruncated(tf *tailedFile) error {
+	info, err := tf.file.Stat()
+	if err != nil {
+		return err
+	}
+	tf.info = info
+
+	matches, err := tf.identify()
+	if err != nil {
+		return err
+	}
+	if info.Size() >= tf.offset && matches {
+		return nil
+	}
+
+	t.logger.Info("Log file truncated, reading from start", zap.String("path", tf.path))
+	t.flush(tf)
+	tf.offset = 0
+	tf.skipping = false
+	tf.fingerprint, tf.fingerprintLen = "", 0
+	_, err = tf.identify()
+	return err
+}
+
+// identify reports whether the file still starts with the fingerprinted bytes and
+// extends the fingerprint while the file is shorter than fingerprintSize
+func (tf *tailedFile) identify() (bool, error) {
+	size := min(tf.info.Size(), fingerprintSize)
+	if size < tf.fingerprintLen {
+		return false, nil
+	}
+
+	prefix := make([]byte, size)
+	if _, err := tf.file.ReadAt(prefix, 0); err != nil && !errors.Is(err, io.EOF) {
+		return false, err
+	}
+	if tf.fingerprintLen > 0 && hash(prefix[:tf.fingerprintLen]) != tf.fingerprint {
+		return false, nil
+	}
+	tf.fingerprint, tf.fingerprintLen = hash(prefix), size
+	return true, nil
+}
+
+func hash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// read hands the complete lines after the offset to the sink. A partial last line is
+// left for the next poll unless the file is final, as a rotated file no longer grows.
+// Lines are cut at maxLineSize like the Reader does and the rest of a longer line is
+// skipped up to its newline. The held entry is flushed when the file is final or had
+// no new lines.
+func (t *Tailer) read(ctx context.Context, tf *tailedFile, final bool) {
+	buf := make([]byte, readChunkSize)
+	var pending []byte
+	start := tf.offset
+
+	for ctx.Err() == nil {
+		n, err := tf.file.ReadAt(buf, tf.offset+int64(len(pending)))
+		data := append(pending, buf[:n]...)
+
+		consumed := 0
+		for {
+			i := bytes.IndexByte(data[consumed:], '\n')
+			if i < 0 {
+				break
+			}
+			if tf.skipping {
+				tf.skipping = false
+			} else {
+				t.emit(tf, data[consumed:consumed+min(i, maxLineSize)], tf.offset+int64(consumed))
+			}
+			consumed += i + 1
+		}
+		tf.offset += int64(consumed)
+		pending = data[consumed:]
+
+		if tf.skipping {
+			tf.offset += int64(len(pending))
+			pending = nil
+		} else if len(pending) >= maxLineSize {
+			t.emit(tf, pending[:maxLineSize], tf.offset)
+			tf.offset += int64(len(pending))
+			pending = nil
+			tf.skipping = true
+		}
+
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				t.logger.Warn("Failed to read log file", zap.String("path", tf.path), zap.Error(err))
+				return
+			}
+			break
+		}
+	}
+	if ctx.Err() != nil {
+		return
+	}
+
+	if final && len(pending) > 0 {
+		t.emit(tf, pending, tf.offset)
+		tf.offset += int64(len(pending))
+	}
+	if final || tf.offset == start {
+		t.flush(tf)
+	}
+}
+
+// emit adds a line starting at offset to the entry being assembled and hands the
+// entries it completes to the sink
+func (t *Tailer) emit(tf *tailedFile, line []byte, offset int64) {
+	entry, complete, started := tf.assembler.add(string(line))
+	if started {
+		tf.entryStart = offset
+	}
+	if complete {
+		t.deliver(tf, entry)
+	}
+}
+
+// flush hands the held entry of a file to the sink
+func (t *Tailer) flush(tf *tailedFile) {
+	if entry, ok := tf.assembler.flush(); ok {
+		t.deliver(tf, entry)
+	}
+}
+
+func (t *Tailer) deliver(tf *tailedFile, entry hephaestus.LogEntry) {
+	if entry.Context == nil {
+		entry.Context = make(map[string]interface{})
+	}
+	entry.Context[FileContextKey] = tf.path
+
+	if err := t.sink.ProcessLog(entry); err != nil {
+		t.logger.Warn("Failed to process tailed log entry", zap.String("path", tf.path), zap.Error(err))
+	}
+}
+
+// loadOffsets reads the offsets saved by a previous run
+func (t *Tailer) loadOffsets() error {
+	if t.offsetFile == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(t.offsetFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("%w: reading tail offsets: %v", hephaestus.ErrLogError, err)
+	}
+	if err := json.Unmarshal(data, &t.saved); err != nil {
+		return fmt.Errorf("%w: decoding tail offsets: %v", hephaestus.ErrLogError, err)
+	}
+	t.lastSaved = data
+	return nil
+}
+
+// saveOffsets persists the offsets of the followed files when they changed. A held entry
+// is not delivered yet, so the offset of its first line is saved. The file is replaced
+// atomically so a crash leaves either the old or the new offsets.
+func (t *Tailer) saveOffsets() error {
+	if t.offsetFile == "" {
+		return nil
+	}
+
+	offsets := make(map[string]savedOffset, len(t.files))
+	for path, tf := range t.files {
+		offset := tf.offset
+		if tf.assembler.pending != nil {
+			offset = tf.entryStart
+		}
+		offsets[path] = savedOffset{Offset: offset, Fingerprint: tf.fingerprint, FingerprintSize: tf.fingerprintLen}
+	}
+	data, err := json.Marshal(offsets)
+	if err != nil {
+		return fmt.Errorf("%w: encoding tail offsets: %v", hephaestus.ErrLogError, err)
+	}
+	if bytes.Equal(data, t.lastSaved) {
+		return nil
+	}
+
+	tmp := t.offsetFile + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("%w: writing tail offsets: %v", hephaestus.ErrLogError, err)
+	}
+	if err := os.Rename(tmp, t.offsetFile); err != nil {
+		return fmt.Errorf("%w: writing tail offsets: %v", hephaestus.ErrLogError, err)
+	}
+	t.lastSaved = data
+	return nil
+}
+
+// closeFiles closes every followed file
+func (t *Tailer) closeFiles() {
+	for path, tf := range t.files {
+		tf.file.Close()
+		delete(t.files, path)
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,315 @@
+package ingest
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/HoyeonS/hephaestus/pkg/hephaestus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// collectingSink records the messages of processed entries
+type collectingSink struct {
+	mu       sync.Mutex
+	messages []string
+	files    []string
+}
+
+func (s *collectingSink) ProcessLog(entry hephaestus.LogEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messages = append(s.messages, entry.Message)
+	s.files = append(s.files, filepath.Base(entry.Context[FileContextKey].(string)))
+	return nil
+}
+
+// take returns and clears the recorded messages
+func (s *collectingSink) take() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	messages := s.messages
+	s.messages, s.files = nil, nil
+	return messages
+}
+
+func appendFile(t *testing.T, path, data string) {
+	t.Helper()
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	require.NoError(t, err)
+	_, err = file.WriteString(data)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+}
+
+func newTestTailer(t *testing.T, config hephaestus.TailConfiguration, sink LogSink) *Tailer {
+	t.Helper()
+
+	tailer, err := NewTailer(config, hephaestus.ParserConfiguration{}, sink)
+	require.NoError(t, err)
+	t.Cleanup(tailer.closeFiles)
+	return tailer
+}
+
+// pollFlushed polls twice, the second poll finding no new lines flushes the held entries
+func pollFlushed(t *testing.T, tailer *Tailer) {
+	t.Helper()
+
+	require.NoError(t, tailer.poll(context.Background()))
+	require.NoError(t, tailer.poll(context.Background()))
+}
+
+func TestTailer_FollowsAppendedLines(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	appendFile(t, path, "one\ntwo\nthr")
+
+	sink := &collectingSink{}
+	tailer := newTestTailer(t, hephaestus.TailConfiguration{Paths: []string{filepath.Join(dir, "*.log")}}, sink)
+
+	pollFlushed(t, tailer)
+	assert.Equal(t, []string{"one", "two"}, sink.take())
+
+	// The partial line is read once it is complete
+	appendFile(t, path, "ee\n\nfour\n")
+	pollFlushed(t, tailer)
+	assert.Equal(t, []string{"three", "four"}, sink.take())
+}
+
+func TestTailer_TruncatesOversizedLines(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	appendFile(t, path, "before\n"+strings.Repeat("x", maxLineSize+readChunkSize/2))
+
+	sink := &collectingSink{}
+	tailer := newTestTailer(t, hephaestus.TailConfiguration{Paths: []string{filepath.Join(dir, "*.log")}}, sink)
+
+	pollFlushed(t, tailer)
+	messages := sink.take()
+	require.Len(t, messages, 2)
+	assert.Equal(t, "before", messages[0])
+	assert.Len(t, messages[1], maxLineSize)
+
+	// The rest of the line is skipped up to its newline, across polls
+	appendFile(t, path, strings.Repeat("y", 3*readChunkSize)+"\nafter\n"+strings.Repeat("z", maxLineSize+10)+"\nlast\n")
+	pollFlushed(t, tailer)
+	messages = sink.take()
+	require.Len(t, messages, 3)
+	assert.Equal(t, "after", messages[0])
+	assert.Equal(t, strings.Repeat("z", maxLineSize), messages[1])
+	assert.Equal(t, "last", messages[2])
+}
+
+func TestTailer_Rotation(t *testing.T) {
+	tests := []struct {
+		name   string
+		rotate func(t *testing.T, path string)
+		want   []string
+	}{
+		{
+			name: "rename",
+			rotate: func(t *testing.T, path string) {
+				appendFile(t, path, "last old\n")
+				require.NoError(t, os.Rename(path, path+".1"))
+				appendFile(t, path, "first new\n")
+			},
+			want: []string{"last old", "first new"},
+		},
+		{
+			name: "copytruncate",
+			rotate: func(t *testing.T, path string) {
+				require.NoError(t, os.Truncate(path, 0))
+				appendFile(t, path, "first new\n")
+			},
+			want: []string{"first new"},
+		},
+		{
+			name: "truncate and grow past the offset",
+			rotate: func(t *testing.T, path string) {
+				require.NoError(t, os.Truncate(path, 0))
+				appendFile(t, path, "first new\nsecond new line\n")
+			},
+			want: []string{"first new", "second new line"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "app.log")
+			appendFile(t, path, "old entry\n")
+
+			sink := &collectingSink{}
+			tailer := newTestTailer(t, hephaestus.TailConfiguration{Paths: []string{filepath.Join(dir, "*.log")}}, sink)
+			pollFlushed(t, tailer)
+			assert.Equal(t, []string{"old entry"}, sink.take())
+
+			tt.rotate(t, path)
+			pollFlushed(t, tailer)
+			assert.Equal(t, tt.want, sink.take())
+		})
+	}
+}
+
+func TestTailer_FollowsRenameToMatchedPath(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	appendFile(t, path, "one\n")
+
+	sink := &collectingSink{}
+	tailer := newTestTailer(t, hephaestus.TailConfiguration{Paths: []string{filepath.Join(dir, "*.log")}}, sink)
+	pollFlushed(t, tailer)
+	sink.take()
+
+	moved := filepath.Join(dir, "app-1.log")
+	require.NoError(t, os.Rename(path, moved))
+	appendFile(t, moved, "two\n")
+	pollFlushed(t, tailer)
+
+	assert.Equal(t, []string{"two"}, sink.take())
+}
+
+func TestTailer_NumberedRotation(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	appendFile(t, path, "current\n")
+	appendFile(t, path+".1", "previous\n")
+
+	sink := &collectingSink{}
+	tailer := newTestTailer(t, hephaestus.TailConfiguration{Paths: []string{path + "*"}}, sink)
+	pollFlushed(t, tailer)
+	assert.ElementsMatch(t, []string{"current", "previous"}, sink.take())
+
+	// Every followed file is renamed at once, none of them is read again
+	require.NoError(t, os.Rename(path+".1", path+".2"))
+	require.NoError(t, os.Rename(path, path+".1"))
+	appendFile(t, path+".1", "late write\n")
+	appendFile(t, path, "new file\n")
+	pollFlushed(t, tailer)
+
+	assert.ElementsMatch(t, []string{"late write", "new file"}, sink.take())
+}
+
+func TestTailer_GroupsStackTraces(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	offsets := filepath.Join(dir, "offsets.json")
+	appendFile(t, path, "2024-01-02 03:04:05 ERROR boom\n\tat com.example.Service.run(Service.java:42)\n")
+
+	config := hephaestus.TailConfiguration{Paths: []string{path}, OffsetFile: offsets}
+	sink := &entrySink{}
+	first := newTestTailer(t, config, sink)
+	require.NoError(t, first.poll(context.Background()))
+	assert.Empty(t, sink.all())
+
+	// The held entry is not saved as read, a restart reads it again
+	first.closeFiles()
+	tailer := newTestTailer(t, config, sink)
+	require.NoError(t, tailer.poll(context.Background()))
+	appendFile(t, path, "\tat com.example.Main.main(Main.java:7)\n")
+	require.NoError(t, tailer.poll(context.Background()))
+	assert.Empty(t, sink.all())
+
+	require.NoError(t, tailer.poll(context.Background()))
+	entries := sink.all()
+	require.Len(t, entries, 1)
+	assert.Equal(t, "boom", entries[0].Message)
+	assert.Equal(t, "\tat com.example.Service.run(Service.java:42)\n\tat com.example.Main.main(Main.java:7)", entries[0].ErrorTrace)
+	assert.Equal(t, path, entries[0].Context[FileContextKey])
+}
+
+func TestTailer_ResumesFromSavedOffsets(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	replaced := filepath.Join(dir, "other.log")
+	appendFile(t, path, "one\n")
+	appendFile(t, replaced, "old content\n")
+
+	config := hephaestus.TailConfiguration{
+		Paths:      []string{filepath.Join(dir, "*.log")},
+		OffsetFile: filepath.Join(dir, "offsets.json"),
+	}
+	sink := &collectingSink{}
+	first := newTestTailer(t, config, sink)
+	pollFlushed(t, first)
+	first.closeFiles()
+	assert.Equal(t, []string{"one", "old content"}, sink.take())
+
+	// Lines written while stopped are read, a file replaced while stopped is read from the start
+	appendFile(t, path, "two\n")
+	require.NoError(t, os.Remove(replaced))
+	appendFile(t, replaced, "new content that is longer\n")
+
+	second := newTestTailer(t, config, sink)
+	pollFlushed(t, second)
+	assert.Equal(t, []string{"two", "new content that is longer"}, sink.take())
+}
+
+func TestTailer_DiscoversNewFiles(t *testing.T) {
+	dir := t.TempDir()
+	appendFile(t, filepath.Join(dir, "existing.log"), "skipped\n")
+
+	sink := &collectingSink{}
+	tailer := newTestTailer(t, hephaestus.TailConfiguration{Paths: []string{filepath.Join(dir, "*.log")}, StartAtEnd: true}, sink)
+	pollFlushed(t, tailer)
+	assert.Empty(t, sink.take())
+
+	// Files created later are read from the start
+	appendFile(t, filepath.Join(dir, "new.log"), "created\n")
+	appendFile(t, filepath.Join(dir, "ignored.txt"), "ignored\n")
+	pollFlushed(t, tailer)
+	assert.Equal(t, []string{"created"}, sink.take())
+}
+
+func TestTailer_Run(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	offsets := filepath.Join(dir, "offsets.json")
+
+	sink := &collectingSink{}
+	tailer := newTestTailer(t, hephaestus.TailConfiguration{
+		Paths:        []string{path},
+		PollInterval: 5 * time.Millisecond,
+		OffsetFile:   offsets,
+	}, sink)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- tailer.Run(ctx) }()
+
+	appendFile(t, path, `{"level":"error","msg":"boom"}`+"\n")
+	require.Eventually(t, func() bool {
+		sink.mu.Lock()
+		defer sink.mu.Unlock()
+		return len(sink.messages) == 1
+	}, time.Second, 5*time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-done)
+	assert.Equal(t, []string{"app.log"}, sink.files)
+	assert.FileExists(t, offsets)
+}
+
+func TestNewTailer_InvalidConfiguration(t *testing.T) {
+	tests := []struct {
+		name   string
+		config hephaestus.TailConfiguration
+	}{
+		{name: "no paths", config: hephaestus.TailConfiguration{}},
+		{name: "bad pattern", config: hephaestus.TailConfiguration{Paths: []string{"[a-"}}},
+		{name: "negative interval", config: hephaestus.TailConfiguration{Paths: []string{"*.log"}, PollInterval: -time.Second}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewTailer(tt.config, hephaestus.ParserConfiguration{}, &collectingSink{})
+			assert.ErrorIs(t, err, hephaestus.ErrInvalidConfig)
+		})
+	}
+}
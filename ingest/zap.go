@@ -0,0 +1,241 @@
+package ingest
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/HoyeonS/hephaestus/pkg/hephaestus"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	// defaultZapBufferSize is the number of entries a ZapCore queues when no size is set
+	defaultZapBufferSize = 1024
+
+	// defaultZapFlushTimeout bounds the wait for entries above error when no timeout is set
+	defaultZapFlushTimeout = time.Second
+)
+
+// ZapCoreOptions are options for a ZapCore
+type ZapCoreOptions struct {
+	// BufferSize is the number of entries queued for the node, entries written while
+	// the queue is full are dropped. Zero uses 1024.
+	BufferSize int
+	// FlushTimeout bounds how long writing an entry above error waits for it to reach
+	// the node, as zap panics or exits the process right after. Zero uses one second.
+	FlushTimeout time.Duration
+}
+
+// ZapCore is a zapcore.Core forwarding entries to a node, meant to be teed into an
+// existing logger. Writes only encode the fields and queue the entry; a background
+// goroutine hands queued entries to the node, so a slow node never blocks the caller.
+type ZapCore struct {
+	zapcore.LevelEnabler
+	fields       []zapcore.Field
+	queue        *zapQueue
+	flushTimeout time.Duration
+}
+
+// zapQueue is the queue shared by a core and the cores derived from it with With
+type zapQueue struct {
+	items   chan zapItem
+	sink    LogSink
+	dropped atomic.Uint64
+
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+// zapItem is a queued entry, or a flush request when flushed is set
+type zapItem struct {
+	entry   hephaestus.LogEntry
+	flushed chan struct{}
+}
+
+// NewZapCore creates a core forwarding the entries enabled by enabler to sink. The
+// core runs a goroutine until Close is called.
+func NewZapCore(sink LogSink, enabler zapcore.LevelEnabler, opts *ZapCoreOptions) *ZapCore {
+	size := defaultZapBufferSize
+	flushTimeout := defaultZapFlushTimeout
+	if opts != nil && opts.BufferSize > 0 {
+		size = opts.BufferSize
+	}
+	if opts != nil && opts.FlushTimeout > 0 {
+		flushTimeout = opts.FlushTimeout
+	}
+
+	q := &zapQueue{
+		items: make(chan zapItem, size),
+		sink:  sink,
+		done:  make(chan struct{}),
+	}
+	go q.run()
+
+	return &ZapCore{LevelEnabler: enabler, queue: q, flushTimeout: flushTimeout}
+}
+
+// With returns a core adding fields to every entry
+func (c *ZapCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := *c
+	clone.fields = append(c.fields[:len(c.fields):len(c.fields)], fields...)
+	return &clone
+}
+
+// Check adds the core to the checked entry when the level is enabled
+func (c *ZapCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+// Write queues the entry for the node. Entries are dropped when the queue is full
+// or the core is closed. Entries above error are followed by a panic or exit, so
+// Write waits up to the flush timeout for them to be handed to the node.
+func (c *ZapCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	item := zapItem{entry: c.logEntry(entry, fields)}
+
+	select {
+	case <-c.queue.done:
+		c.queue.dropped.Add(1)
+		return nil
+	default:
+	}
+
+	if entry.Level > zapcore.ErrorLevel {
+		timer := time.NewTimer(c.flushTimeout)
+		defer timer.Stop()
+
+		select {
+		case c.queue.items <- item:
+			c.flush(timer.C)
+		case <-timer.C:
+			c.queue.dropped.Add(1)
+		case <-c.queue.done:
+			c.queue.dropped.Add(1)
+		}
+		return nil
+	}
+
+	select {
+	case c.queue.items <- item:
+	default:
+		c.queue.dropped.Add(1)
+	}
+	return nil
+}
+
+// Sync waits until the entries queued so far were handed to the node
+func (c *ZapCore) Sync() error {
+	c.flush(nil)
+	return nil
+}
+
+// flush waits until the entries queued so far were handed to the node, or until
+// timeout fires
+func (c *ZapCore) flush(timeout <-chan time.Time) {
+	flushed := make(chan struct{})
+	select {
+	case c.queue.items <- zapItem{flushed: flushed}:
+	case <-c.queue.done:
+		return
+	case <-timeout:
+		return
+	}
+
+	select {
+	case <-flushed:
+	case <-c.queue.done:
+	case <-timeout:
+	}
+}
+
+// Close hands the queued entries to the node and stops the core. Entries written
+// afterwards are dropped.
+func (c *ZapCore) Close() {
+	c.queue.closeOnce.Do(func() {
+		close(c.queue.done)
+	})
+}
+
+// Dropped returns the number of entries dropped because the queue was full or the
+// core was closed
+func (c *ZapCore) Dropped() uint64 {
+	return c.queue.dropped.Load()
+}
+
+// run hands queued entries to the node until the core is closed, then drains the queue
+func (q *zapQueue) run() {
+	for {
+		select {
+		case item := <-q.items:
+			q.handle(item)
+		case <-q.done:
+			for {
+				select {
+				case item := <-q.items:
+					q.handle(item)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (q *zapQueue) handle(item zapItem) {
+	if item.flushed != nil {
+		close(item.flushed)
+		return
+	}
+	// Errors of the node are its own to report, logging must not fail because of them
+	_ = q.sink.ProcessLog(item.entry)
+}
+
+// logEntry converts a zap entry and its fields to a log entry
+func (c *ZapCore) logEntry(entry zapcore.Entry, fields []zapcore.Field) hephaestus.LogEntry {
+	encoder := zapcore.NewMapObjectEncoder()
+	for _, field := range c.fields {
+		field.AddTo(encoder)
+	}
+	for _, field := range fields {
+		field.AddTo(encoder)
+	}
+	if entry.LoggerName != "" {
+		encoder.Fields["logger"] = entry.LoggerName
+	}
+	if entry.Caller.Defined {
+		encoder.Fields["caller"] = entry.Caller.TrimmedPath()
+	}
+
+	logEntry := hephaestus.LogEntry{
+		Timestamp:  entry.Time,
+		Level:      zapLevel(entry.Level),
+		Message:    entry.Message,
+		ErrorTrace: entry.Stack,
+	}
+	if len(encoder.Fields) > 0 {
+		logEntry.Context = encoder.Fields
+	}
+	return logEntry
+}
+
+// zapLevel maps a zap level onto a severity name. DPanic logs as an error outside
+// development, so it maps to error.
+func zapLevel(level zapcore.Level) string {
+	switch {
+	case level >= zapcore.FatalLevel:
+		return hephaestus.SeverityFatal.String()
+	case level >= zapcore.PanicLevel:
+		return hephaestus.SeverityPanic.String()
+	case level >= zapcore.ErrorLevel:
+		return hephaestus.SeverityError.String()
+	case level >= zapcore.WarnLevel:
+		return hephaestus.SeverityWarn.String()
+	case level >= zapcore.InfoLevel:
+		return hephaestus.SeverityInfo.String()
+	default:
+		return hephaestus.SeverityDebug.String()
+	}
+}
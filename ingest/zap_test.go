@@ -0,0 +1,159 @@
+package ingest
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/HoyeonS/hephaestus/pkg/hephaestus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// blockingSink holds every entry until released
+type blockingSink struct {
+	entrySink
+	release chan struct{}
+}
+
+func (s *blockingSink) ProcessLog(entry hephaestus.LogEntry) error {
+	<-s.release
+	return s.entrySink.ProcessLog(entry)
+}
+
+func TestZapCore_TeesEntries(t *testing.T) {
+	sink := &entrySink{}
+	core := NewZapCore(sink, zapcore.InfoLevel, nil)
+	defer core.Close()
+
+	observed, logs := observer.New(zapcore.DebugLevel)
+	logger := zap.New(zapcore.NewTee(observed, core), zap.AddCaller(), zap.AddStacktrace(zapcore.ErrorLevel)).Named("checkout")
+
+	logger.Debug("not forwarded")
+	logger.With(zap.String("service", "checkout")).Warn("slow", zap.Int("attempt", 2), zap.Namespace("db"), zap.String("table", "orders"))
+	logger.Error("boom", zap.Error(errors.New("refused")))
+	require.NoError(t, logger.Sync())
+
+	assert.Equal(t, 3, logs.Len())
+
+	entries := sink.all()
+	require.Len(t, entries, 2)
+
+	assert.Equal(t, "warn", entries[0].Level)
+	assert.Equal(t, "slow", entries[0].Message)
+	assert.Empty(t, entries[0].ErrorTrace)
+	assert.Equal(t, "checkout", entries[0].Context["service"])
+	assert.Equal(t, int64(2), entries[0].Context["attempt"])
+	assert.Equal(t, map[string]interface{}{"table": "orders"}, entries[0].Context["db"])
+	assert.Equal(t, "checkout", entries[0].Context["logger"])
+	assert.Contains(t, entries[0].Context["caller"], "ingest/zap_test.go:")
+
+	assert.Equal(t, "error", entries[1].Level)
+	assert.Equal(t, "refused", entries[1].Context["error"])
+	assert.Contains(t, entries[1].ErrorTrace, "ingest.TestZapCore_TeesEntries")
+	assert.False(t, entries[1].Timestamp.IsZero())
+}
+
+func TestZapLevel(t *testing.T) {
+	tests := []struct {
+		level zapcore.Level
+		want  string
+	}{
+		{zapcore.DebugLevel, "debug"},
+		{zapcore.InfoLevel, "info"},
+		{zapcore.WarnLevel, "warn"},
+		{zapcore.ErrorLevel, "error"},
+		{zapcore.DPanicLevel, "error"},
+		{zapcore.PanicLevel, "panic"},
+		{zapcore.FatalLevel, "fatal"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.level.String(), func(t *testing.T) {
+			assert.Equal(t, tt.want, zapLevel(tt.level))
+		})
+	}
+}
+
+func TestZapCore_DoesNotBlockOnSlowNode(t *testing.T) {
+	sink := &blockingSink{release: make(chan struct{})}
+	core := NewZapCore(sink, zapcore.DebugLevel, &ZapCoreOptions{BufferSize: 2})
+	defer core.Close()
+	logger := zap.New(core)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 10; i++ {
+			logger.Error("boom")
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("logging blocked on a slow node")
+	}
+
+	// One entry is being handled, two are queued and the rest were dropped
+	close(sink.release)
+	require.NoError(t, logger.Sync())
+	assert.Equal(t, uint64(10), core.Dropped()+uint64(len(sink.all())))
+	assert.GreaterOrEqual(t, core.Dropped(), uint64(7))
+}
+
+func TestZapCore_CloseDrainsQueue(t *testing.T) {
+	sink := &blockingSink{release: make(chan struct{})}
+	core := NewZapCore(sink, zapcore.DebugLevel, nil)
+	logger := zap.New(core)
+
+	logger.Info("one")
+	logger.Info("two")
+	core.Close()
+	close(sink.release)
+
+	assert.Eventually(t, func() bool { return len(sink.all()) == 2 }, time.Second, time.Millisecond)
+
+	logger.Info("after close")
+	assert.Equal(t, uint64(1), core.Dropped())
+	require.NoError(t, logger.Sync())
+}
+
+func TestZapCore_WaitsForEntriesAboveError(t *testing.T) {
+	sink := &blockingSink{release: make(chan struct{})}
+	core := NewZapCore(sink, zapcore.DebugLevel, nil)
+	defer core.Close()
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		close(sink.release)
+	}()
+
+	// zap exits right after writing a fatal entry, it must have reached the node
+	require.NoError(t, core.Write(zapcore.Entry{Level: zapcore.FatalLevel, Message: "fatal"}, nil))
+	entries := sink.all()
+	require.Len(t, entries, 1)
+	assert.Equal(t, "fatal", entries[0].Level)
+}
+
+func TestZapCore_BoundsWaitForEntriesAboveError(t *testing.T) {
+	sink := &blockingSink{release: make(chan struct{})}
+	core := NewZapCore(sink, zapcore.DebugLevel, &ZapCoreOptions{FlushTimeout: 10 * time.Millisecond})
+	defer func() {
+		close(sink.release)
+		core.Close()
+	}()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		core.Write(zapcore.Entry{Level: zapcore.PanicLevel, Message: "panic"}, nil)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("writing a panic entry blocked on a stuck node")
+	}
+}
@@ -16,12 +16,16 @@ type Collector struct {
 	nodeMutex sync.RWMutex
 
 	// Prometheus metrics
-	operationLatency    *prometheus.HistogramVec
-	operationErrors     *prometheus.CounterVec
-	nodeStatusGauge     *prometheus.GaugeVec
-	logProcessingGauge  *prometheus.GaugeVec
-	modelLatencyHist    *prometheus.HistogramVec
+	operationLatency     *prometheus.HistogramVec
+	operationErrors      *prometheus.CounterVec
+	nodeStatusGauge      *prometheus.GaugeVec
+	logProcessingGauge   *prometheus.GaugeVec
+	modelLatencyHist     *prometheus.HistogramVec
 	repositoryErrorCount *prometheus.CounterVec
+	channelDropCount     *prometheus.CounterVec
+	solutionCount        *prometheus.CounterVec
+	occurrenceCount      *prometheus.CounterVec
+	ingestionDropCount   *prometheus.CounterVec
 }
 
 // NodeMetrics represents metrics for a specific node
@@ -95,6 +99,38 @@ func (c *Collector) Initialize(ctx context.Context) error {
 		[]string{"node_id", "operation", "error_type"},
 	)
 
+	c.channelDropCount = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "node_channel_dropped_total",
+			Help: "Total number of items dropped from node output channels",
+		},
+		[]string{"node_id", "channel", "reason"},
+	)
+
+	c.ingestionDropCount = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "node_ingestion_dropped_total",
+			Help: "Total number of log entries dropped by node sampling and rate limiting",
+		},
+		[]string{"node_id", "level", "reason"},
+	)
+
+	c.solutionCount = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "node_solutions_total",
+			Help: "Total number of solutions generated per incident fingerprint",
+		},
+		[]string{"node_id", "fingerprint"},
+	)
+
+	c.occurrenceCount = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "node_incident_occurrences_total",
+			Help: "Total number of log entries attached to incidents per fingerprint",
+		},
+		[]string{"node_id", "fingerprint"},
+	)
+
 	// Register metrics
 	metrics := []prometheus.Collector{
 		c.operationLatency,
@@ -103,6 +139,10 @@ func (c *Collector) Initialize(ctx context.Context) error {
 		c.logProcessingGauge,
 		c.modelLatencyHist,
 		c.repositoryErrorCount,
+		c.channelDropCount,
+		c.ingestionDropCount,
+		c.solutionCount,
+		c.occurrenceCount,
 	}
 
 	for _, metric := range metrics {
@@ -159,6 +199,8 @@ func (c *Collector) RecordNodeStatusChange(ctx context.Context, nodeID string, s
 		Timestamp: time.Now(),
 	})
 
+	// Only the current status keeps a series
+	c.nodeStatusGauge.DeletePartialMatch(prometheus.Labels{"node_id": nodeID})
 	c.nodeStatusGauge.WithLabelValues(nodeID, status).Set(statusToValue(status))
 	return nil
 }
@@ -203,6 +245,46 @@ func (c *Collector) RecordRepositoryError(ctx context.Context, nodeID string, op
 	return nil
 }
 
+// RecordChannelDrop records an item dropped from a node output channel
+func (c *Collector) RecordChannelDrop(ctx context.Context, nodeID string, channel string, reason string) error {
+	c.nodeMutex.RLock()
+	defer c.nodeMutex.RUnlock()
+
+	if _, exists := c.nodes[nodeID]; !exists {
+		return fmt.Errorf("node not found: %s", nodeID)
+	}
+
+	c.channelDropCount.WithLabelValues(nodeID, channel, reason).Inc()
+	return nil
+}
+
+// RecordIngestionDrop records a log entry dropped by the node ingestion policy
+func (c *Collector) RecordIngestionDrop(ctx context.Context, nodeID string, level string, reason string) error {
+	c.nodeMutex.RLock()
+	defer c.nodeMutex.RUnlock()
+
+	if _, exists := c.nodes[nodeID]; !exists {
+		return fmt.Errorf("node not found: %s", nodeID)
+	}
+
+	c.ingestionDropCount.WithLabelValues(nodeID, level, reason).Inc()
+	return nil
+}
+
+// RecordIncident records a solution generated for an incident and its occurrences
+func (c *Collector) RecordIncident(ctx context.Context, nodeID string, fingerprint string, occurrences int) error {
+	c.nodeMutex.RLock()
+	defer c.nodeMutex.RUnlock()
+
+	if _, exists := c.nodes[nodeID]; !exists {
+		return fmt.Errorf("node not found: %s", nodeID)
+	}
+
+	c.solutionCount.WithLabelValues(nodeID, fingerprint).Inc()
+	c.occurrenceCount.WithLabelValues(nodeID, fingerprint).Add(float64(occurrences))
+	return nil
+}
+
 // CleanupNodeMetrics removes metrics for a node
 func (c *Collector) CleanupNodeMetrics(ctx context.Context, nodeID string) error {
 	c.nodeMutex.Lock()
@@ -215,9 +297,12 @@ func (c *Collector) CleanupNodeMetrics(ctx context.Context, nodeID string) error
 	delete(c.nodes, nodeID)
 
 	// Remove node-specific metrics
-	c.nodeStatusGauge.DeleteLabelValues(nodeID, "active")
-	c.nodeStatusGauge.DeleteLabelValues(nodeID, "error")
+	c.nodeStatusGauge.DeletePartialMatch(prometheus.Labels{"node_id": nodeID})
 	c.logProcessingGauge.DeleteLabelValues(nodeID)
+	c.channelDropCount.DeletePartialMatch(prometheus.Labels{"node_id": nodeID})
+	c.ingestionDropCount.DeletePartialMatch(prometheus.Labels{"node_id": nodeID})
+	c.solutionCount.DeletePartialMatch(prometheus.Labels{"node_id": nodeID})
+	c.occurrenceCount.DeletePartialMatch(prometheus.Labels{"node_id": nodeID})
 
 	return nil
 }
@@ -233,8 +318,8 @@ func (c *Collector) GetCurrentMetrics() map[string]interface{} {
 	nodeMetrics := make(map[string]interface{})
 	for nodeID, node := range c.nodes {
 		nodeMetrics[nodeID] = map[string]interface{}{
-			"created_at":      node.CreatedAt,
-			"last_active":     node.LastActive,
+			"created_at":     node.CreatedAt,
+			"last_active":    node.LastActive,
 			"status_history": node.StatusHistory,
 		}
 	}
@@ -245,13 +330,24 @@ func (c *Collector) GetCurrentMetrics() map[string]interface{} {
 
 // Helper functions
 
+// statusToValue maps a node status to its gauge value, one per lifecycle state:
+// initializing 0, operational 1, processing 2, degraded 3, draining 4, stopped 5
+// and error 6. "active" and "failed" are kept as aliases of operational and error.
 func statusToValue(status string) float64 {
 	switch status {
-	case "active", "operational":
+	case "operational", "active":
 		return 1
-	case "error", "failed":
+	case "processing":
 		return 2
+	case "degraded":
+		return 3
+	case "draining":
+		return 4
+	case "stopped":
+		return 5
+	case "error", "failed":
+		return 6
 	default:
 		return 0
 	}
-} 
\ No newline at end of file
+}
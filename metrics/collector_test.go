@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -58,6 +59,44 @@ func TestRecordNodeStatusChange(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestRecordNodeStatusChange_KeepsCurrentStatusOnly(t *testing.T) {
+	collector, ctx := setupTest(t)
+	require.NoError(t, collector.InitializeNodeMetrics(ctx, "test-node"))
+
+	for _, status := range []string{"operational", "processing", "degraded", "draining"} {
+		require.NoError(t, collector.RecordNodeStatusChange(ctx, "test-node", status))
+	}
+
+	assert.Equal(t, 1, testutil.CollectAndCount(collector.nodeStatusGauge))
+	assert.Equal(t, float64(4), testutil.ToFloat64(collector.nodeStatusGauge.WithLabelValues("test-node", "draining")))
+
+	require.NoError(t, collector.CleanupNodeMetrics(ctx, "test-node"))
+	assert.Equal(t, 0, testutil.CollectAndCount(collector.nodeStatusGauge))
+}
+
+func TestStatusToValue(t *testing.T) {
+	tests := []struct {
+		status string
+		want   float64
+	}{
+		{status: "initializing", want: 0},
+		{status: "operational", want: 1},
+		{status: "active", want: 1},
+		{status: "processing", want: 2},
+		{status: "degraded", want: 3},
+		{status: "draining", want: 4},
+		{status: "stopped", want: 5},
+		{status: "error", want: 6},
+		{status: "failed", want: 6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			assert.Equal(t, tt.want, statusToValue(tt.status))
+		})
+	}
+}
+
 func TestRecordLogProcessing(t *testing.T) {
 	collector, ctx := setupTest(t)
 
@@ -173,4 +212,64 @@ func TestGetCurrentMetrics(t *testing.T) {
 	history := testNodeMetrics["status_history"].([]StatusChange)
 	assert.Equal(t, 1, len(history))
 	assert.Equal(t, "active", history[0].Status)
-} 
\ No newline at end of file
+}
+
+func TestRecordChannelDrop(t *testing.T) {
+	collector, ctx := setupTest(t)
+
+	// Initialize node
+	err := collector.InitializeNodeMetrics(ctx, "test-node")
+	require.NoError(t, err)
+
+	// Test successful drop recording
+	err = collector.RecordChannelDrop(ctx, "test-node", "solution", "drop_newest")
+	assert.NoError(t, err)
+	assert.Equal(t, float64(1), testutil.ToFloat64(collector.channelDropCount.WithLabelValues("test-node", "solution", "drop_newest")))
+
+	// Test non-existent node
+	err = collector.RecordChannelDrop(ctx, "non-existent", "solution", "drop_newest")
+	assert.Error(t, err)
+}
+
+func TestRecordIngestionDrop(t *testing.T) {
+	collector, ctx := setupTest(t)
+
+	// Initialize node
+	err := collector.InitializeNodeMetrics(ctx, "test-node")
+	require.NoError(t, err)
+
+	// Test successful drop recording
+	require.NoError(t, collector.RecordIngestionDrop(ctx, "test-node", "info", "sampled"))
+	require.NoError(t, collector.RecordIngestionDrop(ctx, "test-node", "info", "sampled"))
+	require.NoError(t, collector.RecordIngestionDrop(ctx, "test-node", "debug", "rate_limited"))
+	assert.Equal(t, float64(2), testutil.ToFloat64(collector.ingestionDropCount.WithLabelValues("test-node", "info", "sampled")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(collector.ingestionDropCount.WithLabelValues("test-node", "debug", "rate_limited")))
+
+	// Test cleanup
+	require.NoError(t, collector.CleanupNodeMetrics(ctx, "test-node"))
+	assert.Equal(t, 0, testutil.CollectAndCount(collector.ingestionDropCount))
+
+	// Test non-existent node
+	err = collector.RecordIngestionDrop(ctx, "non-existent", "info", "sampled")
+	assert.Error(t, err)
+}
+
+func TestRecordIncident(t *testing.T) {
+	collector, ctx := setupTest(t)
+
+	// Initialize node
+	err := collector.InitializeNodeMetrics(ctx, "test-node")
+	require.NoError(t, err)
+
+	// Test successful incident recording
+	err = collector.RecordIncident(ctx, "test-node", "5d41402abc4b2a76", 3)
+	assert.NoError(t, err)
+	err = collector.RecordIncident(ctx, "test-node", "5d41402abc4b2a76", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(2), testutil.ToFloat64(collector.solutionCount.WithLabelValues("test-node", "5d41402abc4b2a76")))
+	assert.Equal(t, float64(5), testutil.ToFloat64(collector.occurrenceCount.WithLabelValues("test-node", "5d41402abc4b2a76")))
+
+	// Test non-existent node
+	err = collector.RecordIncident(ctx, "non-existent", "5d41402abc4b2a76", 1)
+	assert.Error(t, err)
+}
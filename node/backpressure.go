@@ -0,0 +1,324 @@
+package node
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/HoyeonS/hephaestus/pkg/hephaestus"
+)
+
+const (
+	// defaultChannelCapacity is used when no channel capacity is configured
+	defaultChannelCapacity = 100
+	// defaultBlockTimeout bounds how long the block policy waits for the consumer
+	defaultBlockTimeout = 5 * time.Second
+
+	// spillFileSuffix marks queued items in a spill directory
+	spillFileSuffix = ".json"
+)
+
+// outbox delivers items on an output channel according to a backpressure policy
+type outbox[T any] struct {
+	name    string
+	ch      chan T
+	policy  hephaestus.BackpressurePolicy
+	timeout time.Duration
+	spill   *spillQueue[T]
+	onDrop  func(channel, reason string)
+}
+
+// newOutbox creates an outbox from the channel configuration. Spilled items are kept
+// in a subdirectory named after the channel, so channels sharing a spill directory
+// never read each other's items. onError, when set, is told about spill files that
+// could not be removed after delivery.
+func newOutbox[T any](name string, config hephaestus.ChannelConfiguration, codec spillCodec[T], onDrop func(channel, reason string), onError func(channel string, err error)) (*outbox[T], error) {
+	capacity := config.Capacity
+	if capacity <= 0 {
+		capacity = defaultChannelCapacity
+	}
+	policy := config.Policy
+	if policy == "" {
+		policy = hephaestus.BackpressurePolicyBlock
+	}
+	timeout := config.BlockTimeout
+	if timeout <= 0 {
+		timeout = defaultBlockTimeout
+	}
+
+	o := &outbox[T]{
+		name:    name,
+		ch:      make(chan T, capacity),
+		policy:  policy,
+		timeout: timeout,
+		onDrop:  onDrop,
+	}
+
+	if policy == hephaestus.BackpressurePolicySpill {
+		var report func(error)
+		if onError != nil {
+			report = func(err error) { onError(name, err) }
+		}
+		spill, err := newSpillQueue(filepath.Join(config.SpillDirectory, name), o.ch, codec, report)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s spill queue: %v", name, err)
+		}
+		o.spill = spill
+	}
+
+	return o, nil
+}
+
+// send delivers an item, applying the backpressure policy when the channel is full
+func (o *outbox[T]) send(item T) {
+	switch o.policy {
+	case hephaestus.BackpressurePolicyDropNewest:
+		select {
+		case o.ch <- item:
+		default:
+			o.drop(string(hephaestus.BackpressurePolicyDropNewest))
+		}
+
+	case hephaestus.BackpressurePolicyDropOldest:
+		for {
+			select {
+			case o.ch <- item:
+				return
+			default:
+			}
+			select {
+			case <-o.ch:
+				o.drop(string(hephaestus.BackpressurePolicyDropOldest))
+			default:
+			}
+		}
+
+	case hephaestus.BackpressurePolicySpill:
+		if err := o.spill.push(item); err != nil {
+			o.drop("spill_failed")
+		}
+
+	default:
+		timer := time.NewTimer(o.timeout)
+		defer timer.Stop()
+		select {
+		case o.ch <- item:
+		case <-timer.C:
+			o.drop("block_timeout")
+		}
+	}
+}
+
+// drop reports a dropped item
+func (o *outbox[T]) drop(reason string) {
+	if o.onDrop != nil {
+		o.onDrop(o.name, reason)
+	}
+}
+
+// close stops delivery and closes the channel.
+// Callers must guarantee no send is in progress. Spilled items stay on disk and are
+// delivered by the next outbox opened on the same directory.
+func (o *outbox[T]) close() {
+	if o.spill != nil {
+		o.spill.close()
+	}
+	close(o.ch)
+}
+
+// spillCodec converts items to and from their on-disk representation
+type spillCodec[T any] struct {
+	encode func(T) ([]byte, error)
+	decode func([]byte) (T, error)
+}
+
+// spillQueue is a FIFO of items stored as files in a local directory.
+// Items are moved from disk to the channel by a pump goroutine as room frees up.
+type spillQueue[T any] struct {
+	dir   string
+	ch    chan T
+	codec spillCodec[T]
+	// onError reports spill files that could not be removed
+	onError func(error)
+
+	mu      sync.Mutex
+	pending []string
+	next    uint64
+
+	wake chan struct{}
+	stop chan struct{}
+	done chan struct{}
+}
+
+// newSpillQueue opens a spill directory and resumes delivery of any items left in it
+func newSpillQueue[T any](dir string, ch chan T, codec spillCodec[T], onError func(error)) (*spillQueue[T], error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	q := &spillQueue[T]{
+		dir:     dir,
+		ch:      ch,
+		codec:   codec,
+		onError: onError,
+		wake:    make(chan struct{}, 1),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+
+	for _, file := range files {
+		name := file.Name()
+		if file.IsDir() || !strings.HasSuffix(name, spillFileSuffix) {
+			continue
+		}
+		var seq uint64
+		if _, err := fmt.Sscanf(strings.TrimSuffix(name, spillFileSuffix), "%d", &seq); err != nil {
+			continue
+		}
+		q.pending = append(q.pending, name)
+		if seq >= q.next {
+			q.next = seq + 1
+		}
+	}
+	sort.Strings(q.pending)
+
+	go q.pump()
+	q.notify()
+
+	return q, nil
+}
+
+// push delivers the item directly when nothing is spilled and the channel has room,
+// otherwise it is appended to the spill directory to preserve ordering
+func (q *spillQueue[T]) push(item T) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.pending) == 0 {
+		select {
+		case q.ch <- item:
+			return nil
+		default:
+		}
+	}
+
+	data, err := q.codec.encode(item)
+	if err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%020d%s", q.next, spillFileSuffix)
+	if err := os.WriteFile(filepath.Join(q.dir, name), data, 0600); err != nil {
+		return err
+	}
+	q.next++
+	q.pending = append(q.pending, name)
+	q.notify()
+
+	return nil
+}
+
+// notify wakes the pump goroutine
+func (q *spillQueue[T]) notify() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// pump moves spilled items onto the channel in order until the queue is closed
+func (q *spillQueue[T]) pump() {
+	defer close(q.done)
+
+	for {
+		q.mu.Lock()
+		var name string
+		if len(q.pending) > 0 {
+			name = q.pending[0]
+		}
+		q.mu.Unlock()
+
+		if name == "" {
+			select {
+			case <-q.wake:
+				continue
+			case <-q.stop:
+				return
+			}
+		}
+
+		path := filepath.Join(q.dir, name)
+		item, err := q.load(path)
+		if err == nil {
+			select {
+			case q.ch <- item:
+			case <-q.stop:
+				return
+			}
+		}
+
+		// Unreadable items are discarded so they cannot wedge the queue. A file that
+		// cannot be removed is skipped, it is delivered again after a restart.
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) && q.onError != nil {
+			q.onError(err)
+		}
+		q.mu.Lock()
+		q.pending = q.pending[1:]
+		q.mu.Unlock()
+	}
+}
+
+// load reads and decodes a spilled item
+func (q *spillQueue[T]) load(path string) (T, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return q.codec.decode(data)
+}
+
+// close stops the pump goroutine and waits for it to exit
+func (q *spillQueue[T]) close() {
+	close(q.stop)
+	<-q.done
+}
+
+// solutionCodec stores solutions as JSON
+var solutionCodec = spillCodec[*hephaestus.Solution]{
+	encode: func(solution *hephaestus.Solution) ([]byte, error) {
+		return json.Marshal(solution)
+	},
+	decode: func(data []byte) (*hephaestus.Solution, error) {
+		var solution hephaestus.Solution
+		if err := json.Unmarshal(data, &solution); err != nil {
+			return nil, err
+		}
+		return &solution, nil
+	},
+}
+
+// errorCodec stores errors by their message
+var errorCodec = spillCodec[error]{
+	encode: func(err error) ([]byte, error) {
+		return json.Marshal(err.Error())
+	},
+	decode: func(data []byte) (error, error) {
+		var message string
+		if err := json.Unmarshal(data, &message); err != nil {
+			return nil, err
+		}
+		return errors.New(message), nil
+	},
+}
@@ -0,0 +1,245 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/HoyeonS/hephaestus/pkg/hephaestus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dropRecorder collects dropped item reports
+type dropRecorder struct {
+	mu    sync.Mutex
+	drops []string
+}
+
+func (r *dropRecorder) record(channel, reason string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.drops = append(r.drops, channel+":"+reason)
+}
+
+func (r *dropRecorder) all() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.drops...)
+}
+
+func drainInts(ch chan int) []int {
+	var items []int
+	for {
+		select {
+		case item := <-ch:
+			items = append(items, item)
+		default:
+			return items
+		}
+	}
+}
+
+var intCodec = spillCodec[int]{
+	encode: func(i int) ([]byte, error) { return []byte{byte(i)}, nil },
+	decode: func(data []byte) (int, error) { return int(data[0]), nil },
+}
+
+func TestOutbox_Policies(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    hephaestus.ChannelConfiguration
+		wantItems []int
+		wantDrops []string
+	}{
+		{
+			name:      "drop newest",
+			config:    hephaestus.ChannelConfiguration{Capacity: 2, Policy: hephaestus.BackpressurePolicyDropNewest},
+			wantItems: []int{1, 2},
+			wantDrops: []string{"test:drop_newest", "test:drop_newest"},
+		},
+		{
+			name:      "drop oldest",
+			config:    hephaestus.ChannelConfiguration{Capacity: 2, Policy: hephaestus.BackpressurePolicyDropOldest},
+			wantItems: []int{3, 4},
+			wantDrops: []string{"test:drop_oldest", "test:drop_oldest"},
+		},
+		{
+			name:      "block with timeout",
+			config:    hephaestus.ChannelConfiguration{Capacity: 2, BlockTimeout: 10 * time.Millisecond},
+			wantItems: []int{1, 2},
+			wantDrops: []string{"test:block_timeout", "test:block_timeout"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := &dropRecorder{}
+			o, err := newOutbox("test", tt.config, intCodec, recorder.record, nil)
+			require.NoError(t, err)
+
+			for i := 1; i <= 4; i++ {
+				o.send(i)
+			}
+
+			assert.Equal(t, tt.wantItems, drainInts(o.ch))
+			assert.Equal(t, tt.wantDrops, recorder.all())
+			o.close()
+		})
+	}
+}
+
+func TestOutbox_SpillPreservesOrderAcrossRestart(t *testing.T) {
+	dir := t.TempDir()
+	config := hephaestus.ChannelConfiguration{Capacity: 2, Policy: hephaestus.BackpressurePolicySpill, SpillDirectory: dir}
+	recorder := &dropRecorder{}
+
+	o, err := newOutbox("test", config, intCodec, recorder.record, nil)
+	require.NoError(t, err)
+	for i := 1; i <= 5; i++ {
+		o.send(i)
+	}
+	assert.Equal(t, 1, <-o.ch)
+	o.close()
+
+	// Items still on disk are delivered by the next outbox on the same directory
+	reopened, err := newOutbox("test", config, intCodec, recorder.record, nil)
+	require.NoError(t, err)
+	defer reopened.close()
+
+	var received []int
+	for item := range o.ch {
+		received = append(received, item)
+	}
+	for len(received) < 4 {
+		select {
+		case item := <-reopened.ch:
+			received = append(received, item)
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for spilled items, got %v", received)
+		}
+	}
+
+	assert.Equal(t, []int{2, 3, 4, 5}, received)
+	assert.Empty(t, recorder.all())
+}
+
+func TestOutbox_SpillChannelsShareDirectory(t *testing.T) {
+	config := hephaestus.ChannelConfiguration{Capacity: 1, Policy: hephaestus.BackpressurePolicySpill, SpillDirectory: t.TempDir()}
+
+	first, err := newOutbox("first", config, intCodec, nil, nil)
+	require.NoError(t, err)
+	second, err := newOutbox("second", config, intCodec, nil, nil)
+	require.NoError(t, err)
+	for i := 1; i <= 3; i++ {
+		first.send(i)
+		second.send(10 + i)
+	}
+	first.close()
+	second.close()
+
+	// Each reopened channel resumes only its own spilled items
+	for name, want := range map[string][]int{"first": {2, 3}, "second": {12, 13}} {
+		reopened, err := newOutbox(name, config, intCodec, nil, nil)
+		require.NoError(t, err)
+
+		var received []int
+		for len(received) < len(want) {
+			select {
+			case item := <-reopened.ch:
+				received = append(received, item)
+			case <-time.After(time.Second):
+				t.Fatalf("timed out waiting for %s items, got %v", name, received)
+			}
+		}
+		assert.Equal(t, want, received)
+		assert.Empty(t, drainInts(reopened.ch))
+		reopened.close()
+	}
+}
+
+// fakeMetricsCollector records metrics reported by the node
+type fakeMetricsCollector struct {
+	mu        sync.Mutex
+	drops     []string
+	incidents []string
+	statuses  []string
+	ingestion []string
+	nodes     []string
+}
+
+func (f *fakeMetricsCollector) InitializeNodeMetrics(ctx context.Context, nodeID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.nodes = append(f.nodes, "+"+nodeID)
+	return nil
+}
+
+func (f *fakeMetricsCollector) CleanupNodeMetrics(ctx context.Context, nodeID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.nodes = append(f.nodes, "-"+nodeID)
+	return nil
+}
+
+func (f *fakeMetricsCollector) RecordChannelDrop(ctx context.Context, nodeID, channel, reason string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.drops = append(f.drops, nodeID+":"+channel+":"+reason)
+	return nil
+}
+
+func (f *fakeMetricsCollector) RecordIncident(ctx context.Context, nodeID, fingerprint string, occurrences int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.incidents = append(f.incidents, fmt.Sprintf("%s:%s:%d", nodeID, fingerprint, occurrences))
+	return nil
+}
+
+func (f *fakeMetricsCollector) RecordIngestionDrop(ctx context.Context, nodeID, level, reason string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.ingestion = append(f.ingestion, nodeID+":"+level+":"+reason)
+	return nil
+}
+
+func (f *fakeMetricsCollector) RecordNodeStatusChange(ctx context.Context, nodeID string, status string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.statuses = append(f.statuses, nodeID+":"+status)
+	return nil
+}
+
+func TestNode_ChannelDropsAreRecorded(t *testing.T) {
+	node := newTestNode(t, withBackpressure(hephaestus.BackpressureConfiguration{
+		Solution: hephaestus.ChannelConfiguration{Capacity: 1, Policy: hephaestus.BackpressurePolicyDropNewest},
+	}))
+
+	collector := &fakeMetricsCollector{}
+	node.SetMetricsCollector(collector)
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, node.ProcessLog(hephaestus.LogEntry{Level: "error", Message: "boom " + distinctWord(i)}))
+	}
+	require.NoError(t, node.Stop(context.Background()))
+
+	count := 0
+	for range node.GetSolutions() {
+		count++
+	}
+	assert.Equal(t, 1, count)
+	assert.Equal(t, []string{"test-node:solution:drop_newest", "test-node:solution:drop_newest"}, collector.drops)
+	assert.Len(t, collector.incidents, 3)
+}
+
+func TestErrorCodec(t *testing.T) {
+	data, err := errorCodec.encode(errors.New("boom"))
+	require.NoError(t, err)
+
+	decoded, err := errorCodec.decode(data)
+	require.NoError(t, err)
+	assert.EqualError(t, decoded, "boom")
+}
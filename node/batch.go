@@ -0,0 +1,122 @@
+package node
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/HoyeonS/hephaestus/pkg/hephaestus"
+	"github.com/HoyeonS/hephaestus/pkg/hephaestus/fingerprint"
+	"go.uber.org/zap"
+)
+
+// batchEntry is a validated batch entry and its position in the submitted batch
+type batchEntry struct {
+	index      int
+	entry      hephaestus.LogEntry
+	redactions []hephaestus.Redaction
+}
+
+// ProcessLogs processes a batch of log entries.
+// Entries failing validation are rejected individually, entries dropped by sampling or
+// rate limiting are skipped and the rest are processed in timestamp order, entries
+// without a timestamp taking the time the batch was received.
+// The trigger fires at most once per batch: threshold entries after it count towards
+// the next trigger. The result counts the accepted and dropped entries; accepted
+// entries the write-ahead log failed to store are counted as unpersisted rather than
+// failed, since they are buffered already. When some entries fail a
+// *hephaestus.BatchError lists them by their position in entries.
+func (n *Node) ProcessLogs(entries []hephaestus.LogEntry) (hephaestus.BatchResult, error) {
+	result := hephaestus.BatchResult{Total: len(entries)}
+	if len(entries) == 0 {
+		return result, fmt.Errorf("%w: empty log batch", hephaestus.ErrInvalidArgument)
+	}
+	if limit := n.systemConfig.LimitConfiguration.LogBatchLimit; limit > 0 && len(entries) > limit {
+		return result, fmt.Errorf("%w: log batch of %d entries exceeds the limit of %d", hephaestus.ErrInvalidArgument, len(entries), limit)
+	}
+	// A stopped node takes nothing, not even sampling or rate limit tokens
+	if n.isStopped() {
+		return result, fmt.Errorf("node is stopped: %w", hephaestus.ErrUnavailable)
+	}
+
+	batchErr := &hephaestus.BatchError{Total: len(entries)}
+	fail := func(index int, err error) {
+		batchErr.Failures = append(batchErr.Failures, &hephaestus.LogEntryError{Index: index, Err: err})
+	}
+
+	receivedAt := time.Now()
+	accepted := make([]batchEntry, 0, len(entries))
+	for i, entry := range entries {
+		if err := hephaestus.ValidateLogEntry(entry); err != nil {
+			fail(i, err)
+			continue
+		}
+		if reason, level := n.ingestion.admit(entry, receivedAt); reason != "" {
+			n.recordIngestionDrop(level, reason)
+			batchErr.Dropped++
+			continue
+		}
+		if entry.Timestamp.IsZero() {
+			entry.Timestamp = receivedAt
+		}
+		entry, redactions := n.redact(entry)
+		accepted = append(accepted, batchEntry{index: i, entry: entry, redactions: redactions})
+	}
+	sort.SliceStable(accepted, func(i, j int) bool {
+		return accepted[i].entry.Timestamp.Before(accepted[j].entry.Timestamp)
+	})
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	// Stop may have been called while the batch was admitted
+	if n.stopped {
+		return result, fmt.Errorf("node is stopped: %w", hephaestus.ErrUnavailable)
+	}
+
+	triggered := false
+	for _, item := range accepted {
+		n.recordRedactions(item.redactions)
+		if err := n.ingest(item.entry); err != nil {
+			result.Unpersisted++
+		}
+
+		if triggered {
+			n.countThreshold(item.entry)
+			continue
+		}
+		if n.shouldProcessLogs(item.entry) {
+			triggered = true
+			if err := n.triggerLogProcessing(); err != nil {
+				fail(item.index, err)
+			}
+		}
+	}
+
+	result.Accepted, result.Dropped = batchErr.Accepted(), batchErr.Dropped
+	if result.Unpersisted > 0 {
+		n.logger.Warn("Accepted log entries without persisting them",
+			zap.Int("unpersisted", result.Unpersisted),
+			zap.Int("accepted", result.Accepted),
+		)
+	}
+	if len(batchErr.Failures) == 0 {
+		return result, nil
+	}
+	sort.Slice(batchErr.Failures, func(i, j int) bool {
+		return batchErr.Failures[i].Index < batchErr.Failures[j].Index
+	})
+	return result, batchErr
+}
+
+// countThreshold counts a threshold entry that arrived after the batch trigger fired.
+// Entries of an open incident attach to it as usual. Callers must hold n.mu.
+func (n *Node) countThreshold(entry hephaestus.LogEntry) {
+	if !n.threshold.matches(entry.Level) {
+		return
+	}
+	if n.incidents.attach(fingerprint.Compute(entry), time.Now()) {
+		return
+	}
+	n.threshold.record(entry.Timestamp)
+}
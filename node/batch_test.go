@@ -0,0 +1,119 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/HoyeonS/hephaestus/pkg/hephaestus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNode_ProcessLogsRejectsInvalidEntries(t *testing.T) {
+	node := newTestNode(t)
+
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	_, err := node.ProcessLogs([]hephaestus.LogEntry{
+		{Timestamp: base.Add(2 * time.Second), Level: "info", Message: "second"},
+		{Timestamp: base, Level: "loud", Message: "unknown level"},
+		{Timestamp: base.Add(time.Second), Level: "info", Message: "first"},
+		{Timestamp: base, Level: "info", Message: "  "},
+	})
+
+	var batchErr *hephaestus.BatchError
+	require.ErrorAs(t, err, &batchErr)
+	assert.ErrorIs(t, err, hephaestus.ErrInvalidArgument)
+	assert.Equal(t, 2, batchErr.Accepted())
+	require.Len(t, batchErr.Failures, 2)
+	assert.Equal(t, 1, batchErr.Failures[0].Index)
+	assert.Equal(t, 3, batchErr.Failures[1].Index)
+
+	// Accepted entries are buffered in timestamp order
+	var messages []string
+	for _, entry := range node.logBuffer.Snapshot() {
+		messages = append(messages, entry.Message)
+	}
+	assert.Equal(t, []string{"first", "second"}, messages)
+}
+
+func TestNode_ProcessLogsBatchValidation(t *testing.T) {
+	node := newTestNode(t, withLimits(hephaestus.LimitConfiguration{LogBatchLimit: 2}))
+
+	entry := hephaestus.LogEntry{Level: "info", Message: "ok"}
+	_, err := node.ProcessLogs(nil)
+	assert.ErrorIs(t, err, hephaestus.ErrInvalidArgument)
+	_, err = node.ProcessLogs([]hephaestus.LogEntry{entry, entry, entry})
+	assert.ErrorIs(t, err, hephaestus.ErrInvalidArgument)
+	result, err := node.ProcessLogs([]hephaestus.LogEntry{entry, entry})
+	assert.NoError(t, err)
+	assert.Equal(t, hephaestus.BatchResult{Total: 2, Accepted: 2}, result)
+
+	require.NoError(t, node.Stop(context.Background()))
+	_, err = node.ProcessLogs([]hephaestus.LogEntry{entry})
+	assert.ErrorIs(t, err, hephaestus.ErrUnavailable)
+}
+
+func TestNode_ProcessLogsTriggersOncePerBatch(t *testing.T) {
+	node := newTestNode(t, withLogProcessing(hephaestus.LogProcessingConfiguration{ThresholdCount: 2}))
+
+	base := time.Now()
+	batch := make([]hephaestus.LogEntry, 5)
+	for i := range batch {
+		batch[i] = hephaestus.LogEntry{Timestamp: base.Add(time.Duration(i) * time.Millisecond), Level: "error", Message: "boom " + distinctWord(i)}
+	}
+	_, err := node.ProcessLogs(batch)
+	require.NoError(t, err)
+
+	// The three entries after the trigger carry over, one more fires the next flow
+	node.mu.Lock()
+	assert.Len(t, node.threshold.hits, 3)
+	node.mu.Unlock()
+	require.NoError(t, node.ProcessLog(hephaestus.LogEntry{Level: "error", Message: "boom " + distinctWord(5)}))
+	require.NoError(t, node.Stop(context.Background()))
+
+	var triggers []string
+	for solution := range node.GetSolutions() {
+		triggers = append(triggers, solution.LogEntry.Message)
+	}
+	assert.ElementsMatch(t, []string{"boom " + distinctWord(1), "boom " + distinctWord(5)}, triggers)
+}
+
+func TestBatchError(t *testing.T) {
+	err := &hephaestus.BatchError{Total: 4, Dropped: 1, Failures: []*hephaestus.LogEntryError{
+		{Index: 2, Err: errors.New("boom")},
+	}}
+
+	assert.EqualError(t, err, "1 of 4 log entries failed, first: log entry 2: boom")
+	assert.Equal(t, 2, err.Accepted())
+}
+
+func TestNode_ProcessLogsOnStoppedNodeDropsNothing(t *testing.T) {
+	node := newTestNode(t, withIngestion(hephaestus.IngestionConfiguration{SampleRates: map[string]float64{"debug": 0}}))
+	collector := &fakeMetricsCollector{}
+	node.SetMetricsCollector(collector)
+	require.NoError(t, node.Stop(context.Background()))
+
+	_, err := node.ProcessLogs([]hephaestus.LogEntry{{Level: "debug", Message: "cache miss"}})
+	assert.ErrorIs(t, err, hephaestus.ErrUnavailable)
+	assert.Empty(t, collector.ingestion)
+}
+
+func TestNode_ProcessLogsCountsUnpersistedEntriesAsAccepted(t *testing.T) {
+	node := newTestNode(t, withJournal(t.TempDir()))
+	require.NoError(t, node.Start(context.Background()))
+	defer node.Stop(context.Background())
+
+	// Appends fail once the write-ahead log is closed
+	require.NoError(t, node.wal.Close())
+	result, err := node.ProcessLogs([]hephaestus.LogEntry{
+		{Level: "info", Message: "first"},
+		{Level: "info", Message: "second"},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, hephaestus.BatchResult{Total: 2, Accepted: 2, Unpersisted: 2}, result)
+	assert.Equal(t, 2, node.BufferStats().Entries)
+	assert.Equal(t, hephaestus.NodeStatusDegraded, node.Status())
+}
@@ -0,0 +1,87 @@
+package node
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/HoyeonS/hephaestus/pkg/hephaestus"
+)
+
+// contextCapture collects the entries received after a trigger until its window ends
+type contextCapture struct {
+	triggeredAt time.Time
+	window      time.Duration
+	limit       int
+	entries     []hephaestus.LogEntry
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// newContextCapture opens an after window, it returns nil when no window is configured
+func newContextCapture(config hephaestus.LogProcessingConfiguration, triggeredAt time.Time) *contextCapture {
+	if config.ContextAfterWindow <= 0 {
+		return nil
+	}
+	return &contextCapture{
+		triggeredAt: triggeredAt,
+		window:      config.ContextAfterWindow,
+		limit:       config.ContextAfterEntries,
+		done:        make(chan struct{}),
+	}
+}
+
+// add captures an entry, closing the window once the entry limit is reached.
+// Callers must hold n.mu.
+func (c *contextCapture) add(entry hephaestus.LogEntry) {
+	select {
+	case <-c.done:
+		return
+	default:
+	}
+
+	c.entries = append(c.entries, entry)
+	if c.limit > 0 && len(c.entries) >= c.limit {
+		c.finish()
+	}
+}
+
+// finish closes the window early
+func (c *contextCapture) finish() {
+	c.closeOnce.Do(func() { close(c.done) })
+}
+
+// wait blocks until the window ends or ctx is done
+func (c *contextCapture) wait(ctx context.Context) {
+	timer := time.NewTimer(time.Until(c.triggeredAt.Add(c.window)))
+	defer timer.Stop()
+
+	select {
+	case <-c.done:
+	case <-timer.C:
+		c.finish()
+	case <-ctx.Done():
+		c.finish()
+	}
+}
+
+// contextBefore selects the entries preceding a trigger within the configured entry
+// count and time window, the trigger is the last entry and kept on top of the count.
+// Entries without a timestamp are kept.
+func contextBefore(config hephaestus.LogProcessingConfiguration, entries []hephaestus.LogEntry, triggeredAt time.Time) []hephaestus.LogEntry {
+	selected := entries
+	if config.ContextBeforeWindow > 0 {
+		cutoff := triggeredAt.Add(-config.ContextBeforeWindow)
+		selected = make([]hephaestus.LogEntry, 0, len(entries))
+		for _, entry := range entries {
+			if entry.Timestamp.IsZero() || !entry.Timestamp.Before(cutoff) {
+				selected = append(selected, entry)
+			}
+		}
+	}
+	if limit := config.ContextBeforeEntries + 1; limit > 1 && len(selected) > limit {
+		selected = selected[len(selected)-limit:]
+	}
+	return selected
+}
@@ -0,0 +1,100 @@
+package node
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/HoyeonS/hephaestus/pkg/hephaestus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContextBefore(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	entries := []hephaestus.LogEntry{
+		{Timestamp: base.Add(-10 * time.Minute), Message: "old"},
+		{Message: "undated"},
+		{Timestamp: base.Add(-30 * time.Second), Message: "recent"},
+		{Timestamp: base, Message: "trigger"},
+	}
+
+	tests := []struct {
+		name   string
+		config hephaestus.LogProcessingConfiguration
+		want   []string
+	}{
+		{name: "no bounds", config: hephaestus.LogProcessingConfiguration{}, want: []string{"old", "undated", "recent", "trigger"}},
+		{name: "time window", config: hephaestus.LogProcessingConfiguration{ContextBeforeWindow: time.Minute}, want: []string{"undated", "recent", "trigger"}},
+		{name: "entry count", config: hephaestus.LogProcessingConfiguration{ContextBeforeEntries: 2}, want: []string{"undated", "recent", "trigger"}},
+		{
+			name:   "both",
+			config: hephaestus.LogProcessingConfiguration{ContextBeforeWindow: time.Minute, ContextBeforeEntries: 1},
+			want:   []string{"recent", "trigger"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var messages []string
+			for _, entry := range contextBefore(tt.config, entries, base) {
+				messages = append(messages, entry.Message)
+			}
+			assert.Equal(t, tt.want, messages)
+		})
+	}
+}
+
+func TestNode_CapturesAfterWindow(t *testing.T) {
+	node := newTestNode(t, withLogProcessing(hephaestus.LogProcessingConfiguration{ContextAfterWindow: 100 * time.Millisecond}))
+
+	require.NoError(t, node.ProcessLog(hephaestus.LogEntry{Level: "info", Message: "accepted order"}))
+	require.NoError(t, node.ProcessLog(hephaestus.LogEntry{Level: "error", Message: "payment failed"}))
+	require.NoError(t, node.ProcessLog(hephaestus.LogEntry{Level: "warn", Message: "retrying charge"}))
+	require.NoError(t, node.ProcessLog(hephaestus.LogEntry{Level: "info", Message: "card declined by issuer"}))
+
+	select {
+	case <-node.GetSolutions():
+		t.Fatal("solution flow ran before the after window ended")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	solution := <-node.GetSolutions()
+	require.NotNil(t, solution.Evidence)
+	assert.Equal(t, "payment failed", solution.LogEntry.Message)
+	assert.Equal(t, "payment failed", solution.Evidence.Trigger.Message)
+	require.Len(t, solution.Evidence.Before, 1)
+	assert.Equal(t, "accepted order", solution.Evidence.Before[0].Message)
+	require.Len(t, solution.Evidence.After, 2)
+	assert.Equal(t, "retrying charge", solution.Evidence.After[0].Message)
+	assert.Equal(t, "card declined by issuer", solution.Evidence.After[1].Message)
+}
+
+func TestNode_AfterWindowEntryLimit(t *testing.T) {
+	node := newTestNode(t, withLogProcessing(hephaestus.LogProcessingConfiguration{ContextAfterWindow: time.Hour, ContextAfterEntries: 1}))
+
+	require.NoError(t, node.ProcessLog(hephaestus.LogEntry{Level: "error", Message: "payment failed"}))
+	require.NoError(t, node.ProcessLog(hephaestus.LogEntry{Level: "info", Message: "retrying charge"}))
+
+	select {
+	case solution := <-node.GetSolutions():
+		require.Len(t, solution.Evidence.After, 1)
+		assert.Equal(t, "retrying charge", solution.Evidence.After[0].Message)
+	case <-time.After(time.Second):
+		t.Fatal("entry limit did not end the after window")
+	}
+}
+
+func TestNode_StopEndsAfterWindow(t *testing.T) {
+	node := newTestNode(t, withLogProcessing(hephaestus.LogProcessingConfiguration{ContextAfterWindow: time.Hour}))
+
+	require.NoError(t, node.ProcessLog(hephaestus.LogEntry{Level: "error", Message: "payment failed"}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, node.Stop(ctx))
+
+	solution := <-node.GetSolutions()
+	require.NotNil(t, solution)
+	assert.Empty(t, solution.Evidence.After)
+}
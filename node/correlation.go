@@ -0,0 +1,116 @@
+package node
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/HoyeonS/hephaestus/pkg/hephaestus"
+)
+
+// correlationValue is a correlation key and the value the trigger carries for it
+type correlationValue struct {
+	key   string
+	value string
+}
+
+// correlationKeys returns the configured correlation keys
+func (n *Node) correlationKeys() []string {
+	if keys := n.clientNodeConfig.LogProcessingConfiguration.CorrelationKeys; keys != nil {
+		return keys
+	}
+	return hephaestus.DefaultCorrelationKeys
+}
+
+// correlationValues returns the correlation keys the entry carries a value for
+func correlationValues(entry hephaestus.LogEntry, keys []string) []correlationValue {
+	var values []correlationValue
+	for _, key := range keys {
+		if value, ok := contextString(entry, key); ok {
+			values = append(values, correlationValue{key: key, value: value})
+		}
+	}
+	return values
+}
+
+// contextString returns a non-empty context value as a string
+func contextString(entry hephaestus.LogEntry, key string) (string, bool) {
+	raw, ok := entry.Context[key]
+	if !ok || raw == nil {
+		return "", false
+	}
+	value := fmt.Sprint(raw)
+	return value, value != ""
+}
+
+// correlate returns the entries sharing any correlation value
+func correlate(entries []hephaestus.LogEntry, values []correlationValue) []hephaestus.LogEntry {
+	if len(values) == 0 {
+		return nil
+	}
+
+	var correlated []hephaestus.LogEntry
+	for _, entry := range entries {
+		for _, v := range values {
+			if value, ok := contextString(entry, v.key); ok && value == v.value {
+				correlated = append(correlated, entry)
+				break
+			}
+		}
+	}
+	return correlated
+}
+
+// findCorrelated searches the log store for entries sharing a correlation value
+func (n *Node) findCorrelated(ctx context.Context, values []correlationValue) ([]hephaestus.LogEntry, error) {
+	n.mu.Lock()
+	store := n.logStore
+	n.mu.Unlock()
+
+	if store == nil {
+		return nil, nil
+	}
+
+	var found []hephaestus.LogEntry
+	for _, v := range values {
+		entries, err := store.FindCorrelated(ctx, v.key, v.value)
+		if err != nil {
+			return found, fmt.Errorf("failed to search log store for %s %q: %w", v.key, v.value, err)
+		}
+		found = append(found, entries...)
+	}
+	return found, nil
+}
+
+// entryIdentity tells copies of the same entry from different sources apart
+type entryIdentity struct {
+	timestamp int64
+	level     string
+	message   string
+}
+
+func identityOf(entry hephaestus.LogEntry) entryIdentity {
+	return entryIdentity{timestamp: entry.Timestamp.UnixNano(), level: entry.Level, message: entry.Message}
+}
+
+// mergeCorrelated joins correlated entries from several sources, dropping duplicates
+// and the trigger itself, and orders them by timestamp
+func mergeCorrelated(trigger hephaestus.LogEntry, sources ...[]hephaestus.LogEntry) []hephaestus.LogEntry {
+	seen := map[entryIdentity]bool{identityOf(trigger): true}
+	var merged []hephaestus.LogEntry
+	for _, entries := range sources {
+		for _, entry := range entries {
+			id := identityOf(entry)
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			merged = append(merged, entry)
+		}
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].Timestamp.Before(merged[j].Timestamp)
+	})
+	return merged
+}
@@ -0,0 +1,103 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/HoyeonS/hephaestus/pkg/hephaestus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCorrelate(t *testing.T) {
+	entries := []hephaestus.LogEntry{
+		{Message: "same trace", Context: map[string]interface{}{"trace_id": "t-1"}},
+		{Message: "same request", Context: map[string]interface{}{"request_id": 42}},
+		{Message: "other trace", Context: map[string]interface{}{"trace_id": "t-2"}},
+		{Message: "no context"},
+	}
+	trigger := hephaestus.LogEntry{Context: map[string]interface{}{"trace_id": "t-1", "request_id": 42, "span_id": "s-1"}}
+
+	tests := []struct {
+		name string
+		keys []string
+		want []string
+	}{
+		{name: "default keys", keys: hephaestus.DefaultCorrelationKeys, want: []string{"same trace", "same request"}},
+		{name: "single key", keys: []string{"request_id"}, want: []string{"same request"}},
+		{name: "key missing on trigger", keys: []string{"session_id"}, want: nil},
+		{name: "no keys", keys: []string{}, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var messages []string
+			for _, entry := range correlate(entries, correlationValues(trigger, tt.keys)) {
+				messages = append(messages, entry.Message)
+			}
+			assert.Equal(t, tt.want, messages)
+		})
+	}
+}
+
+func TestMergeCorrelated(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	trigger := hephaestus.LogEntry{Timestamp: base.Add(3 * time.Second), Level: "error", Message: "trigger"}
+	first := hephaestus.LogEntry{Timestamp: base, Level: "info", Message: "first"}
+	second := hephaestus.LogEntry{Timestamp: base.Add(time.Second), Level: "info", Message: "second"}
+
+	merged := mergeCorrelated(trigger, []hephaestus.LogEntry{second}, []hephaestus.LogEntry{first, second, trigger})
+	assert.Equal(t, []hephaestus.LogEntry{first, second}, merged)
+}
+
+// fakeLogStore serves correlated entries from memory
+type fakeLogStore struct {
+	entries []hephaestus.LogEntry
+	err     error
+}
+
+func (f *fakeLogStore) FindCorrelated(ctx context.Context, key, value string) ([]hephaestus.LogEntry, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return correlate(f.entries, []correlationValue{{key: key, value: value}}), nil
+}
+
+func TestNode_CorrelatesBufferAndLogStore(t *testing.T) {
+	node := newTestNode(t, withLogProcessing(hephaestus.LogProcessingConfiguration{ContextBeforeEntries: 1}))
+
+	base := time.Now()
+	node.SetLogStore(&fakeLogStore{entries: []hephaestus.LogEntry{
+		{Timestamp: base.Add(-time.Hour), Level: "info", Message: "request received", Context: map[string]interface{}{"trace_id": "t-1"}},
+		{Timestamp: base.Add(-time.Hour), Level: "info", Message: "unrelated", Context: map[string]interface{}{"trace_id": "t-9"}},
+	}})
+
+	require.NoError(t, node.ProcessLog(hephaestus.LogEntry{Timestamp: base.Add(-2 * time.Second), Level: "debug", Message: "cache lookup", Context: map[string]interface{}{"trace_id": "t-1"}}))
+	require.NoError(t, node.ProcessLog(hephaestus.LogEntry{Timestamp: base.Add(-time.Second), Level: "info", Message: "other request", Context: map[string]interface{}{"trace_id": "t-2"}}))
+	require.NoError(t, node.ProcessLog(hephaestus.LogEntry{Timestamp: base, Level: "error", Message: "query failed", Context: map[string]interface{}{"trace_id": "t-1"}}))
+
+	solution := <-node.GetSolutions()
+	require.NotNil(t, solution.Evidence)
+
+	// The before window only kept the previous entry, correlation still reaches the older one
+	require.Len(t, solution.Evidence.Before, 1)
+	assert.Equal(t, "other request", solution.Evidence.Before[0].Message)
+	var messages []string
+	for _, entry := range solution.Evidence.Correlated {
+		messages = append(messages, entry.Message)
+	}
+	assert.Equal(t, []string{"request received", "cache lookup"}, messages)
+}
+
+func TestNode_LogStoreFailureIsReported(t *testing.T) {
+	node := newTestNode(t)
+	node.SetLogStore(&fakeLogStore{err: errors.New("store offline")})
+
+	require.NoError(t, node.ProcessLog(hephaestus.LogEntry{Level: "error", Message: "query failed", Context: map[string]interface{}{"request_id": "r-1"}}))
+
+	assert.ErrorContains(t, <-node.GetErrors(), "store offline")
+	solution := <-node.GetSolutions()
+	assert.Empty(t, solution.Evidence.Correlated)
+}
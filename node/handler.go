@@ -0,0 +1,73 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/HoyeonS/hephaestus/pkg/hephaestus"
+)
+
+// namedSolutionHandler is a solution handler registered on a node
+type namedSolutionHandler struct {
+	name    string
+	handler hephaestus.SolutionHandler
+}
+
+// MultiSolutionHandler runs several handlers in order.
+// Every handler runs even if an earlier one fails or panics; the failures are joined.
+type MultiSolutionHandler []hephaestus.SolutionHandler
+
+// HandleSolution passes the solution to every handler
+func (m MultiSolutionHandler) HandleSolution(ctx context.Context, solution *hephaestus.Solution) error {
+	var errs []error
+	for i, handler := range m {
+		if err := callSolutionHandler(ctx, handler, solution); err != nil {
+			errs = append(errs, fmt.Errorf("handler %d: %w", i, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// RegisterSolutionHandler registers a handler invoked for every generated solution.
+// Handlers run in registration order and a failing handler does not affect the others.
+func (n *Node) RegisterSolutionHandler(name string, handler hephaestus.SolutionHandler) error {
+	if handler == nil {
+		return fmt.Errorf("%w: solution handler cannot be nil", hephaestus.ErrInvalidArgument)
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	for _, registered := range n.handlers {
+		if registered.name == name {
+			return fmt.Errorf("solution handler %q: %w", name, hephaestus.ErrAlreadyExists)
+		}
+	}
+	n.handlers = append(n.handlers, namedSolutionHandler{name: name, handler: handler})
+	return nil
+}
+
+// dispatchSolution passes the solution to every registered handler and reports failures on the error channel
+func (n *Node) dispatchSolution(ctx context.Context, solution *hephaestus.Solution) {
+	n.mu.Lock()
+	handlers := make([]namedSolutionHandler, len(n.handlers))
+	copy(handlers, n.handlers)
+	n.mu.Unlock()
+
+	for _, registered := range handlers {
+		if err := callSolutionHandler(ctx, registered.handler, solution); err != nil {
+			n.errorOutbox.send(fmt.Errorf("solution handler %q failed for %s: %w", registered.name, solution.ID, err))
+		}
+	}
+}
+
+// callSolutionHandler invokes a handler and converts a panic into an error
+func callSolutionHandler(ctx context.Context, handler hephaestus.SolutionHandler, solution *hephaestus.Solution) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("handler panicked: %v", r)
+		}
+	}()
+	return handler.HandleSolution(ctx, solution)
+}
@@ -0,0 +1,80 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/HoyeonS/hephaestus/pkg/hephaestus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultiSolutionHandler(t *testing.T) {
+	var calls int32
+	counting := hephaestus.SolutionHandlerFunc(func(ctx context.Context, solution *hephaestus.Solution) error {
+		atomic.AddInt32(&calls, 1)
+		return nil
+	})
+	failing := hephaestus.SolutionHandlerFunc(func(ctx context.Context, solution *hephaestus.Solution) error {
+		return errors.New("store unavailable")
+	})
+	panicking := hephaestus.SolutionHandlerFunc(func(ctx context.Context, solution *hephaestus.Solution) error {
+		panic("boom")
+	})
+
+	handler := MultiSolutionHandler{counting, failing, panicking, counting}
+	err := handler.HandleSolution(context.Background(), &hephaestus.Solution{ID: "sol-1"})
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "store unavailable")
+	assert.Contains(t, err.Error(), "boom")
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestNode_RegisterSolutionHandler(t *testing.T) {
+	node := newTestNode(t)
+	noop := hephaestus.SolutionHandlerFunc(func(ctx context.Context, solution *hephaestus.Solution) error { return nil })
+
+	assert.NoError(t, node.RegisterSolutionHandler("noop", noop))
+	assert.ErrorIs(t, node.RegisterSolutionHandler("noop", noop), hephaestus.ErrAlreadyExists)
+	assert.ErrorIs(t, node.RegisterSolutionHandler("nil", nil), hephaestus.ErrInvalidArgument)
+}
+
+func TestNode_SolutionHandlersAreIsolated(t *testing.T) {
+	node := newTestNode(t)
+
+	var handled atomic.Value
+	require.NoError(t, node.RegisterSolutionHandler("panics", hephaestus.SolutionHandlerFunc(
+		func(ctx context.Context, solution *hephaestus.Solution) error { panic("boom") })))
+	require.NoError(t, node.RegisterSolutionHandler("fails", hephaestus.SolutionHandlerFunc(
+		func(ctx context.Context, solution *hephaestus.Solution) error { return errors.New("failed") })))
+	require.NoError(t, node.RegisterSolutionHandler("records", hephaestus.SolutionHandlerFunc(
+		func(ctx context.Context, solution *hephaestus.Solution) error {
+			handled.Store(solution.ID)
+			return nil
+		})))
+
+	require.NoError(t, node.ProcessLog(hephaestus.LogEntry{Level: "error", Message: "boom"}))
+
+	select {
+	case solution := <-node.GetSolutions():
+		assert.Equal(t, solution.ID, handled.Load())
+	case <-time.After(time.Second):
+		t.Fatal("expected a solution")
+	}
+
+	var errs []error
+	for i := 0; i < 2; i++ {
+		select {
+		case err := <-node.GetErrors():
+			errs = append(errs, err)
+		case <-time.After(time.Second):
+			t.Fatal("expected a handler error")
+		}
+	}
+	assert.Contains(t, errs[0].Error(), `"panics"`)
+	assert.Contains(t, errs[1].Error(), `"fails"`)
+}
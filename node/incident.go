@@ -0,0 +1,95 @@
+package node
+
+import (
+	"time"
+
+	"github.com/HoyeonS/hephaestus/pkg/hephaestus"
+)
+
+// incident groups the matching entries handled by one solution flow
+type incident struct {
+	fingerprint string
+	flowID      string
+	startedAt   time.Time
+	finishedAt  time.Time
+	occurrences int
+}
+
+// active reports whether matching entries should still attach to the incident
+func (i *incident) active(now time.Time, cooldown time.Duration) bool {
+	if i.finishedAt.IsZero() {
+		return true
+	}
+	return now.Before(i.finishedAt.Add(cooldown))
+}
+
+// incidentTracker debounces solution flows per fingerprint.
+// While a flow is in flight, and for the cooldown period after it finishes, matching
+// entries with the same fingerprint attach to its incident instead of starting a new flow.
+type incidentTracker struct {
+	cooldown      time.Duration
+	byFingerprint map[string]*incident
+	byFlow        map[string]*incident
+}
+
+// newIncidentTracker creates an incident tracker from the log processing settings
+func newIncidentTracker(config hephaestus.LogProcessingConfiguration) *incidentTracker {
+	return &incidentTracker{
+		cooldown:      config.CooldownPeriod,
+		byFingerprint: make(map[string]*incident),
+		byFlow:        make(map[string]*incident),
+	}
+}
+
+// attach records an occurrence on the active incident for the fingerprint and reports
+// whether one existed
+func (t *incidentTracker) attach(fingerprint string, now time.Time) bool {
+	t.expire(now)
+
+	current, ok := t.byFingerprint[fingerprint]
+	if !ok {
+		return false
+	}
+	current.occurrences++
+	return true
+}
+
+// start opens an incident for a new solution flow
+func (t *incidentTracker) start(flowID, fingerprint string, now time.Time) {
+	current := &incident{
+		fingerprint: fingerprint,
+		flowID:      flowID,
+		startedAt:   now,
+		occurrences: 1,
+	}
+	t.byFingerprint[fingerprint] = current
+	t.byFlow[flowID] = current
+}
+
+// lookup returns the fingerprint and attached entry count of the incident of a flow
+func (t *incidentTracker) lookup(flowID string) (string, int) {
+	if current, ok := t.byFlow[flowID]; ok {
+		return current.fingerprint, current.occurrences
+	}
+	return "", 0
+}
+
+// finish marks the incident of a flow as finished, starting its cooldown
+func (t *incidentTracker) finish(flowID string, now time.Time) {
+	current, ok := t.byFlow[flowID]
+	if !ok {
+		return
+	}
+	current.finishedAt = now
+	delete(t.byFlow, flowID)
+	t.expire(now)
+}
+
+// expire forgets finished incidents whose cooldown has passed
+func (t *incidentTracker) expire(now time.Time) {
+	for fingerprint, current := range t.byFingerprint {
+		if !current.active(now, t.cooldown) {
+			delete(t.byFingerprint, fingerprint)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package node
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/HoyeonS/hephaestus/pkg/hephaestus"
+	"github.com/HoyeonS/hephaestus/pkg/hephaestus/fingerprint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIncidentTracker(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tracker := newIncidentTracker(hephaestus.LogProcessingConfiguration{CooldownPeriod: time.Minute})
+	assert.False(t, tracker.attach("a", base))
+
+	tracker.start("flow-1", "a", base)
+	assert.True(t, tracker.attach("a", base.Add(time.Second)), "in-flight incident")
+	assert.False(t, tracker.attach("b", base.Add(time.Second)), "other fingerprint")
+	key, occurrences := tracker.lookup("flow-1")
+	assert.Equal(t, "a", key)
+	assert.Equal(t, 2, occurrences)
+
+	tracker.finish("flow-1", base.Add(10*time.Second))
+	assert.True(t, tracker.attach("a", base.Add(30*time.Second)), "cooling down")
+	assert.False(t, tracker.attach("a", base.Add(71*time.Second)), "cooldown passed")
+	assert.Empty(t, tracker.byFingerprint)
+}
+
+func TestIncidentTracker_NoCooldown(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tracker := newIncidentTracker(hephaestus.LogProcessingConfiguration{})
+	tracker.start("flow-1", "a", base)
+	assert.True(t, tracker.attach("a", base))
+
+	tracker.finish("flow-1", base)
+	assert.False(t, tracker.attach("a", base))
+}
+
+func TestNode_RepeatedTriggersAttachToIncident(t *testing.T) {
+	node := newTestNode(t, withLogProcessing(hephaestus.LogProcessingConfiguration{CooldownPeriod: time.Hour}))
+
+	// Hold the first flow in its log store lookup so the storm arrives while it is in flight
+	store := &blockingLogStore{started: make(chan struct{}, 1), release: make(chan struct{})}
+	node.SetLogStore(store)
+	traceContext := map[string]interface{}{"trace_id": "t-1"}
+	for i := 0; i < 50; i++ {
+		require.NoError(t, node.ProcessLog(hephaestus.LogEntry{Level: "error", Message: "connection refused", Context: traceContext}))
+	}
+	<-store.started
+	close(store.release)
+
+	solution := <-node.GetSolutions()
+	assert.Equal(t, 50, solution.Occurrences)
+	assert.Equal(t, fingerprint.Compute(hephaestus.LogEntry{Message: "connection refused"}), solution.Fingerprint)
+
+	// The cooldown keeps attaching after the flow finished
+	require.NoError(t, node.ProcessLog(hephaestus.LogEntry{Level: "error", Message: "connection refused"}))
+	require.NoError(t, node.Stop(context.Background()))
+	for range node.GetSolutions() {
+		t.Fatal("repeated trigger started a new flow")
+	}
+}
+
+func TestNode_MaxConcurrentFlows(t *testing.T) {
+	node := newTestNode(t, withLogProcessing(hephaestus.LogProcessingConfiguration{MaxConcurrentFlows: 1}))
+
+	for i := 0; i < cap(node.solutionOutbox.ch); i++ {
+		node.solutionOutbox.ch <- &hephaestus.Solution{}
+	}
+	for i := 0; i < 3; i++ {
+		require.NoError(t, node.ProcessLog(hephaestus.LogEntry{Level: "error", Message: "failure " + distinctWord(i)}))
+	}
+
+	// Deferred entries stay buffered until the running flow finishes
+	assert.Equal(t, 2, node.BufferStats().Entries)
+
+	for i := 0; i < cap(node.solutionOutbox.ch); i++ {
+		<-node.solutionOutbox.ch
+	}
+	first := <-node.GetSolutions()
+	deferred := <-node.GetSolutions()
+	assert.NotEqual(t, first.ID, deferred.ID)
+	assert.Equal(t, 0, node.BufferStats().Entries)
+
+	require.NoError(t, node.Stop(context.Background()))
+	for range node.GetSolutions() {
+		t.Fatal("deferred trigger started more than one flow")
+	}
+}
+
+func TestNode_DeferredTriggerKeepsLaterEntriesBuffered(t *testing.T) {
+	node := newTestNode(t, withLogProcessing(hephaestus.LogProcessingConfiguration{MaxConcurrentFlows: 1}))
+
+	node.mu.Lock()
+	node.activeFlows = 1
+	node.mu.Unlock()
+	require.NoError(t, node.ProcessLog(hephaestus.LogEntry{Level: "error", Message: "deferred failure"}))
+	require.NoError(t, node.ProcessLog(hephaestus.LogEntry{Level: "info", Message: "later"}))
+
+	node.mu.Lock()
+	node.activeFlows = 0
+	require.NoError(t, node.triggerLogProcessing())
+	node.mu.Unlock()
+
+	solution := <-node.GetSolutions()
+	require.NotNil(t, solution.Evidence)
+	assert.Equal(t, "deferred failure", solution.Evidence.Trigger.Message)
+	assert.Equal(t, 1, node.BufferStats().Entries)
+	require.NoError(t, node.Stop(context.Background()))
+}
@@ -0,0 +1,128 @@
+package node
+
+import (
+	"context"
+	"math"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/HoyeonS/hephaestus/pkg/hephaestus"
+	"go.uber.org/zap"
+)
+
+// Reasons an entry is dropped by the ingestion policy, reported to the metrics collector
+const (
+	dropReasonSampled     = "sampled"
+	dropReasonRateLimited = "rate_limited"
+)
+
+// ingestionPolicy samples and rate limits entries before they enter the node.
+// It has its own lock so entries are dropped before any further work is done on them.
+type ingestionPolicy struct {
+	mu     sync.Mutex
+	keep   hephaestus.Severity
+	rates  map[hephaestus.Severity]float64
+	bucket *tokenBucket
+	random func() float64
+}
+
+// newIngestionPolicy creates an ingestion policy from the node settings
+func newIngestionPolicy(config hephaestus.ClientNodeConfiguration) *ingestionPolicy {
+	ingestion := config.IngestionConfiguration
+
+	// Levels are validated with the configuration, unknown values keep the defaults
+	keep := hephaestus.DefaultThresholdSeverity
+	level := ingestion.AlwaysKeepLevel
+	if level == "" {
+		level = config.LogProcessingConfiguration.ThresholdLevel
+	}
+	if parsed, err := hephaestus.ParseSeverity(level); err == nil {
+		keep = parsed
+	}
+
+	p := &ingestionPolicy{
+		keep:   keep,
+		rates:  make(map[hephaestus.Severity]float64, len(ingestion.SampleRates)),
+		random: rand.Float64,
+	}
+	for level, rate := range ingestion.SampleRates {
+		if severity, err := hephaestus.ParseSeverity(level); err == nil {
+			p.rates[severity] = rate
+		}
+	}
+	if ingestion.RateLimit > 0 {
+		burst := float64(ingestion.RateBurst)
+		if burst == 0 {
+			burst = math.Max(1, math.Ceil(ingestion.RateLimit))
+		}
+		p.bucket = newTokenBucket(ingestion.RateLimit, burst, time.Now())
+	}
+	return p
+}
+
+// admit decides whether an entry received at now is kept. It returns the drop reason
+// and the normalized level of a dropped entry, or an empty reason when it is kept.
+func (p *ingestionPolicy) admit(entry hephaestus.LogEntry, now time.Time) (reason string, level string) {
+	if len(p.rates) == 0 && p.bucket == nil {
+		return "", ""
+	}
+
+	severity, _ := hephaestus.ParseSeverity(entry.Level)
+	if severity.AtLeast(p.keep) {
+		return "", ""
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// Sampled out entries do not spend rate limit tokens
+	if rate, ok := p.rates[severity]; ok && p.random() >= rate {
+		return dropReasonSampled, severity.String()
+	}
+	if p.bucket != nil && !p.bucket.take(now) {
+		return dropReasonRateLimited, severity.String()
+	}
+	return "", ""
+}
+
+// tokenBucket is a token bucket rate limiter refilled continuously
+type tokenBucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// newTokenBucket creates a full bucket refilled with rate tokens per second up to burst
+func newTokenBucket(rate, burst float64, now time.Time) *tokenBucket {
+	return &tokenBucket{rate: rate, burst: burst, tokens: burst, last: now}
+}
+
+// take spends a token if one is available at now
+func (b *tokenBucket) take(now time.Time) bool {
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = math.Min(b.burst, b.tokens+elapsed.Seconds()*b.rate)
+		b.last = now
+	}
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// recordIngestionDrop reports an entry dropped by the ingestion policy
+func (n *Node) recordIngestionDrop(level, reason string) {
+	n.mu.Lock()
+	collector := n.metrics
+	logger := n.logger
+	n.mu.Unlock()
+
+	if collector == nil {
+		return
+	}
+	if err := collector.RecordIngestionDrop(context.Background(), n.clientNodeConfig.NodeID, level, reason); err != nil {
+		logger.Debug("Failed to record ingestion drop", zap.Error(err))
+	}
+}
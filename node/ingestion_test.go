@@ -0,0 +1,116 @@
+package node
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/HoyeonS/hephaestus/pkg/hephaestus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenBucket(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	bucket := newTokenBucket(2, 3, base)
+
+	for i := 0; i < 3; i++ {
+		assert.True(t, bucket.take(base), "burst token %d", i)
+	}
+	assert.False(t, bucket.take(base), "burst spent")
+	assert.False(t, bucket.take(base.Add(400*time.Millisecond)), "0.8 tokens refilled")
+	assert.True(t, bucket.take(base.Add(500*time.Millisecond)), "one token refilled")
+	assert.True(t, bucket.take(base.Add(10*time.Second)))
+	assert.True(t, bucket.take(base.Add(10*time.Second)))
+	assert.True(t, bucket.take(base.Add(10*time.Second)))
+	assert.False(t, bucket.take(base.Add(10*time.Second)), "refill is capped at the burst")
+}
+
+func TestIngestionPolicy_Admit(t *testing.T) {
+	config := hephaestus.ClientNodeConfiguration{
+		LogProcessingConfiguration: hephaestus.LogProcessingConfiguration{ThresholdLevel: "warn"},
+		IngestionConfiguration: hephaestus.IngestionConfiguration{
+			SampleRates: map[string]float64{"INFO": 0.25, "debug": 0, "warn": 0},
+			RateLimit:   1,
+			RateBurst:   2,
+		},
+	}
+	policy := newIngestionPolicy(config)
+	draws := []float64{0.1, 0.5, 0.2, 0.2}
+	policy.random = func() float64 {
+		draw := draws[0]
+		draws = draws[1:]
+		return draw
+	}
+	now := time.Now()
+
+	tests := []struct {
+		level      string
+		wantReason string
+	}{
+		{level: "info", wantReason: ""},
+		{level: "information", wantReason: dropReasonSampled},
+		{level: "debug", wantReason: dropReasonSampled},
+		{level: "trace", wantReason: ""},
+		{level: "info", wantReason: dropReasonRateLimited},
+		// Threshold level entries bypass sampling and the rate limit
+		{level: "warn", wantReason: ""},
+		{level: "error", wantReason: ""},
+	}
+	for _, tt := range tests {
+		reason, level := policy.admit(hephaestus.LogEntry{Level: tt.level}, now)
+		assert.Equal(t, tt.wantReason, reason, tt.level)
+		if reason != "" {
+			parsed, _ := hephaestus.ParseSeverity(tt.level)
+			assert.Equal(t, parsed.String(), level)
+		}
+	}
+}
+
+func TestIngestionPolicy_Unconfigured(t *testing.T) {
+	policy := newIngestionPolicy(hephaestus.ClientNodeConfiguration{})
+	for i := 0; i < 100; i++ {
+		reason, _ := policy.admit(hephaestus.LogEntry{Level: "debug"}, time.Now())
+		assert.Empty(t, reason)
+	}
+}
+
+func TestNode_IngestionDropsAreRecorded(t *testing.T) {
+	node := newTestNode(t, withIngestion(hephaestus.IngestionConfiguration{
+		SampleRates: map[string]float64{"debug": 0},
+		RateLimit:   0.001,
+		RateBurst:   1,
+	}))
+	collector := &fakeMetricsCollector{}
+	node.SetMetricsCollector(collector)
+
+	require.NoError(t, node.ProcessLog(hephaestus.LogEntry{Level: "debug", Message: "cache miss"}))
+	require.NoError(t, node.ProcessLog(hephaestus.LogEntry{Level: "info", Message: "request handled"}))
+	result, err := node.ProcessLogs([]hephaestus.LogEntry{
+		{Level: "info", Message: "request handled"},
+		{Level: "warn", Message: "slow query"},
+	})
+	require.NoError(t, err)
+	// Dropped entries are not counted as accepted
+	assert.Equal(t, hephaestus.BatchResult{Total: 2, Dropped: 2}, result)
+	require.NoError(t, node.ProcessLog(hephaestus.LogEntry{Level: "error", Message: "query failed"}))
+	require.NoError(t, node.Stop(context.Background()))
+
+	assert.Equal(t, []string{"test-node:debug:sampled", "test-node:info:rate_limited", "test-node:warn:rate_limited"}, collector.ingestion)
+
+	solution := <-node.GetSolutions()
+	require.NotNil(t, solution)
+	assert.Equal(t, "query failed", solution.LogEntry.Message)
+	require.Len(t, solution.Evidence.Before, 1)
+	assert.Equal(t, "request handled", solution.Evidence.Before[0].Message)
+}
+
+func TestNode_StoppedNodeRecordsNoIngestionDrops(t *testing.T) {
+	node := newTestNode(t, withIngestion(hephaestus.IngestionConfiguration{SampleRates: map[string]float64{"debug": 0}}))
+	collector := &fakeMetricsCollector{}
+	node.SetMetricsCollector(collector)
+	require.NoError(t, node.Stop(context.Background()))
+
+	assert.ErrorIs(t, node.ProcessLog(hephaestus.LogEntry{Level: "debug", Message: "cache miss"}), hephaestus.ErrUnavailable)
+	assert.Empty(t, collector.ingestion)
+}
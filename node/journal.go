@@ -0,0 +1,168 @@
+package node
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/HoyeonS/hephaestus/buffer"
+	"github.com/HoyeonS/hephaestus/pkg/hephaestus"
+	"github.com/HoyeonS/hephaestus/wal"
+	"go.uber.org/zap"
+)
+
+// journal record types
+const (
+	journalEntry        = "entry"
+	journalFlowStarted  = "flow_started"
+	journalFlowFinished = "flow_finished"
+)
+
+// journalRecord is a node state change persisted in the write-ahead log
+type journalRecord struct {
+	Type    string                `json:"type"`
+	Entry   *hephaestus.LogEntry  `json:"entry,omitempty"`
+	FlowID  string                `json:"flow_id,omitempty"`
+	Entries []hephaestus.LogEntry `json:"entries,omitempty"`
+	// Buffered are the entries left in the buffer when a flow started
+	Buffered []hephaestus.LogEntry `json:"buffered,omitempty"`
+}
+
+// journalState is the node state rebuilt from the write-ahead log
+type journalState struct {
+	// buffer holds the replayed entries within the node buffer limits
+	buffer  *buffer.RingBuffer
+	flows   map[string][]hephaestus.LogEntry
+	flowIDs []string
+}
+
+// openJournal opens the write-ahead log and rebuilds the buffered entries and
+// unfinished flows it describes. Callers must hold n.mu.
+func (n *Node) openJournal() (*journalState, error) {
+	log, err := wal.Open(n.clientNodeConfig.WriteAheadLogConfiguration)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open write-ahead log: %v", err)
+	}
+
+	limits := n.systemConfig.LimitConfiguration
+	state := &journalState{
+		buffer: buffer.NewRingBuffer(limits.LogChunkLimit, limits.LogChunkByteLimit),
+		flows:  make(map[string][]hephaestus.LogEntry),
+	}
+	err = log.Replay(func(data []byte) error {
+		var record journalRecord
+		if err := json.Unmarshal(data, &record); err != nil {
+			return fmt.Errorf("failed to decode journal record: %v", err)
+		}
+		state.apply(record)
+		return nil
+	})
+	if err != nil {
+		log.Close()
+		return nil, fmt.Errorf("failed to replay write-ahead log: %v", err)
+	}
+
+	n.wal = log
+	return state, nil
+}
+
+// apply folds a journal record into the state
+func (s *journalState) apply(record journalRecord) {
+	switch record.Type {
+	case journalEntry:
+		if record.Entry != nil {
+			s.buffer.Push(*record.Entry)
+		}
+	case journalFlowStarted:
+		// A flow drains the buffer when it starts, entries after the trigger stay
+		s.buffer.Reset()
+		for _, entry := range record.Buffered {
+			s.buffer.Push(entry)
+		}
+		if _, exists := s.flows[record.FlowID]; !exists {
+			s.flowIDs = append(s.flowIDs, record.FlowID)
+		}
+		s.flows[record.FlowID] = record.Entries
+	case journalFlowFinished:
+		delete(s.flows, record.FlowID)
+	}
+}
+
+// journal appends a record to the write-ahead log when it is enabled. A failed append
+// degrades the node until the next one succeeds. Callers must hold n.mu.
+func (n *Node) journal(record journalRecord) error {
+	if n.wal == nil {
+		return nil
+	}
+
+	err := n.appendJournal(record)
+	if err != nil {
+		n.degrade("write-ahead log append failed: " + err.Error())
+	} else {
+		n.clearDegraded()
+	}
+	return err
+}
+
+// appendJournal appends a record and compacts the journal once it grows too large.
+// Callers must hold n.mu.
+func (n *Node) appendJournal(record journalRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode journal record: %v", err)
+	}
+	if err := n.wal.Append(data); err != nil {
+		return err
+	}
+
+	if n.wal.NeedsCompaction() {
+		return n.compactJournal()
+	}
+	return nil
+}
+
+// compactJournal checkpoints the buffered entries and pending flows. Callers must hold n.mu.
+func (n *Node) compactJournal() error {
+	var records [][]byte
+	for _, id := range n.pendingFlowIDs() {
+		data, err := json.Marshal(journalRecord{Type: journalFlowStarted, FlowID: id, Entries: n.pendingFlows[id]})
+		if err != nil {
+			return fmt.Errorf("failed to encode journal record: %v", err)
+		}
+		records = append(records, data)
+	}
+	for _, entry := range n.logBuffer.Snapshot() {
+		data, err := json.Marshal(journalRecord{Type: journalEntry, Entry: &entry})
+		if err != nil {
+			return fmt.Errorf("failed to encode journal record: %v", err)
+		}
+		records = append(records, data)
+	}
+
+	return n.wal.Checkpoint(records)
+}
+
+// pendingFlowIDs returns the pending flow IDs in start order. Callers must hold n.mu.
+func (n *Node) pendingFlowIDs() []string {
+	ids := make([]string, 0, len(n.pendingFlows))
+	for _, id := range n.pendingOrder {
+		if _, pending := n.pendingFlows[id]; pending {
+			ids = append(ids, id)
+		}
+	}
+	n.pendingOrder = ids
+	return ids
+}
+
+// closeJournal closes the write-ahead log once no flow can write to it
+func (n *Node) closeJournal() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.wal == nil {
+		return
+	}
+	if err := n.wal.Close(); err != nil {
+		n.logger.Warn("Failed to close write-ahead log", zap.Error(err))
+	}
+	n.wal = nil
+}
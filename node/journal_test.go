@@ -0,0 +1,130 @@
+package node
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/HoyeonS/hephaestus/pkg/hephaestus"
+	"github.com/HoyeonS/hephaestus/wal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNode_JournalRestoresBuffer(t *testing.T) {
+	dir := t.TempDir()
+
+	node := newTestNode(t, withJournal(dir))
+	require.NoError(t, node.Start(context.Background()))
+	require.NoError(t, node.ProcessLog(hephaestus.LogEntry{Level: "info", Message: "first"}))
+	require.NoError(t, node.ProcessLog(hephaestus.LogEntry{Level: "warn", Message: "second"}))
+	require.NoError(t, node.Stop(context.Background()))
+
+	restarted := newTestNode(t, withJournal(dir))
+	require.NoError(t, restarted.ProcessLog(hephaestus.LogEntry{Level: "info", Message: "before start"}))
+	require.NoError(t, restarted.Start(context.Background()))
+	defer restarted.Stop(context.Background())
+
+	var messages []string
+	for _, entry := range restarted.logBuffer.Snapshot() {
+		messages = append(messages, entry.Message)
+	}
+	assert.Equal(t, []string{"first", "second", "before start"}, messages)
+}
+
+func TestNode_JournalReplayKeepsBufferLimits(t *testing.T) {
+	dir := t.TempDir()
+
+	node := newTestNode(t, withJournal(dir))
+	require.NoError(t, node.Start(context.Background()))
+	for i := 0; i < 25; i++ {
+		require.NoError(t, node.ProcessLog(hephaestus.LogEntry{Level: "info", Message: "entry " + distinctWord(i)}))
+	}
+	require.NoError(t, node.Stop(context.Background()))
+
+	// Replay holds no more entries than the buffer keeps
+	restarted := newTestNode(t, withJournal(dir))
+	restarted.mu.Lock()
+	state, err := restarted.openJournal()
+	restarted.mu.Unlock()
+	require.NoError(t, err)
+	defer restarted.wal.Close()
+
+	entries := state.buffer.Snapshot()
+	require.Len(t, entries, 10)
+	assert.Equal(t, "entry "+distinctWord(24), entries[9].Message)
+}
+
+func TestNode_JournalReplayKeepsEntriesAfterTrigger(t *testing.T) {
+	dir := t.TempDir()
+
+	// The entry logged while the trigger was deferred stays buffered when the flow starts
+	node := newTestNode(t, withJournal(dir), withLogProcessing(hephaestus.LogProcessingConfiguration{MaxConcurrentFlows: 1}))
+	require.NoError(t, node.Start(context.Background()))
+	node.mu.Lock()
+	node.activeFlows = 1
+	node.mu.Unlock()
+	require.NoError(t, node.ProcessLog(hephaestus.LogEntry{Level: "error", Message: "deferred failure"}))
+	require.NoError(t, node.ProcessLog(hephaestus.LogEntry{Level: "info", Message: "later"}))
+
+	node.mu.Lock()
+	node.activeFlows = 0
+	require.NoError(t, node.triggerLogProcessing())
+	node.mu.Unlock()
+	<-node.GetSolutions()
+	require.NoError(t, node.Stop(context.Background()))
+
+	restarted := newTestNode(t, withJournal(dir))
+	restarted.mu.Lock()
+	state, err := restarted.openJournal()
+	restarted.mu.Unlock()
+	require.NoError(t, err)
+	defer restarted.wal.Close()
+
+	entries := state.buffer.Snapshot()
+	require.Len(t, entries, 1)
+	assert.Equal(t, "later", entries[0].Message)
+}
+
+func TestNode_JournalResumesPendingFlows(t *testing.T) {
+	dir := t.TempDir()
+
+	// Simulate a crash after a flow started but before it finished
+	log, err := wal.Open(hephaestus.WriteAheadLogConfiguration{Directory: dir})
+	require.NoError(t, err)
+	for _, record := range []journalRecord{
+		{Type: journalEntry, Entry: &hephaestus.LogEntry{Level: "info", Message: "drained"}},
+		{Type: journalFlowStarted, FlowID: "flow-1", Entries: []hephaestus.LogEntry{{Level: "error", Message: "crashed"}}},
+		{Type: journalFlowStarted, FlowID: "flow-2", Entries: []hephaestus.LogEntry{{Level: "error", Message: "finished"}}},
+		{Type: journalFlowFinished, FlowID: "flow-2"},
+		{Type: journalEntry, Entry: &hephaestus.LogEntry{Level: "info", Message: "buffered"}},
+	} {
+		data, err := json.Marshal(record)
+		require.NoError(t, err)
+		require.NoError(t, log.Append(data))
+	}
+	require.NoError(t, log.Close())
+
+	node := newTestNode(t, withJournal(dir))
+	require.NoError(t, node.Start(context.Background()))
+
+	select {
+	case solution := <-node.GetSolutions():
+		assert.Equal(t, "crashed", solution.LogEntry.Message)
+	case <-time.After(time.Second):
+		t.Fatal("pending flow was not resumed")
+	}
+
+	require.Len(t, node.logBuffer.Snapshot(), 1)
+	assert.Equal(t, "buffered", node.logBuffer.Snapshot()[0].Message)
+	require.NoError(t, node.Stop(context.Background()))
+
+	// The resumed flow is recorded as finished and is not replayed again
+	restarted := newTestNode(t, withJournal(dir))
+	require.NoError(t, restarted.Start(context.Background()))
+	require.NoError(t, restarted.Stop(context.Background()))
+	for range restarted.GetSolutions() {
+		t.Fatal("finished flow was resumed")
+	}
+}
@@ -0,0 +1,131 @@
+package node
+
+import (
+	"context"
+	"time"
+
+	"github.com/HoyeonS/hephaestus/pkg/hephaestus"
+	"go.uber.org/zap"
+)
+
+// transition moves the node to a new status and publishes the transition event.
+// Moving to the current status is a no-op. Callers must hold n.mu.
+func (n *Node) transition(to hephaestus.NodeStatus, reason string) error {
+	from := n.status
+	if from == to {
+		return nil
+	}
+	if err := hephaestus.ValidateNodeStatusTransition(from, to); err != nil {
+		return err
+	}
+	n.status = to
+
+	event := hephaestus.NodeStatusEvent{
+		NodeID:    n.clientNodeConfig.NodeID,
+		From:      from,
+		To:        to,
+		Reason:    reason,
+		Timestamp: time.Now(),
+	}
+	n.logger.Info("Node status changed",
+		zap.String("from", string(from)),
+		zap.String("to", string(to)),
+		zap.String("reason", reason))
+
+	if n.metrics != nil {
+		if err := n.metrics.RecordNodeStatusChange(context.Background(), event.NodeID, string(to)); err != nil {
+			n.logger.Debug("Failed to record node status change", zap.Error(err))
+		}
+	}
+	for _, subscriber := range n.subscribers {
+		// Subscribers that fall behind miss events rather than stall the node
+		select {
+		case subscriber <- event:
+		default:
+		}
+	}
+	return nil
+}
+
+// settle moves a running node to the status matching its current state: degraded
+// while a degradation is active, processing while flows run and operational otherwise.
+// A node in error because a flow panicked recovers through operational, any other
+// error is final. Callers must hold n.mu.
+func (n *Node) settle(reason string) {
+	switch n.status {
+	case hephaestus.NodeStatusOperational, hephaestus.NodeStatusProcessing, hephaestus.NodeStatusDegraded:
+	case hephaestus.NodeStatusError:
+		if !n.flowPanicked || n.stopped {
+			return
+		}
+		n.flowPanicked = false
+		if err := n.transition(hephaestus.NodeStatusOperational, reason); err != nil {
+			n.logger.Warn("Rejected node status transition", zap.Error(err))
+			return
+		}
+	default:
+		return
+	}
+
+	to := hephaestus.NodeStatusOperational
+	switch {
+	case n.degraded != "":
+		to = hephaestus.NodeStatusDegraded
+		reason = n.degraded
+	case n.activeFlows > 0:
+		to = hephaestus.NodeStatusProcessing
+	}
+	if err := n.transition(to, reason); err != nil {
+		n.logger.Warn("Rejected node status transition", zap.Error(err))
+	}
+}
+
+// degrade marks the node degraded for reason until clearDegraded is called. Callers must hold n.mu.
+func (n *Node) degrade(reason string) {
+	n.degraded = reason
+	n.settle(reason)
+}
+
+// clearDegraded clears a degradation set by degrade. Callers must hold n.mu.
+func (n *Node) clearDegraded() {
+	if n.degraded == "" {
+		return
+	}
+	n.degraded = ""
+	n.settle("recovered")
+}
+
+// Subscribe returns a channel receiving every node status transition and a function
+// that cancels the subscription. Events are dropped for a subscriber whose buffer is
+// full. The channel is closed once the node has stopped.
+func (n *Node) Subscribe(buffer int) (<-chan hephaestus.NodeStatusEvent, func()) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	events := make(chan hephaestus.NodeStatusEvent, buffer)
+	if n.status == hephaestus.NodeStatusStopped {
+		close(events)
+		return events, func() {}
+	}
+
+	n.subscriberSeq++
+	id := n.subscriberSeq
+	n.subscribers[id] = events
+
+	return events, func() {
+		n.mu.Lock()
+		defer n.mu.Unlock()
+		if subscriber, ok := n.subscribers[id]; ok {
+			delete(n.subscribers, id)
+			close(subscriber)
+		}
+	}
+}
+
+// closeSubscribers closes every subscriber channel. Callers must hold n.mu.
+func (n *Node) closeSubscribers() {
+	for id, subscriber := range n.subscribers {
+		delete(n.subscribers, id)
+		close(subscriber)
+	}
+}
@@ -0,0 +1,115 @@
+package node
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/HoyeonS/hephaestus/pkg/hephaestus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func collectEvents(t *testing.T, events <-chan hephaestus.NodeStatusEvent) []hephaestus.NodeStatusEvent {
+	t.Helper()
+	var collected []hephaestus.NodeStatusEvent
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return collected
+			}
+			collected = append(collected, event)
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for status events, got %v", collected)
+		}
+	}
+}
+
+func TestNode_LifecycleEvents(t *testing.T) {
+	node := newTestNode(t)
+	collector := &fakeMetricsCollector{}
+	node.SetMetricsCollector(collector)
+	events, _ := node.Subscribe(16)
+
+	require.NoError(t, node.Start(context.Background()))
+	assert.Equal(t, hephaestus.NodeStatusOperational, node.Status())
+	require.NoError(t, node.ProcessLog(hephaestus.LogEntry{Level: "error", Message: "boom"}))
+	require.NoError(t, node.Stop(context.Background()))
+	assert.Equal(t, hephaestus.NodeStatusStopped, node.Status())
+
+	collected := collectEvents(t, events)
+	require.GreaterOrEqual(t, len(collected), 4)
+
+	var statuses []string
+	for i, event := range collected {
+		assert.Equal(t, "test-node", event.NodeID)
+		assert.NotEmpty(t, event.Reason)
+		assert.False(t, event.Timestamp.IsZero())
+		assert.NoError(t, hephaestus.ValidateNodeStatusTransition(event.From, event.To))
+		if i > 0 {
+			assert.Equal(t, collected[i-1].To, event.From)
+		}
+		statuses = append(statuses, "test-node:"+string(event.To))
+	}
+	assert.Equal(t, hephaestus.NodeStatusInitializing, collected[0].From)
+	assert.Equal(t, hephaestus.NodeStatusOperational, collected[0].To)
+	assert.Equal(t, hephaestus.NodeStatusProcessing, collected[1].To)
+	assert.Equal(t, hephaestus.NodeStatusDraining, collected[len(collected)-2].To)
+	assert.Equal(t, hephaestus.NodeStatusStopped, collected[len(collected)-1].To)
+
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+	assert.Equal(t, statuses, collector.statuses)
+}
+
+func TestNode_StopWithoutStart(t *testing.T) {
+	node := newTestNode(t)
+	events, _ := node.Subscribe(4)
+
+	require.NoError(t, node.Stop(context.Background()))
+
+	collected := collectEvents(t, events)
+	require.Len(t, collected, 2)
+	assert.Equal(t, hephaestus.NodeStatusDraining, collected[0].To)
+	assert.Equal(t, hephaestus.NodeStatusStopped, collected[1].To)
+
+	// Subscribing after the node stopped yields a closed channel
+	late, _ := node.Subscribe(1)
+	_, open := <-late
+	assert.False(t, open)
+}
+
+func TestNode_Degraded(t *testing.T) {
+	node := newTestNode(t)
+	require.NoError(t, node.Start(context.Background()))
+	events, unsubscribe := node.Subscribe(4)
+
+	node.mu.Lock()
+	node.degrade("write-ahead log append failed")
+	node.mu.Unlock()
+	assert.Equal(t, hephaestus.NodeStatusDegraded, node.Status())
+
+	node.mu.Lock()
+	node.clearDegraded()
+	node.mu.Unlock()
+	assert.Equal(t, hephaestus.NodeStatusOperational, node.Status())
+
+	unsubscribe()
+	collected := collectEvents(t, events)
+	require.Len(t, collected, 2)
+	assert.Equal(t, "write-ahead log append failed", collected[0].Reason)
+	assert.Equal(t, hephaestus.NodeStatusDegraded, collected[0].To)
+	assert.Equal(t, hephaestus.NodeStatusOperational, collected[1].To)
+}
+
+func TestNode_TransitionRejected(t *testing.T) {
+	node := newTestNode(t)
+
+	node.mu.Lock()
+	err := node.transition(hephaestus.NodeStatusStopped, "skip draining")
+	node.mu.Unlock()
+
+	assert.ErrorIs(t, err, hephaestus.ErrInvalidTransition)
+	assert.Equal(t, hephaestus.NodeStatusInitializing, node.Status())
+}
@@ -0,0 +1,273 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/HoyeonS/hephaestus/pkg/hephaestus"
+	"go.uber.org/zap"
+)
+
+var (
+	_ hephaestus.ClientNode  = (*Node)(nil)
+	_ hephaestus.NodeManager = (*Manager)(nil)
+)
+
+// NodeSetupFunc prepares a node for its configuration before the manager starts it,
+// typically setting the remote repository and log store the node should use
+type NodeSetupFunc func(node *Node, config *hephaestus.ClientNodeConfiguration) error
+
+// managedNode is a node registered with a Manager
+type managedNode struct {
+	node         *Node
+	config       *hephaestus.ClientNodeConfiguration
+	registeredAt time.Time
+}
+
+// Manager hosts many client nodes in one process keyed by node ID.
+// Every node has its own buffer, flows, output channels and lock, and the manager never
+// holds its own lock while calling into a node, so a failing or backlogged node does not
+// hold up the others.
+type Manager struct {
+	systemConfig *hephaestus.SystemConfiguration
+
+	mu    sync.RWMutex
+	nodes map[string]*managedNode
+	// reserved holds the IDs of nodes being created and of removed nodes still closing
+	reserved map[string]struct{}
+	logger   *zap.Logger
+	metrics  hephaestus.MetricsCollectionService
+	setup    NodeSetupFunc
+}
+
+// NewManager creates a node manager sharing the system configuration across its nodes
+func NewManager(systemConfig *hephaestus.SystemConfiguration) (*Manager, error) {
+	if err := hephaestus.ValidateSystemConfiguration(systemConfig); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %v", err)
+	}
+
+	return &Manager{
+		systemConfig: systemConfig,
+		nodes:        make(map[string]*managedNode),
+		reserved:     make(map[string]struct{}),
+		logger:       newDefaultLogger(),
+	}, nil
+}
+
+// SetLogger sets the logger nodes registered afterwards log to, tagged with their node ID
+func (m *Manager) SetLogger(logger *zap.Logger) {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.logger = logger
+}
+
+// SetMetricsCollector sets the collector that tracks nodes registered afterwards
+func (m *Manager) SetMetricsCollector(collector hephaestus.MetricsCollectionService) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.metrics = collector
+}
+
+// SetNodeSetup sets the function preparing nodes registered afterwards
+func (m *Manager) SetNodeSetup(setup NodeSetupFunc) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.setup = setup
+}
+
+// RegisterNode creates a node from its configuration, starts tracking its metrics and
+// starts it. A node that fails to start is removed again. An ID stays taken until the
+// node last registered under it has closed.
+func (m *Manager) RegisterNode(ctx context.Context, config *hephaestus.ClientNodeConfiguration) (hephaestus.ClientNode, error) {
+	if config == nil || config.NodeID == "" {
+		return nil, fmt.Errorf("%w: node ID is required", hephaestus.ErrInvalidArgument)
+	}
+
+	// Reserve the ID first, a second node must not touch the spill or journal directories
+	// of the registered one
+	m.mu.Lock()
+	_, exists := m.nodes[config.NodeID]
+	_, reserved := m.reserved[config.NodeID]
+	if exists || reserved {
+		m.mu.Unlock()
+		return nil, fmt.Errorf("node %s: %w", config.NodeID, hephaestus.ErrAlreadyExists)
+	}
+	m.reserved[config.NodeID] = struct{}{}
+	m.mu.Unlock()
+
+	node, err := NewNode(m.systemConfig, config)
+
+	m.mu.Lock()
+	delete(m.reserved, config.NodeID)
+	if err != nil {
+		m.mu.Unlock()
+		return nil, err
+	}
+	managed := &managedNode{node: node, config: config, registeredAt: time.Now()}
+	m.nodes[config.NodeID] = managed
+	logger, collector, setup := m.logger, m.metrics, m.setup
+	m.mu.Unlock()
+
+	node.SetLogger(logger.With(zap.String("node_id", config.NodeID)))
+	if err := m.startNode(ctx, managed, collector, setup); err != nil {
+		m.remove(managed)
+		_ = node.Stop(context.Background())
+		return nil, err
+	}
+
+	logger.Info("Node registered", zap.String("node_id", config.NodeID), zap.String("mode", string(config.Mode)))
+	return node, nil
+}
+
+// startNode ties a freshly registered node into the metrics collector and starts it
+func (m *Manager) startNode(ctx context.Context, managed *managedNode, collector hephaestus.MetricsCollectionService, setup NodeSetupFunc) error {
+	nodeID := managed.config.NodeID
+	if collector != nil {
+		if err := collector.InitializeNodeMetrics(ctx, nodeID); err != nil {
+			return fmt.Errorf("failed to initialize metrics for node %s: %w", nodeID, err)
+		}
+		managed.node.SetMetricsCollector(collector)
+	}
+
+	if setup != nil {
+		if err := setup(managed.node, managed.config); err != nil {
+			m.cleanupMetrics(ctx, nodeID, collector)
+			return fmt.Errorf("failed to set up node %s: %w", nodeID, err)
+		}
+	}
+	if err := managed.node.Start(ctx); err != nil {
+		m.cleanupMetrics(ctx, nodeID, collector)
+		return fmt.Errorf("failed to start node %s: %w", nodeID, err)
+	}
+	return nil
+}
+
+// DeregisterNode stops a node and removes it once stopped. The node ID stays taken
+// while its in-flight flows drain. If ctx expires first the remaining flows are
+// canceled, the node is removed and the ctx error is returned; its ID stays taken
+// until the canceled flows have unwound and the node has closed.
+func (m *Manager) DeregisterNode(ctx context.Context, nodeID string) error {
+	m.mu.RLock()
+	managed, exists := m.nodes[nodeID]
+	collector := m.metrics
+	logger := m.logger
+	m.mu.RUnlock()
+	if !exists {
+		return fmt.Errorf("node %s: %w", nodeID, hephaestus.ErrNotFound)
+	}
+
+	stopErr := managed.node.Stop(ctx)
+	if m.remove(managed) {
+		m.cleanupMetrics(ctx, nodeID, collector)
+		logger.Info("Node deregistered", zap.String("node_id", nodeID))
+	}
+	return stopErr
+}
+
+// remove drops a managed node from the registry and reports whether it was registered.
+// The ID stays reserved until the node has closed, so that a new node cannot open the
+// journal and spill directories the old one is still using.
+func (m *Manager) remove(managed *managedNode) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	nodeID := managed.config.NodeID
+	if m.nodes[nodeID] != managed {
+		return false
+	}
+	delete(m.nodes, nodeID)
+
+	select {
+	case <-managed.node.closed:
+	default:
+		m.reserved[nodeID] = struct{}{}
+		go func() {
+			<-managed.node.closed
+			m.mu.Lock()
+			delete(m.reserved, nodeID)
+			m.mu.Unlock()
+		}()
+	}
+	return true
+}
+
+// cleanupMetrics stops tracking a node in the metrics collector
+func (m *Manager) cleanupMetrics(ctx context.Context, nodeID string, collector hephaestus.MetricsCollectionService) {
+	if collector == nil {
+		return
+	}
+	if err := collector.CleanupNodeMetrics(ctx, nodeID); err != nil {
+		m.mu.RLock()
+		logger := m.logger
+		m.mu.RUnlock()
+		logger.Warn("Failed to clean up node metrics", zap.String("node_id", nodeID), zap.Error(err))
+	}
+}
+
+// GetNode returns a registered node
+func (m *Manager) GetNode(nodeID string) (hephaestus.ClientNode, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	managed, exists := m.nodes[nodeID]
+	if !exists {
+		return nil, fmt.Errorf("node %s: %w", nodeID, hephaestus.ErrNotFound)
+	}
+	return managed.node, nil
+}
+
+// ListNodes returns the registered nodes ordered by node ID
+func (m *Manager) ListNodes() []hephaestus.NodeInfo {
+	m.mu.RLock()
+	managed := make([]*managedNode, 0, len(m.nodes))
+	for _, node := range m.nodes {
+		managed = append(managed, node)
+	}
+	m.mu.RUnlock()
+
+	// Status takes the node lock, query it outside the manager lock
+	infos := make([]hephaestus.NodeInfo, len(managed))
+	for i, node := range managed {
+		infos[i] = hephaestus.NodeInfo{
+			NodeID:       node.config.NodeID,
+			Mode:         node.node.mode(),
+			Status:       node.node.Status(),
+			RegisteredAt: node.registeredAt,
+		}
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].NodeID < infos[j].NodeID })
+	return infos
+}
+
+// Shutdown deregisters every node concurrently, each draining within ctx
+func (m *Manager) Shutdown(ctx context.Context) error {
+	m.mu.RLock()
+	nodeIDs := make([]string, 0, len(m.nodes))
+	for nodeID := range m.nodes {
+		nodeIDs = append(nodeIDs, nodeID)
+	}
+	m.mu.RUnlock()
+
+	errs := make([]error, len(nodeIDs))
+	var wg sync.WaitGroup
+	for i, nodeID := range nodeIDs {
+		wg.Add(1)
+		go func(i int, nodeID string) {
+			defer wg.Done()
+			if err := m.DeregisterNode(ctx, nodeID); err != nil && !errors.Is(err, hephaestus.ErrNotFound) {
+				errs[i] = fmt.Errorf("node %s: %w", nodeID, err)
+			}
+		}(i, nodeID)
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
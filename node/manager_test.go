@@ -0,0 +1,209 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/HoyeonS/hephaestus/pkg/hephaestus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newTestManager(t *testing.T) (*Manager, *fakeMetricsCollector) {
+	t.Helper()
+	manager, err := NewManager(&hephaestus.SystemConfiguration{})
+	require.NoError(t, err)
+	manager.SetLogger(zap.NewNop())
+	collector := &fakeMetricsCollector{}
+	manager.SetMetricsCollector(collector)
+	return manager, collector
+}
+
+func TestManager_RegisterLookupDeregister(t *testing.T) {
+	manager, collector := newTestManager(t)
+	ctx := context.Background()
+
+	_, err := manager.RegisterNode(ctx, &hephaestus.ClientNodeConfiguration{NodeID: "checkout"})
+	require.NoError(t, err)
+	_, err = manager.RegisterNode(ctx, &hephaestus.ClientNodeConfiguration{NodeID: "billing", Mode: hephaestus.OperationModeSuggest})
+	require.NoError(t, err)
+
+	_, err = manager.RegisterNode(ctx, &hephaestus.ClientNodeConfiguration{NodeID: "checkout"})
+	assert.ErrorIs(t, err, hephaestus.ErrAlreadyExists)
+	_, err = manager.RegisterNode(ctx, &hephaestus.ClientNodeConfiguration{})
+	assert.ErrorIs(t, err, hephaestus.ErrInvalidArgument)
+	_, err = manager.RegisterNode(ctx, &hephaestus.ClientNodeConfiguration{NodeID: "broken", Mode: "auto"})
+	assert.Error(t, err)
+
+	node, err := manager.GetNode("checkout")
+	require.NoError(t, err)
+	assert.Equal(t, hephaestus.NodeStatusOperational, node.Status())
+	_, err = manager.GetNode("missing")
+	assert.ErrorIs(t, err, hephaestus.ErrNotFound)
+
+	infos := manager.ListNodes()
+	require.Len(t, infos, 2)
+	assert.Equal(t, "billing", infos[0].NodeID)
+	assert.Equal(t, hephaestus.OperationModeSuggest, infos[0].Mode)
+	assert.Equal(t, "checkout", infos[1].NodeID)
+	assert.Equal(t, hephaestus.NodeStatusOperational, infos[1].Status)
+
+	require.NoError(t, manager.DeregisterNode(ctx, "checkout"))
+	assert.Equal(t, hephaestus.NodeStatusStopped, node.Status())
+	assert.ErrorIs(t, manager.DeregisterNode(ctx, "checkout"), hephaestus.ErrNotFound)
+	assert.Len(t, manager.ListNodes(), 1)
+
+	// The ID can be registered again once the node is gone
+	_, err = manager.RegisterNode(ctx, &hephaestus.ClientNodeConfiguration{NodeID: "checkout"})
+	require.NoError(t, err)
+	require.NoError(t, manager.Shutdown(ctx))
+	assert.Empty(t, manager.ListNodes())
+
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+	assert.Equal(t, []string{"+checkout", "+billing", "-checkout", "+checkout"}, collector.nodes[:4])
+	assert.ElementsMatch(t, []string{"-billing", "-checkout"}, collector.nodes[4:])
+	assert.Contains(t, collector.statuses, "checkout:operational")
+	assert.Contains(t, collector.statuses, "checkout:stopped")
+}
+
+func TestManager_SetupFailureRemovesNode(t *testing.T) {
+	manager, collector := newTestManager(t)
+	manager.SetNodeSetup(func(node *Node, config *hephaestus.ClientNodeConfiguration) error {
+		return errors.New("no repository for " + config.NodeID)
+	})
+
+	_, err := manager.RegisterNode(context.Background(), &hephaestus.ClientNodeConfiguration{NodeID: "checkout"})
+	assert.EqualError(t, err, "failed to set up node checkout: no repository for checkout")
+	assert.Empty(t, manager.ListNodes())
+	assert.Equal(t, []string{"+checkout", "-checkout"}, collector.nodes)
+}
+
+func TestManager_NilLoggerFallsBackToNop(t *testing.T) {
+	manager, _ := newTestManager(t)
+	manager.SetLogger(nil)
+
+	_, err := manager.RegisterNode(context.Background(), &hephaestus.ClientNodeConfiguration{NodeID: "checkout"})
+	require.NoError(t, err)
+	require.NoError(t, manager.Shutdown(context.Background()))
+}
+
+// stubbornLogStore blocks lookups until released, ignoring their context
+type stubbornLogStore struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (s *stubbornLogStore) FindCorrelated(ctx context.Context, key, value string) ([]hephaestus.LogEntry, error) {
+	s.started <- struct{}{}
+	<-s.release
+	return nil, ctx.Err()
+}
+
+func TestManager_IDStaysTakenUntilNodeHasClosed(t *testing.T) {
+	manager, _ := newTestManager(t)
+	store := &stubbornLogStore{started: make(chan struct{}, 1), release: make(chan struct{})}
+	manager.SetNodeSetup(func(node *Node, config *hephaestus.ClientNodeConfiguration) error {
+		node.SetLogStore(store)
+		return nil
+	})
+
+	ctx := context.Background()
+	node, err := manager.RegisterNode(ctx, &hephaestus.ClientNodeConfiguration{NodeID: "checkout"})
+	require.NoError(t, err)
+	require.NoError(t, node.ProcessLog(hephaestus.LogEntry{Level: "error", Message: "boom", Context: map[string]interface{}{"trace_id": "t-1"}}))
+	<-store.started
+
+	deadline, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, manager.DeregisterNode(deadline, "checkout"), context.DeadlineExceeded)
+	_, err = manager.GetNode("checkout")
+	assert.ErrorIs(t, err, hephaestus.ErrNotFound)
+
+	// The canceled flow is still unwinding, the ID is not free yet
+	_, err = manager.RegisterNode(ctx, &hephaestus.ClientNodeConfiguration{NodeID: "checkout"})
+	assert.ErrorIs(t, err, hephaestus.ErrAlreadyExists)
+
+	close(store.release)
+	<-node.(*Node).closed
+	assert.Eventually(t, func() bool {
+		_, err := manager.RegisterNode(ctx, &hephaestus.ClientNodeConfiguration{NodeID: "checkout"})
+		return err == nil
+	}, time.Second, 5*time.Millisecond)
+	require.NoError(t, manager.Shutdown(ctx))
+}
+
+// panickingLogStore panics on every lookup
+type panickingLogStore struct{}
+
+func (panickingLogStore) FindCorrelated(ctx context.Context, key, value string) ([]hephaestus.LogEntry, error) {
+	panic("store exploded")
+}
+
+func TestManager_NodesAreIsolated(t *testing.T) {
+	manager, _ := newTestManager(t)
+	stuck := &blockingLogStore{started: make(chan struct{}, 1), release: make(chan struct{})}
+	manager.SetNodeSetup(func(node *Node, config *hephaestus.ClientNodeConfiguration) error {
+		switch config.NodeID {
+		case "stuck":
+			node.SetLogStore(stuck)
+		case "failing":
+			node.SetLogStore(panickingLogStore{})
+		}
+		return nil
+	})
+
+	ctx := context.Background()
+	nodes := make(map[string]hephaestus.ClientNode)
+	for _, nodeID := range []string{"stuck", "failing", "healthy"} {
+		node, err := manager.RegisterNode(ctx, &hephaestus.ClientNodeConfiguration{NodeID: nodeID})
+		require.NoError(t, err)
+		nodes[nodeID] = node
+	}
+
+	events, unsubscribe := nodes["failing"].(*Node).Subscribe(8)
+	defer unsubscribe()
+
+	entry := hephaestus.LogEntry{Level: "error", Message: "boom", Context: map[string]interface{}{"trace_id": "t-1"}}
+	for _, node := range nodes {
+		require.NoError(t, node.ProcessLog(entry))
+	}
+	<-stuck.started
+
+	// A panicking flow puts its node in error until the flow has finished, without
+	// affecting the rest
+	select {
+	case err := <-nodes["failing"].GetErrors():
+		assert.ErrorContains(t, err, "store exploded")
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the panic to be reported")
+	}
+	select {
+	case solution := <-nodes["healthy"].GetSolutions():
+		assert.Equal(t, "boom", solution.LogEntry.Message)
+	case <-time.After(time.Second):
+		t.Fatal("healthy node blocked by its neighbours")
+	}
+	assert.Eventually(t, func() bool {
+		return nodes["failing"].Status() == hephaestus.NodeStatusOperational
+	}, time.Second, 5*time.Millisecond)
+	var statuses []hephaestus.NodeStatus
+	for len(events) > 0 {
+		statuses = append(statuses, (<-events).To)
+	}
+	assert.Equal(t, []hephaestus.NodeStatus{hephaestus.NodeStatusProcessing, hephaestus.NodeStatusError, hephaestus.NodeStatusOperational}, statuses)
+	assert.Equal(t, hephaestus.NodeStatusProcessing, nodes["stuck"].Status())
+
+	// Deregistering the stuck node gives up at the deadline and cancels its flow
+	deadline, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, manager.DeregisterNode(deadline, "stuck"), context.DeadlineExceeded)
+	_, err := manager.GetNode("stuck")
+	assert.ErrorIs(t, err, hephaestus.ErrNotFound)
+
+	require.NoError(t, manager.Shutdown(ctx))
+	assert.Equal(t, hephaestus.NodeStatusStopped, nodes["failing"].Status())
+}
@@ -2,24 +2,83 @@ package node
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/HoyeonS/hephaestus/buffer"
+	"github.com/HoyeonS/hephaestus/pattern"
 	"github.com/HoyeonS/hephaestus/pkg/hephaestus"
+	"github.com/HoyeonS/hephaestus/pkg/hephaestus/fingerprint"
+	"github.com/HoyeonS/hephaestus/pkg/hephaestus/stacktrace"
+	"github.com/HoyeonS/hephaestus/redact"
+	"github.com/HoyeonS/hephaestus/wal"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 )
 
-// Node processing hephaestus log ingestion flow
+// dominantTemplateLimit is the number of log templates handed to solution generation
+const dominantTemplateLimit = 5
+
+// Node processing hephaestus log ingestion flow.
+// All mutable state is guarded by mu so ProcessLog can be called from many goroutines.
 type Node struct {
 	systemConfig     *hephaestus.SystemConfiguration
 	clientNodeConfig *hephaestus.ClientNodeConfiguration
-	status           hephaestus.NodeStatus
+
+	mu      sync.Mutex
+	status  hephaestus.NodeStatus
+	stopped bool
+
+	// ctx is the node lifecycle context every solution flow derives from, cancel aborts
+	// the flows still running when Stop gives up waiting
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// Lifecycle
+	degraded      string
+	subscribers   map[uint64]chan hephaestus.NodeStatusEvent
+	subscriberSeq uint64
+	// flowPanicked is set while the node is in error because a solution flow panicked,
+	// the node settles again once that flow has finished
+	flowPanicked bool
+
 	// Log processing
-	logBuffer     []hephaestus.LogEntry
+	logBuffer     *buffer.RingBuffer
 	lastProcessed time.Time
+	threshold     *thresholdMonitor
+	incidents     *incidentTracker
+	patterns      *pattern.Miner
+	captures      map[string]*contextCapture
+	ingestion     *ingestionPolicy
+	redactor      *redact.Redactor
+	redactions    redactionAudit
 
 	// Solution processing
-	solutionChan chan *hephaestus.Solution
-	errorChan    chan error
+	logger           *zap.Logger
+	remoteRepository hephaestus.RemoteRepositoryService
+	logStore         hephaestus.LogStore
+	handlers         []namedSolutionHandler
+	flows            sync.WaitGroup
+	activeFlows      int
+	solutionOutbox   *outbox[*hephaestus.Solution]
+	errorOutbox      *outbox[error]
+	metrics          hephaestus.MetricsCollectionService
+	closeOnce        sync.Once
+	closed           chan struct{}
+	// deferredTrigger is set when a trigger fired at MaxConcurrentFlows, the next flow
+	// to finish starts it
+	deferredTrigger bool
+
+	// Write-ahead log
+	wal          *wal.Log
+	pendingFlows map[string][]hephaestus.LogEntry
+	pendingOrder []string
+	flowSeq      uint64
 }
 
 // NewNode creates a new Hephaestus node
@@ -31,114 +90,745 @@ func NewNode(systemConfig *hephaestus.SystemConfiguration, clientNodeConfig *hep
 		return nil, fmt.Errorf("invalid configuration: %v", err)
 	}
 
-	return &Node{
+	limits := systemConfig.LimitConfiguration
+	ctx, cancel := context.WithCancel(context.Background())
+	n := &Node{
+		ctx:              ctx,
+		cancel:           cancel,
+		systemConfig:     systemConfig,
 		clientNodeConfig: clientNodeConfig,
 		status:           hephaestus.NodeStatusInitializing,
-		logBuffer:        make([]hephaestus.LogEntry, 0),
-		solutionChan:     make(chan *hephaestus.Solution, 100),
-		errorChan:        make(chan error, 100),
+		logBuffer:        buffer.NewRingBuffer(limits.LogChunkLimit, limits.LogChunkByteLimit),
+		threshold:        newThresholdMonitor(clientNodeConfig.LogProcessingConfiguration),
+		incidents:        newIncidentTracker(clientNodeConfig.LogProcessingConfiguration),
+		ingestion:        newIngestionPolicy(*clientNodeConfig),
+		patterns:         pattern.NewMiner(pattern.Config{}),
+		captures:         make(map[string]*contextCapture),
+		subscribers:      make(map[uint64]chan hephaestus.NodeStatusEvent),
+		logger:           newDefaultLogger(),
+		closed:           make(chan struct{}),
 		lastProcessed:    time.Now(),
-	}, nil
+		pendingFlows:     make(map[string][]hephaestus.LogEntry),
+	}
+
+	if redaction := clientNodeConfig.RedactionConfiguration; redaction.Enabled {
+		redactor, err := redact.New(redaction)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		n.redactor = redactor
+	}
+
+	// Spilled items live under the node ID so nodes sharing a spill directory stay apart
+	backpressure := clientNodeConfig.BackpressureConfiguration
+	backpressure.Solution.SpillDirectory = nodeSpillDirectory(backpressure.Solution, clientNodeConfig.NodeID)
+	backpressure.Error.SpillDirectory = nodeSpillDirectory(backpressure.Error, clientNodeConfig.NodeID)
+	solutionOutbox, err := newOutbox("solution", backpressure.Solution, solutionCodec, n.recordChannelDrop, n.reportSpillError)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	errorOutbox, err := newOutbox("error", backpressure.Error, errorCodec, n.recordChannelDrop, n.reportSpillError)
+	if err != nil {
+		solutionOutbox.close()
+		cancel()
+		return nil, err
+	}
+	n.solutionOutbox = solutionOutbox
+	n.errorOutbox = errorOutbox
+
+	return n, nil
+}
+
+// newDefaultLogger writes JSON structured output to stdout
+func newDefaultLogger() *zap.Logger {
+	encoder := zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig())
+	return zap.New(zapcore.NewCore(encoder, zapcore.Lock(os.Stdout), zapcore.InfoLevel)).Named("hephaestus")
+}
+
+// mode returns the configured operation mode, defaulting to suggest
+func (n *Node) mode() hephaestus.OperationMode {
+	if n.clientNodeConfig.Mode == "" {
+		return hephaestus.OperationModeSuggest
+	}
+	return n.clientNodeConfig.Mode
 }
 
-// Start initializes and starts the node
+// Start initializes and starts the node.
+// When the write-ahead log is enabled it is replayed first: buffered entries are
+// restored and solution flows that never finished are resumed.
 func (n *Node) Start(ctx context.Context) error {
-	n.status = hephaestus.NodeStatusOperational
-	// Start log processing
-	// go n.processLogs(ctx)
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.stopped {
+		return fmt.Errorf("node is stopped: %w", hephaestus.ErrUnavailable)
+	}
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("failed to start node: %w", err)
+	}
+
+	if n.clientNodeConfig.WriteAheadLogConfiguration.Enabled && n.wal == nil {
+		if err := n.restoreJournal(); err != nil {
+			_ = n.transition(hephaestus.NodeStatusError, "write-ahead log restore failed: "+err.Error())
+			return err
+		}
+	}
+	if err := n.transition(hephaestus.NodeStatusOperational, "started"); err != nil {
+		return err
+	}
+	// Flows resumed from the journal are already running
+	n.settle("resumed solution flows")
 
 	return nil
 }
 
-// Stop gracefully stops the node
-func (n *Node) Stop(ctx context.Context) error {
-	n.status = hephaestus.NodeStatusError
+// restoreJournal replays the write-ahead log into the node. Callers must hold n.mu.
+func (n *Node) restoreJournal() error {
+	state, err := n.openJournal()
+	if err != nil {
+		return err
+	}
+
+	// Entries received before Start were never journaled, keep them after the replayed ones
+	received := n.logBuffer.Drain()
+	for _, entry := range state.buffer.Drain() {
+		n.logBuffer.Push(entry)
+		n.mineTemplate(entry)
+	}
+	for _, entry := range received {
+		n.logBuffer.Push(entry)
+		if err := n.journal(journalRecord{Type: journalEntry, Entry: &entry}); err != nil {
+			return fmt.Errorf("failed to persist log entry: %v", err)
+		}
+	}
 
-	// Close channels
-	close(n.solutionChan)
-	close(n.errorChan)
+	for _, id := range state.flowIDs {
+		entries, pending := state.flows[id]
+		if !pending {
+			continue
+		}
+		n.logger.Info("Resuming solution flow", zap.String("flow_id", id), zap.Int("entries", len(entries)))
+		for _, entry := range entries {
+			n.mineTemplate(entry)
+		}
+		n.startFlow(solutionFlow{id: id, entries: entries})
+	}
 
 	return nil
 }
 
+// Stop gracefully stops the node.
+// New log entries are rejected, in-flight solution flows are drained and only then
+// are the solution and error channels closed. If ctx expires first the remaining flows
+// are canceled, aborting their repository and model calls, Stop returns the ctx error
+// and the channels are closed once those flows have unwound.
+func (n *Node) Stop(ctx context.Context) error {
+	n.mu.Lock()
+	if !n.stopped {
+		n.stopped = true
+		if err := n.transition(hephaestus.NodeStatusDraining, "stop requested"); err != nil {
+			n.logger.Warn("Rejected node status transition", zap.Error(err))
+		}
+		// Flows waiting on their after window run with what was captured so far
+		for _, capture := range n.captures {
+			capture.finish()
+		}
+		go n.closeWhenDrained()
+	}
+	n.mu.Unlock()
+
+	select {
+	case <-n.closed:
+		return nil
+	case <-ctx.Done():
+		n.cancel()
+		return fmt.Errorf("failed to drain solution flows: %w", ctx.Err())
+	}
+}
+
+// closeWhenDrained waits for every in-flight solution flow, marks the node stopped and
+// closes the output channels
+func (n *Node) closeWhenDrained() {
+	n.flows.Wait()
+	n.closeJournal()
+
+	n.mu.Lock()
+	// A flow failing while the node drained left it in error, which only drains
+	if err := n.transition(hephaestus.NodeStatusDraining, "stop requested"); err != nil {
+		n.logger.Warn("Rejected node status transition", zap.Error(err))
+	}
+	if err := n.transition(hephaestus.NodeStatusStopped, "solution flows drained"); err != nil {
+		n.logger.Warn("Rejected node status transition", zap.Error(err))
+	}
+	n.closeSubscribers()
+	n.mu.Unlock()
+
+	n.closeOnce.Do(func() {
+		n.cancel()
+		n.solutionOutbox.close()
+		n.errorOutbox.close()
+		close(n.closed)
+	})
+}
+
+// isStopped reports whether Stop has been called
+func (n *Node) isStopped() bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.stopped
+}
+
+// Status returns the current node status
+func (n *Node) Status() hephaestus.NodeStatus {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.status
+}
+
+// BufferStats returns the occupancy and eviction counters of the node log buffer
+func (n *Node) BufferStats() buffer.Stats {
+	return n.logBuffer.Stats()
+}
+
 // ProcessLog processes a new log entry
 func (n *Node) ProcessLog(entry hephaestus.LogEntry) error {
+	// A stopped node takes nothing, not even sampling or rate limit tokens
+	if n.isStopped() {
+		return fmt.Errorf("node is stopped: %w", hephaestus.ErrUnavailable)
+	}
+
+	// Entries dropped by sampling or rate limiting are not an error
+	if reason, level := n.ingestion.admit(entry, time.Now()); reason != "" {
+		n.recordIngestionDrop(level, reason)
+		return nil
+	}
+
+	// Sensitive values are scrubbed before the entry is buffered, journaled or mined
+	entry, redactions := n.redact(entry)
 
-	// Check if log chunk exceeded then remove the old logs
-	if len(n.logBuffer) == n.systemConfig.LimitConfiguration.LogChunkLimit {
-		n.logBuffer = n.logBuffer[1:]
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.stopped {
+		return fmt.Errorf("node is stopped: %w", hephaestus.ErrUnavailable)
 	}
-	// Add to buffer
-	n.logBuffer = append(n.logBuffer, entry)
+	n.recordRedactions(redactions)
+
+	journalErr := n.ingest(entry)
 
 	// Check if we need to process logs
 	if n.shouldProcessLogs(entry) {
-		return n.triggerLogProcessing()
+		if err := n.triggerLogProcessing(); err != nil {
+			return err
+		}
 	}
 
+	if journalErr != nil {
+		return fmt.Errorf("failed to persist log entry: %w", journalErr)
+	}
 	return nil
 }
 
-// shouldProcessLogs checks if we should process logs based on threshold
+// ingest buffers an entry, evicting the oldest logs once the chunk limits are exceeded,
+// hands it to the template miner and open captures and journals it. Callers must hold n.mu.
+func (n *Node) ingest(entry hephaestus.LogEntry) error {
+	n.logBuffer.Push(entry)
+	n.mineTemplate(entry)
+	for _, capture := range n.captures {
+		capture.add(entry)
+	}
+	return n.journal(journalRecord{Type: journalEntry, Entry: &entry})
+}
+
+// mineTemplate feeds an entry message to the log template miner
+func (n *Node) mineTemplate(entry hephaestus.LogEntry) {
+	ts := entry.Timestamp
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+	n.patterns.Add(entry.Message, ts)
+}
+
+// shouldProcessLogs checks if we should process logs based on threshold.
+// Entries belonging to an in-flight or cooling down incident are attached to it and
+// do not count towards the threshold. Callers must hold n.mu.
 func (n *Node) shouldProcessLogs(entry hephaestus.LogEntry) bool {
-	// need to implement logic to cover higher cases
-	return n.clientNodeConfig.LogProcessingConfiguration.ThresholdLevel == entry.Level
+	if !n.threshold.matches(entry.Level) {
+		return false
+	}
+	if n.incidents.attach(fingerprint.Compute(entry), time.Now()) {
+		return false
+	}
+
+	ts := entry.Timestamp
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+	return n.threshold.observe(ts)
 }
 
-// triggerLogProcessing triggers log processing
+// triggerLogProcessing snapshots the buffer and starts a solution flow.
+// Callers must hold n.mu; the flow is registered before the lock is released so Stop
+// always observes it.
 func (n *Node) triggerLogProcessing() error {
-	n.status = hephaestus.NodeStatusProcessing
+	// Leave the entries buffered while the node is at capacity, the trigger is started
+	// once a flow finishes
+	if limit := n.clientNodeConfig.LogProcessingConfiguration.MaxConcurrentFlows; limit > 0 && n.activeFlows >= limit {
+		n.deferredTrigger = true
+		n.logger.Warn("Solution flow deferred, too many flows in flight", zap.Int("active_flows", n.activeFlows))
+		return nil
+	}
+	n.deferredTrigger = false
+
+	// Clear buffer after processing. The trigger is the newest threshold entry, which
+	// is the newest entry unless the trigger was deferred; later entries stay buffered.
+	buffered := n.logBuffer.Drain()
+	end := len(buffered)
+	for end > 0 && !n.threshold.matches(buffered[end-1].Level) {
+		end--
+	}
+	remaining := buffered[end:]
+	for _, entry := range remaining {
+		n.logBuffer.Push(entry)
+	}
+	if end == 0 {
+		return nil
+	}
+	buffered = buffered[:end]
+	n.lastProcessed = time.Now()
+
+	config := n.clientNodeConfig.LogProcessingConfiguration
+	trigger := buffered[len(buffered)-1]
+	triggeredAt := trigger.Timestamp
+	if triggeredAt.IsZero() {
+		triggeredAt = n.lastProcessed
+	}
+
+	n.flowSeq++
+	flow := solutionFlow{
+		id:      fmt.Sprintf("flow-%d-%d", n.lastProcessed.UnixNano(), n.flowSeq),
+		entries: contextBefore(config, buffered, triggeredAt),
+		// Correlated entries are pulled from the whole buffer, not just the before window
+		correlated: correlate(buffered[:len(buffered)-1], correlationValues(trigger, n.correlationKeys())),
+		capture:    newContextCapture(config, n.lastProcessed),
+	}
+	n.startFlow(flow)
+
+	// The record keeps the entries left buffered, replaying it drains the buffer
+	record := journalRecord{Type: journalFlowStarted, FlowID: flow.id, Entries: flow.entries, Buffered: remaining}
+	if err := n.journal(record); err != nil {
+		return fmt.Errorf("failed to persist solution flow: %w", err)
+	}
+	return nil
+}
+
+// solutionFlow is a solution flow about to run
+type solutionFlow struct {
+	id string
+	// entries are the captured entries before the trigger, trigger last
+	entries []hephaestus.LogEntry
+	// correlated are buffered entries sharing a correlation key with the trigger
+	correlated []hephaestus.LogEntry
+	// capture keeps collecting entries until the after window ends, nil when disabled
+	capture *contextCapture
+}
+
+// startFlow registers a solution flow and runs it in a separate goroutine.
+// Callers must hold n.mu.
+func (n *Node) startFlow(flow solutionFlow) {
+	n.activeFlows++
+	n.settle("solution flow started")
+	n.flows.Add(1)
+
+	if len(flow.entries) > 0 {
+		n.incidents.start(flow.id, fingerprint.Compute(flow.entries[len(flow.entries)-1]), time.Now())
+	}
+
+	if n.wal != nil {
+		n.pendingFlows[flow.id] = flow.entries
+		n.pendingOrder = append(n.pendingOrder, flow.id)
+	}
+	if flow.capture != nil {
+		n.captures[flow.id] = flow.capture
+	}
 
 	// Process logs in a separate goroutine
-	go func() {
-		defer func() {
-			n.status = hephaestus.NodeStatusOperational
-		}()
-
-		// Clear buffer after processing
-		entries := make([]hephaestus.LogEntry, len(n.logBuffer))
-		copy(entries, n.logBuffer)
-		n.logBuffer = make([]hephaestus.LogEntry, 0)
-
-		// Generate solution
-		solution, err := n.initateSolutionFlow(entries)
-		if err != nil {
-			n.errorChan <- fmt.Errorf("failed to generate solution: %v", err)
-			return
+	go n.runSolutionFlow(flow)
+}
+
+// runSolutionFlow waits for the after window, generates a solution for the captured
+// entries and publishes the result. The flow runs under a context derived from the
+// node lifecycle and bounded by the configured flow timeout.
+func (n *Node) runSolutionFlow(flow solutionFlow) {
+	ctx, cancel := n.flowContext()
+	defer cancel()
+
+	// A flow canceled by Stop stays pending in the journal and resumes on restart
+	resumable := false
+	defer n.flows.Done()
+	defer func() { n.finishFlow(flow.id, resumable) }()
+	defer n.recoverFlow(flow.id)
+
+	id, entries, capture := flow.id, flow.entries, flow.capture
+	trigger := entries[len(entries)-1]
+	evidence := &hephaestus.Evidence{
+		TriggeredAt: trigger.Timestamp,
+		Trigger:     trigger,
+		Before:      entries[:len(entries)-1],
+	}
+	if evidence.TriggeredAt.IsZero() {
+		evidence.TriggeredAt = time.Now()
+	}
+	if capture != nil {
+		capture.wait(ctx)
+
+		n.mu.Lock()
+		evidence.After = capture.entries
+		delete(n.captures, id)
+		n.mu.Unlock()
+
+		entries = append(entries[:len(entries):len(entries)], evidence.After...)
+	}
+
+	// Pull the rest of the request story from the after window and the log store
+	keys := correlationValues(trigger, n.correlationKeys())
+	stored, err := n.findCorrelated(ctx, keys)
+	stored = n.redactEntries(stored)
+	// Errors caused by the flow context ending are reported once the flow aborts
+	if err != nil && ctx.Err() == nil {
+		n.errorOutbox.send(err)
+	}
+	evidence.Correlated = mergeCorrelated(trigger, flow.correlated, correlate(evidence.After, keys), stored)
+
+	input := solutionInput{
+		entries:  entries,
+		trigger:  trigger,
+		evidence: evidence,
+		// Solution generation works from the dominant templates rather than the raw lines
+		templates: n.patterns.Summarize(entries, dominantTemplateLimit),
+		trace:     traceOf(trigger, entries),
+	}
+
+	// The stack trace decides which repository files are relevant
+	locations, sources, err := n.fetchSources(ctx, input.trace)
+	if err != nil && ctx.Err() == nil {
+		n.errorOutbox.send(err)
+	}
+	input.locations, input.sources = locations, sources
+
+	// Generate solution
+	solution, err := n.initateSolutionFlow(ctx, input)
+	if ctx.Err() != nil {
+		resumable = n.abortFlow(ctx, flow.id)
+		return
+	}
+	if err != nil {
+		n.errorOutbox.send(fmt.Errorf("failed to generate solution: %v", err))
+		return
+	}
+	n.discardImplausibleChanges(solution, input.locations)
+
+	n.mu.Lock()
+	solution.Fingerprint, solution.Occurrences = n.incidents.lookup(id)
+	collector := n.metrics
+	n.mu.Unlock()
+
+	if collector != nil {
+		if err := collector.RecordIncident(ctx, n.clientNodeConfig.NodeID, solution.Fingerprint, solution.Occurrences); err != nil {
+			n.currentLogger().Debug("Failed to record incident", zap.Error(err))
 		}
+	}
+
+	// Route the solution through the operation mode
+	if err := n.handleSolutionMode(ctx, solution); err != nil {
+		n.errorOutbox.send(fmt.Errorf("failed to handle solution %s in %s mode: %w", solution.ID, n.mode(), err))
+	}
 
-		// Send solution for processing
-		n.solutionChan <- solution
-	}()
+	// Hand the solution to registered handlers before publishing it
+	n.dispatchSolution(ctx, solution)
 
-	return nil
+	// Send solution for processing
+	n.solutionOutbox.send(solution)
 }
 
-// generateSolution generates a solution based on log entries
-func (n *Node) initateSolutionFlow(entries []hephaestus.LogEntry) (*hephaestus.Solution, error) {
+// recoverFlow turns a panicking solution flow into a node error so the panic does not
+// take down the process and the other nodes hosted in it. It must be deferred.
+func (n *Node) recoverFlow(id string) {
+	r := recover()
+	if r == nil {
+		return
+	}
+
+	err := fmt.Errorf("solution flow %s panicked: %v", id, r)
+	n.mu.Lock()
+	if transitionErr := n.transition(hephaestus.NodeStatusError, err.Error()); transitionErr != nil {
+		n.logger.Warn("Rejected node status transition", zap.Error(transitionErr))
+	} else {
+		n.flowPanicked = true
+	}
+	n.mu.Unlock()
+	n.errorOutbox.send(err)
+}
+
+// flowContext derives a solution flow context from the node lifecycle context
+func (n *Node) flowContext() (context.Context, context.CancelFunc) {
+	if timeout := n.clientNodeConfig.LogProcessingConfiguration.FlowTimeout; timeout > 0 {
+		return context.WithTimeout(n.ctx, timeout)
+	}
+	return context.WithCancel(n.ctx)
+}
+
+// abortFlow reports a solution flow whose context ended before a solution was
+// generated and tells whether the flow should resume after a restart. Flows past their
+// deadline are reported on the error channel, flows canceled by Stop are resumable.
+func (n *Node) abortFlow(ctx context.Context, id string) bool {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		n.errorOutbox.send(fmt.Errorf("solution flow %s exceeded its deadline: %w", id, hephaestus.ErrTimeout))
+		return false
+	}
+	n.currentLogger().Info("Solution flow canceled", zap.String("flow_id", id))
+	return true
+}
+
+// finishFlow records the flow as finished and returns the node to operational once
+// the last in-flight flow completes. A resumable flow is left pending in the journal,
+// and a trigger deferred at MaxConcurrentFlows is started in its place.
+func (n *Node) finishFlow(id string, resumable bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if _, pending := n.pendingFlows[id]; pending && !resumable {
+		delete(n.pendingFlows, id)
+		if err := n.journal(journalRecord{Type: journalFlowFinished, FlowID: id}); err != nil {
+			n.logger.Warn("Failed to persist finished solution flow", zap.String("flow_id", id), zap.Error(err))
+		}
+	}
+
+	n.incidents.finish(id, time.Now())
+
+	n.activeFlows--
+	if n.deferredTrigger && !n.stopped {
+		if err := n.triggerLogProcessing(); err != nil {
+			n.logger.Warn("Failed to start deferred solution flow", zap.Error(err))
+		}
+	}
+	n.settle("solution flows finished")
+}
+
+// solutionInput is the context a solution is generated from
+type solutionInput struct {
+	// entries spans the captured window, trigger included
+	entries   []hephaestus.LogEntry
+	trigger   hephaestus.LogEntry
+	evidence  *hephaestus.Evidence
+	templates []hephaestus.LogTemplate
+	trace     *stacktrace.Trace
+	// locations are the repository files the trace points at, innermost frame first
+	locations []sourceLocation
+	// sources holds the fetched contents of the located files keyed by path
+	sources map[string]string
+}
+
+// generateSolution generates a solution based on log entries and their dominant templates
+func (n *Node) initateSolutionFlow(ctx context.Context, input solutionInput) (*hephaestus.Solution, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	sourceFiles := make([]string, 0, len(input.locations))
+	for _, location := range input.locations {
+		if _, fetched := input.sources[location.path]; fetched {
+			sourceFiles = append(sourceFiles, location.path)
+		}
+	}
+
 	// TODO: Implement solution generation logic
 	return &hephaestus.Solution{
 		ID:          fmt.Sprintf("sol-%d", time.Now().UnixNano()),
-		LogEntry:    entries[len(entries)-1],
+		LogEntry:    input.trigger,
 		Description: "Generated solution",
 		GeneratedAt: time.Now(),
 		Confidence:  0.8,
+		Templates:   input.templates,
+		SourceFiles: sourceFiles,
+		Evidence:    input.evidence,
 	}, nil
 }
 
+// handleSolutionMode routes a solution through the configured operation mode
+func (n *Node) handleSolutionMode(ctx context.Context, solution *hephaestus.Solution) error {
+	switch n.mode() {
+	case hephaestus.OperationModeDeploy:
+		return n.handleDeployMode(ctx, solution)
+	default:
+		return n.handleSuggestMode(solution)
+	}
+}
+
 // handleSuggestMode handles solution in suggest mode
 func (n *Node) handleSuggestMode(solution *hephaestus.Solution) error {
-	fmt.Printf("[Hephaestus] Solution generated: %s\n", solution.Description)
+	n.currentLogger().Info("Solution generated",
+		zap.String("solution_id", solution.ID),
+		zap.String("fingerprint", solution.Fingerprint),
+		zap.Int("occurrences", solution.Occurrences),
+		zap.String("description", solution.Description),
+		zap.Float64("confidence", solution.Confidence),
+		zap.String("log_level", solution.LogEntry.Level),
+		zap.String("log_message", solution.LogEntry.Message),
+		zap.Int("code_changes", len(solution.CodeChanges)),
+		zap.Strings("dominant_templates", templatePatterns(solution.Templates)),
+		zap.Time("generated_at", solution.GeneratedAt),
+	)
+	return nil
+}
+
+// handleDeployMode handles solution in deploy mode by opening a pull request with its changes
+func (n *Node) handleDeployMode(ctx context.Context, solution *hephaestus.Solution) error {
+	n.mu.Lock()
+	remote := n.remoteRepository
+	n.mu.Unlock()
+
+	if remote == nil {
+		return fmt.Errorf("%w: remote repository is not configured", hephaestus.ErrInvalidConfig)
+	}
+	if len(solution.CodeChanges) == 0 {
+		return fmt.Errorf("solution %s has no code changes to deploy", solution.ID)
+	}
+
+	prURL, err := remote.CreatePullRequest(ctx, pullRequestTitle(solution), pullRequestBody(solution), solution.CodeChanges)
+	if err != nil {
+		return fmt.Errorf("failed to create pull request: %w", err)
+	}
+	solution.PullRequestURL = prURL
+
+	n.currentLogger().Info("Solution deployed",
+		zap.String("solution_id", solution.ID),
+		zap.String("pull_request_url", prURL),
+	)
 	return nil
 }
 
-// handleDeployMode handles solution in deploy mode
-func (n *Node) handleDeployMode(solution *hephaestus.Solution) error {
-	// TODO: Implement remote repository PR creation
-	return fmt.Errorf("deploy mode not implemented yet")
+// pullRequestTitle builds the pull request title for a solution
+func pullRequestTitle(solution *hephaestus.Solution) string {
+	return fmt.Sprintf("fix: %s", solution.Description)
+}
+
+// pullRequestBody builds the pull request description for a solution
+func pullRequestBody(solution *hephaestus.Solution) string {
+	var body strings.Builder
+	fmt.Fprintf(&body, "%s\n\n", solution.Description)
+	fmt.Fprintf(&body, "- Solution: `%s`\n", solution.ID)
+	fmt.Fprintf(&body, "- Confidence: %.2f\n", solution.Confidence)
+	fmt.Fprintf(&body, "- Triggering log (%s): %s\n", solution.LogEntry.Level, solution.LogEntry.Message)
+	if evidence := solution.Evidence; evidence != nil {
+		fmt.Fprintf(&body, "- Evidence: %d entries before and %d after the trigger\n", len(evidence.Before), len(evidence.After))
+	}
+	for _, change := range solution.CodeChanges {
+		if change.Description != "" {
+			fmt.Fprintf(&body, "- `%s`: %s\n", change.FilePath, change.Description)
+		}
+	}
+	if len(solution.Templates) > 0 {
+		body.WriteString("\nDominant log templates:\n")
+		for _, template := range solution.Templates {
+			marker := ""
+			if template.New {
+				marker = " (new)"
+			}
+			fmt.Fprintf(&body, "- `%s` x%d, %.0f%% of the window vs %.0f%% before%s\n",
+				template.Pattern, template.Count, template.Share*100, template.BaselineShare*100, marker)
+		}
+	}
+	body.WriteString("\nGenerated by Hephaestus.\n")
+	return body.String()
+}
+
+// templatePatterns returns the pattern text of each template
+func templatePatterns(templates []hephaestus.LogTemplate) []string {
+	patterns := make([]string, len(templates))
+	for i, template := range templates {
+		patterns[i] = template.Pattern
+	}
+	return patterns
+}
+
+// SetLogger sets the structured logger used for node output
+func (n *Node) SetLogger(logger *zap.Logger) {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.logger = logger
+}
+
+// SetMetricsCollector sets the collector that receives node metrics
+func (n *Node) SetMetricsCollector(collector hephaestus.MetricsCollectionService) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.metrics = collector
+}
+
+// recordChannelDrop reports an item dropped by an output channel backpressure policy
+func (n *Node) recordChannelDrop(channel, reason string) {
+	n.mu.Lock()
+	collector := n.metrics
+	logger := n.logger
+	n.mu.Unlock()
+
+	logger.Warn("Output channel item dropped", zap.String("channel", channel), zap.String("reason", reason))
+	if collector == nil {
+		return
+	}
+	if err := collector.RecordChannelDrop(context.Background(), n.clientNodeConfig.NodeID, channel, reason); err != nil {
+		logger.Debug("Failed to record channel drop", zap.Error(err))
+	}
+}
+
+// reportSpillError logs a spill file that could not be removed after delivery
+func (n *Node) reportSpillError(channel string, err error) {
+	n.currentLogger().Warn("Failed to remove delivered spill file", zap.String("channel", channel), zap.Error(err))
+}
+
+// nodeSpillDirectory returns the spill directory of a node's channel
+func nodeSpillDirectory(config hephaestus.ChannelConfiguration, nodeID string) string {
+	if config.SpillDirectory == "" {
+		return ""
+	}
+	return filepath.Join(config.SpillDirectory, nodeID)
+}
+
+// currentLogger returns the logger set on the node
+func (n *Node) currentLogger() *zap.Logger {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.logger
+}
+
+// SetLogStore sets the persisted log store searched for correlated entries
+func (n *Node) SetLogStore(store hephaestus.LogStore) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.logStore = store
+}
+
+// SetRemoteRepository sets the repository service used to deploy solutions
+func (n *Node) SetRemoteRepository(remote hephaestus.RemoteRepositoryService) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.remoteRepository = remote
+}
+
+// GetSolutions returns the solution channel.
+// The channel is closed once the node is stopped and every in-flight flow has finished.
+func (n *Node) GetSolutions() <-chan *hephaestus.Solution {
+	return n.solutionOutbox.ch
 }
 
 // GetErrors returns the error channel
 func (n *Node) GetErrors() <-chan error {
-	return n.errorChan
+	return n.errorOutbox.ch
 }
@@ -1,11 +1,19 @@
 package node
 
 import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/HoyeonS/hephaestus/pkg/hephaestus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 // MockLogBuffer is a mock implementation of LogBuffer
@@ -45,6 +53,79 @@ func (m *MockSolutionGenerator) Generate(entries []hephaestus.LogEntry) (*hephae
 	return args.Get(0).(*hephaestus.Solution), args.Error(1)
 }
 
+// testNodeOption adjusts the configuration of a node created by newTestNode
+type testNodeOption func(*hephaestus.SystemConfiguration, *hephaestus.ClientNodeConfiguration)
+
+// newTestNode creates a node named "test-node" buffering up to 10 entries, with a
+// no-op logger and the options applied
+func newTestNode(t *testing.T, options ...testNodeOption) *Node {
+	t.Helper()
+
+	systemConfig := &hephaestus.SystemConfiguration{
+		LimitConfiguration: hephaestus.LimitConfiguration{LogChunkLimit: 10},
+	}
+	clientConfig := &hephaestus.ClientNodeConfiguration{NodeID: "test-node"}
+	for _, option := range options {
+		option(systemConfig, clientConfig)
+	}
+
+	node, err := NewNode(systemConfig, clientConfig)
+	require.NoError(t, err)
+	node.SetLogger(zap.NewNop())
+	return node
+}
+
+// withLimits replaces the system limits of the node
+func withLimits(limits hephaestus.LimitConfiguration) testNodeOption {
+	return func(systemConfig *hephaestus.SystemConfiguration, _ *hephaestus.ClientNodeConfiguration) {
+		systemConfig.LimitConfiguration = limits
+	}
+}
+
+// withLogProcessing sets the log processing settings of the node
+func withLogProcessing(config hephaestus.LogProcessingConfiguration) testNodeOption {
+	return func(_ *hephaestus.SystemConfiguration, clientConfig *hephaestus.ClientNodeConfiguration) {
+		clientConfig.LogProcessingConfiguration = config
+	}
+}
+
+// withBackpressure sets the output channel policies of the node
+func withBackpressure(config hephaestus.BackpressureConfiguration) testNodeOption {
+	return func(_ *hephaestus.SystemConfiguration, clientConfig *hephaestus.ClientNodeConfiguration) {
+		clientConfig.BackpressureConfiguration = config
+	}
+}
+
+// withJournal keeps the write-ahead log of the node in dir
+func withJournal(dir string) testNodeOption {
+	return func(_ *hephaestus.SystemConfiguration, clientConfig *hephaestus.ClientNodeConfiguration) {
+		clientConfig.WriteAheadLogConfiguration = hephaestus.WriteAheadLogConfiguration{Enabled: true, Directory: dir}
+	}
+}
+
+// withRedaction sets the redaction settings of the node
+func withRedaction(config hephaestus.RedactionConfiguration) testNodeOption {
+	return func(_ *hephaestus.SystemConfiguration, clientConfig *hephaestus.ClientNodeConfiguration) {
+		clientConfig.RedactionConfiguration = config
+	}
+}
+
+// withIngestion sets the sampling and rate limiting settings of the node
+func withIngestion(config hephaestus.IngestionConfiguration) testNodeOption {
+	return func(_ *hephaestus.SystemConfiguration, clientConfig *hephaestus.ClientNodeConfiguration) {
+		clientConfig.IngestionConfiguration = config
+	}
+}
+
+// withMode sets the operation mode of the node and the repository deploy mode opens
+// pull requests against
+func withMode(mode hephaestus.OperationMode, repository hephaestus.RemoteRepositoryConfiguration) testNodeOption {
+	return func(_ *hephaestus.SystemConfiguration, clientConfig *hephaestus.ClientNodeConfiguration) {
+		clientConfig.Mode = mode
+		clientConfig.RemoteRepositoryConfiguration = repository
+	}
+}
+
 func TestNewNode(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -87,6 +168,86 @@ func TestNewNode(t *testing.T) {
 	}
 }
 
+// distinctWord spells i in letters so messages do not share a fingerprint
+func distinctWord(i int) string {
+	return string([]byte{byte('a' + i/26%26), byte('a' + i%26)})
+}
+
+func TestNode_ConcurrentProcessLogAndStop(t *testing.T) {
+	node := newTestNode(t,
+		withLimits(hephaestus.LimitConfiguration{LogChunkLimit: 50}),
+		withLogProcessing(hephaestus.LogProcessingConfiguration{ThresholdLevel: "error"}),
+	)
+	require.NoError(t, node.Start(context.Background()))
+
+	var consumed sync.WaitGroup
+	consumed.Add(2)
+	solutions := 0
+	go func() {
+		defer consumed.Done()
+		for range node.solutionOutbox.ch {
+			solutions++
+		}
+	}()
+	go func() {
+		defer consumed.Done()
+		for range node.GetErrors() {
+		}
+	}()
+
+	var producers sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		producers.Add(1)
+		go func(worker int) {
+			defer producers.Done()
+			for j := 0; j < 50; j++ {
+				level := "info"
+				if j%10 == 0 {
+					level = "error"
+				}
+				_ = node.ProcessLog(hephaestus.LogEntry{
+					Timestamp: time.Now(),
+					Level:     level,
+					Message:   fmt.Sprintf("worker %s entry %s", distinctWord(worker), distinctWord(j)),
+				})
+				_ = node.Status()
+			}
+		}(i)
+	}
+	producers.Wait()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, node.Stop(ctx))
+	consumed.Wait()
+
+	assert.Equal(t, 40, solutions)
+	assert.ErrorIs(t, node.ProcessLog(hephaestus.LogEntry{Level: "error"}), hephaestus.ErrUnavailable)
+	assert.NoError(t, node.Stop(ctx))
+}
+
+func TestNode_StopWaitsForInFlightFlows(t *testing.T) {
+	node := newTestNode(t)
+
+	// Fill the solution channel so the next flow blocks on send
+	for i := 0; i < cap(node.solutionOutbox.ch); i++ {
+		node.solutionOutbox.ch <- &hephaestus.Solution{}
+	}
+	require.NoError(t, node.ProcessLog(hephaestus.LogEntry{Level: "error", Message: "blocked"}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, node.Stop(ctx), context.DeadlineExceeded)
+
+	// Draining the channel lets the flow finish and the node close its channels
+	count := 0
+	for range node.solutionOutbox.ch {
+		count++
+	}
+	assert.Equal(t, 101, count)
+	assert.NoError(t, node.Stop(context.Background()))
+}
+
 // func TestNode_ProcessLog(t *testing.T) {
 // 	mockBuffer := new(MockLogBuffer)
 // 	mockMonitor := new(MockThresholdMonitor)
@@ -211,3 +372,231 @@ func TestNewNode(t *testing.T) {
 // 	errors := node.GetErrors()
 // 	assert.NotNil(t, errors)
 // }
+
+func TestNode_ProcessLogThresholdCount(t *testing.T) {
+	node := newTestNode(t, withLogProcessing(hephaestus.LogProcessingConfiguration{
+		ThresholdLevel:  "error",
+		ThresholdCount:  2,
+		ThresholdWindow: time.Minute,
+	}))
+
+	now := time.Now()
+	assert.NoError(t, node.ProcessLog(hephaestus.LogEntry{Timestamp: now, Level: "error", Message: "first"}))
+	assert.NoError(t, node.ProcessLog(hephaestus.LogEntry{Timestamp: now, Level: "info", Message: "noise"}))
+	assert.Equal(t, hephaestus.NodeStatusInitializing, node.Status())
+
+	assert.NoError(t, node.ProcessLog(hephaestus.LogEntry{Timestamp: now.Add(time.Second), Level: "error", Message: "second"}))
+
+	select {
+	case solution := <-node.solutionOutbox.ch:
+		assert.Equal(t, "second", solution.LogEntry.Message)
+	case <-time.After(time.Second):
+		t.Fatal("expected a solution after threshold count was reached")
+	}
+}
+
+// fakeRemoteRepository records pull requests created by the node
+type fakeRemoteRepository struct {
+	mu      sync.Mutex
+	titles  []string
+	changes [][]hephaestus.Change
+	err     error
+	files   []string
+	fetched []string
+	// truncated makes ListFiles fail as for a repository too large to list
+	truncated bool
+}
+
+func (f *fakeRemoteRepository) CreatePullRequest(ctx context.Context, title, body string, changes []hephaestus.Change) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return "", f.err
+	}
+	f.titles = append(f.titles, title)
+	f.changes = append(f.changes, changes)
+	return fmt.Sprintf("https://github.com/test-owner/test-repo/pull/%d", len(f.titles)), nil
+}
+
+func (f *fakeRemoteRepository) ListFiles(ctx context.Context) ([]string, error) {
+	if f.truncated {
+		return nil, fmt.Errorf("%w: tree of main", hephaestus.ErrTruncated)
+	}
+	return f.files, nil
+}
+
+func (f *fakeRemoteRepository) FetchFiles(ctx context.Context, paths []string) (map[string]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.fetched = append(f.fetched, paths...)
+	contents := make(map[string]string, len(paths))
+	for _, filePath := range paths {
+		if slices.Contains(f.files, filePath) {
+			contents[filePath] = "// " + filePath
+		}
+	}
+	return contents, nil
+}
+
+func TestNode_SuggestMode(t *testing.T) {
+	node := newTestNode(t, withMode(hephaestus.OperationModeSuggest, hephaestus.RemoteRepositoryConfiguration{}))
+
+	core, logs := observer.New(zap.InfoLevel)
+	node.SetLogger(zap.New(core))
+
+	solution := &hephaestus.Solution{ID: "sol-1", Description: "nil pointer in handler", Confidence: 0.9}
+	require.NoError(t, node.handleSolutionMode(context.Background(), solution))
+
+	entries := logs.FilterMessage("Solution generated").All()
+	require.Len(t, entries, 1)
+	assert.Equal(t, "sol-1", entries[0].ContextMap()["solution_id"])
+	assert.Empty(t, solution.PullRequestURL)
+}
+
+func TestNode_DeployMode(t *testing.T) {
+	node := newTestNode(t, withMode(hephaestus.OperationModeDeploy, hephaestus.RemoteRepositoryConfiguration{
+		RemoteRepositoryOwner: "test-owner",
+		RemoteRepositoryName:  "test-repo",
+	}))
+
+	solution := &hephaestus.Solution{
+		ID:          "sol-1",
+		Description: "guard nil handler",
+		CodeChanges: []hephaestus.Change{{FilePath: "main.go", NewContent: "package main\n"}},
+	}
+
+	t.Run("remote repository not configured", func(t *testing.T) {
+		assert.ErrorIs(t, node.handleSolutionMode(context.Background(), solution), hephaestus.ErrInvalidConfig)
+	})
+
+	t.Run("pull request opened", func(t *testing.T) {
+		remote := &fakeRemoteRepository{}
+		node.SetRemoteRepository(remote)
+
+		require.NoError(t, node.handleSolutionMode(context.Background(), solution))
+		assert.Equal(t, "https://github.com/test-owner/test-repo/pull/1", solution.PullRequestURL)
+		assert.Equal(t, []string{"fix: guard nil handler"}, remote.titles)
+		assert.Equal(t, solution.CodeChanges, remote.changes[0])
+	})
+
+	t.Run("no code changes", func(t *testing.T) {
+		node.SetRemoteRepository(&fakeRemoteRepository{})
+		assert.Error(t, node.handleSolutionMode(context.Background(), &hephaestus.Solution{ID: "sol-2"}))
+	})
+
+	t.Run("repository failure", func(t *testing.T) {
+		node.SetRemoteRepository(&fakeRemoteRepository{err: hephaestus.ErrRemoteRepositoryError})
+		assert.ErrorIs(t, node.handleSolutionMode(context.Background(), solution), hephaestus.ErrRemoteRepositoryError)
+	})
+}
+
+func TestValidateClientNodeConfigurationMode(t *testing.T) {
+	_, err := NewNode(&hephaestus.SystemConfiguration{}, &hephaestus.ClientNodeConfiguration{Mode: "yolo"})
+	assert.Error(t, err)
+
+	_, err = NewNode(&hephaestus.SystemConfiguration{}, &hephaestus.ClientNodeConfiguration{Mode: hephaestus.OperationModeDeploy})
+	assert.Error(t, err)
+}
+
+func TestNode_BufferLimits(t *testing.T) {
+	node := newTestNode(t, withLimits(hephaestus.LimitConfiguration{LogChunkLimit: 3, LogChunkByteLimit: 1024}))
+
+	for i := 0; i < 10; i++ {
+		require.NoError(t, node.ProcessLog(hephaestus.LogEntry{Level: "info", Message: fmt.Sprintf("entry %d", i)}))
+	}
+
+	stats := node.BufferStats()
+	assert.Equal(t, 3, stats.Entries)
+	assert.Equal(t, uint64(7), stats.EvictedEntries)
+
+	_, err := NewNode(&hephaestus.SystemConfiguration{
+		LimitConfiguration: hephaestus.LimitConfiguration{LogChunkByteLimit: -1},
+	}, &hephaestus.ClientNodeConfiguration{})
+	assert.Error(t, err)
+}
+
+func TestNode_SolutionCarriesDominantTemplates(t *testing.T) {
+	node := newTestNode(t, withLimits(hephaestus.LimitConfiguration{LogChunkLimit: 50}))
+
+	for i := 0; i < 5; i++ {
+		require.NoError(t, node.ProcessLog(hephaestus.LogEntry{Level: "info", Message: fmt.Sprintf("served request in %d ms", i)}))
+	}
+	for i := 0; i < 3; i++ {
+		require.NoError(t, node.ProcessLog(hephaestus.LogEntry{Level: "warn", Message: fmt.Sprintf("pool exhausted after %d retries", i)}))
+	}
+	require.NoError(t, node.ProcessLog(hephaestus.LogEntry{Level: "error", Message: "database unavailable"}))
+
+	solution := <-node.GetSolutions()
+	require.Len(t, solution.Templates, 3)
+	assert.Equal(t, "served request in <*> ms", solution.Templates[0].Pattern)
+	assert.Equal(t, "pool exhausted after <*> retries", solution.Templates[1].Pattern)
+	assert.Equal(t, 3, solution.Templates[1].Count)
+	assert.Equal(t, "database unavailable", solution.Templates[2].Pattern)
+	assert.True(t, solution.Templates[2].New)
+	assert.Contains(t, pullRequestBody(solution), "`pool exhausted after <*> retries` x3")
+}
+
+// blockingLogStore blocks correlated lookups until released or their context ends
+type blockingLogStore struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (b *blockingLogStore) FindCorrelated(ctx context.Context, key, value string) ([]hephaestus.LogEntry, error) {
+	select {
+	case b.started <- struct{}{}:
+	default:
+	}
+	select {
+	case <-b.release:
+		return nil, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func TestNode_StopCancelsInFlightFlows(t *testing.T) {
+	node := newTestNode(t)
+	store := &blockingLogStore{started: make(chan struct{}, 1)}
+	node.SetLogStore(store)
+
+	require.NoError(t, node.ProcessLog(hephaestus.LogEntry{Level: "error", Message: "boom", Context: map[string]interface{}{"trace_id": "t-1"}}))
+	<-store.started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, node.Stop(ctx), context.DeadlineExceeded)
+
+	// The canceled flow unwinds without publishing a solution or an error
+	for range node.GetSolutions() {
+		t.Fatal("unexpected solution from a canceled flow")
+	}
+	for err := range node.GetErrors() {
+		t.Fatalf("unexpected error from a canceled flow: %v", err)
+	}
+	assert.Equal(t, hephaestus.NodeStatusStopped, node.Status())
+}
+
+func TestNode_FlowTimeout(t *testing.T) {
+	node := newTestNode(t, withLogProcessing(hephaestus.LogProcessingConfiguration{FlowTimeout: 20 * time.Millisecond}))
+	node.SetLogStore(&blockingLogStore{started: make(chan struct{}, 1)})
+
+	require.NoError(t, node.ProcessLog(hephaestus.LogEntry{Level: "error", Message: "boom", Context: map[string]interface{}{"trace_id": "t-1"}}))
+
+	select {
+	case err := <-node.GetErrors():
+		assert.ErrorIs(t, err, hephaestus.ErrTimeout)
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the flow deadline")
+	}
+	require.NoError(t, node.Stop(context.Background()))
+}
+
+func TestNode_StartHonorsContext(t *testing.T) {
+	node := newTestNode(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, node.Start(ctx), context.Canceled)
+	assert.Equal(t, hephaestus.NodeStatusInitializing, node.Status())
+}
@@ -0,0 +1,99 @@
+package node
+
+import (
+	"sort"
+
+	"github.com/HoyeonS/hephaestus/pkg/hephaestus"
+	"go.uber.org/zap"
+)
+
+// redactionAudit accumulates the redactions applied by a node
+type redactionAudit struct {
+	entries int
+	counts  map[hephaestus.Redaction]int
+}
+
+// record adds the redactions applied to one entry
+func (a *redactionAudit) record(redactions []hephaestus.Redaction) {
+	if len(redactions) == 0 {
+		return
+	}
+	if a.counts == nil {
+		a.counts = make(map[hephaestus.Redaction]int)
+	}
+	a.entries++
+	for _, redaction := range redactions {
+		count := redaction.Count
+		redaction.Count = 0
+		a.counts[redaction] += count
+	}
+}
+
+// summary returns the accumulated redactions ordered by field and rule
+func (a *redactionAudit) summary() hephaestus.RedactionSummary {
+	summary := hephaestus.RedactionSummary{Entries: a.entries}
+	for redaction, count := range a.counts {
+		redaction.Count = count
+		summary.Redactions = append(summary.Redactions, redaction)
+	}
+	sort.Slice(summary.Redactions, func(i, j int) bool {
+		if summary.Redactions[i].Field != summary.Redactions[j].Field {
+			return summary.Redactions[i].Field < summary.Redactions[j].Field
+		}
+		return summary.Redactions[i].Rule < summary.Redactions[j].Rule
+	})
+	return summary
+}
+
+// redact scrubs an entry when redaction is enabled. The redactions are returned for
+// the caller to record once it holds n.mu.
+func (n *Node) redact(entry hephaestus.LogEntry) (hephaestus.LogEntry, []hephaestus.Redaction) {
+	if n.redactor == nil {
+		return entry, nil
+	}
+	return n.redactor.Redact(entry)
+}
+
+// recordRedactions adds redactions to the node audit. Callers must hold n.mu.
+func (n *Node) recordRedactions(redactions []hephaestus.Redaction) {
+	if len(redactions) == 0 {
+		return
+	}
+	n.redactions.record(redactions)
+
+	fields := make([]zap.Field, 0, len(redactions))
+	for _, redaction := range redactions {
+		fields = append(fields, zap.Int(redaction.Rule+"@"+redaction.Field, redaction.Count))
+	}
+	n.logger.Debug("Log entry redacted", fields...)
+}
+
+// redactEntries scrubs entries that did not pass through ProcessLog, such as the ones
+// found in the log store
+func (n *Node) redactEntries(entries []hephaestus.LogEntry) []hephaestus.LogEntry {
+	if n.redactor == nil || len(entries) == 0 {
+		return entries
+	}
+
+	redacted := make([]hephaestus.LogEntry, len(entries))
+	var applied [][]hephaestus.Redaction
+	for i, entry := range entries {
+		var redactions []hephaestus.Redaction
+		redacted[i], redactions = n.redactor.Redact(entry)
+		applied = append(applied, redactions)
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for _, redactions := range applied {
+		n.recordRedactions(redactions)
+	}
+	return redacted
+}
+
+// RedactionSummary returns an audit of the redactions the node applied so far
+func (n *Node) RedactionSummary() hephaestus.RedactionSummary {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.redactions.summary()
+}
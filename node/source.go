@@ -0,0 +1,244 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/HoyeonS/hephaestus/pkg/hephaestus"
+	"github.com/HoyeonS/hephaestus/pkg/hephaestus/stacktrace"
+	"go.uber.org/zap"
+)
+
+const (
+	// defaultSourceFileLimit is used when no file node count limit is configured
+	defaultSourceFileLimit = 5
+	// plausibleLineDistance is how far a change may start or end from a frame line
+	plausibleLineDistance = 50
+	// maxFramePathLookups bounds the paths fetched when the repository is too large to list
+	maxFramePathLookups = 50
+)
+
+// sourceLocation is a repository file referenced by a stack trace and the lines it points at
+type sourceLocation struct {
+	path  string
+	lines []int
+}
+
+// traceOf parses the error trace of the trigger entry, falling back to the latest
+// entry that carries one
+func traceOf(trigger hephaestus.LogEntry, entries []hephaestus.LogEntry) *stacktrace.Trace {
+	if trigger.ErrorTrace != "" {
+		return stacktrace.Parse(trigger.ErrorTrace)
+	}
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].ErrorTrace != "" {
+			return stacktrace.Parse(entries[i].ErrorTrace)
+		}
+	}
+	return &stacktrace.Trace{Language: stacktrace.LanguageUnknown}
+}
+
+// fetchSources fetches the repository files the trace frames point at.
+// Nothing is fetched when no remote repository is set or the trace has no frames.
+func (n *Node) fetchSources(ctx context.Context, trace *stacktrace.Trace) ([]sourceLocation, map[string]string, error) {
+	n.mu.Lock()
+	remote := n.remoteRepository
+	n.mu.Unlock()
+
+	frames := trace.Frames()
+	if remote == nil || len(frames) == 0 {
+		return nil, nil, nil
+	}
+
+	limit := n.systemConfig.LimitConfiguration.FileNodeCountLimit
+	if limit <= 0 {
+		limit = defaultSourceFileLimit
+	}
+
+	files, err := remote.ListFiles(ctx)
+	if errors.Is(err, hephaestus.ErrTruncated) {
+		return fetchFramePaths(ctx, remote, frames, limit)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list repository files: %w", err)
+	}
+
+	locations := locateSources(frames, files, limit)
+	if len(locations) == 0 {
+		return nil, nil, nil
+	}
+
+	paths := make([]string, len(locations))
+	for i, location := range locations {
+		paths[i] = location.path
+	}
+	contents, err := remote.FetchFiles(ctx, paths)
+	if err != nil {
+		return locations, nil, fmt.Errorf("failed to fetch repository files: %w", err)
+	}
+	return locations, contents, nil
+}
+
+// fetchFramePaths locates the frame files of a repository too large to list by fetching
+// every trailing part of their paths, the longest part found is the frame's file
+func fetchFramePaths(ctx context.Context, remote hephaestus.RemoteRepositoryService, frames []stacktrace.Frame, limit int) ([]sourceLocation, map[string]string, error) {
+	var candidates []string
+	seen := make(map[string]bool)
+	for _, frame := range frames {
+		if frame.File == "" {
+			continue
+		}
+		parts := strings.Split(strings.Trim(path.Clean(frame.File), "/"), "/")
+		for i := range parts {
+			candidate := strings.Join(parts[i:], "/")
+			if !seen[candidate] && len(candidates) < maxFramePathLookups {
+				seen[candidate] = true
+				candidates = append(candidates, candidate)
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, nil, nil
+	}
+
+	found, err := remote.FetchFiles(ctx, candidates)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch repository files: %w", err)
+	}
+	files := make([]string, 0, len(found))
+	for file := range found {
+		files = append(files, file)
+	}
+
+	locations := locateSources(frames, files, limit)
+	if len(locations) == 0 {
+		return nil, nil, nil
+	}
+	contents := make(map[string]string, len(locations))
+	for _, location := range locations {
+		contents[location.path] = found[location.path]
+	}
+	return locations, contents, nil
+}
+
+// locateSources maps frames onto repository files, innermost frame first, keeping at
+// most limit files. A frame maps to the file sharing the longest path suffix with it;
+// frames matching several files equally well are skipped.
+func locateSources(frames []stacktrace.Frame, files []string, limit int) []sourceLocation {
+	var locations []sourceLocation
+	index := make(map[string]int)
+
+	for _, frame := range frames {
+		if frame.File == "" {
+			continue
+		}
+		file, ok := matchRepositoryFile(frame.File, files)
+		if !ok {
+			continue
+		}
+
+		i, seen := index[file]
+		if !seen {
+			if len(locations) == limit {
+				continue
+			}
+			i = len(locations)
+			index[file] = i
+			locations = append(locations, sourceLocation{path: file})
+		}
+		if frame.Line > 0 {
+			locations[i].lines = append(locations[i].lines, frame.Line)
+		}
+	}
+	return locations
+}
+
+// matchRepositoryFile finds the repository file sharing the most trailing path
+// components with a frame file
+func matchRepositoryFile(frameFile string, files []string) (string, bool) {
+	frameParts := strings.Split(strings.Trim(path.Clean(frameFile), "/"), "/")
+
+	best, bestScore, ambiguous := "", 0, false
+	for _, file := range files {
+		score := commonSuffix(frameParts, strings.Split(file, "/"))
+		switch {
+		case score > bestScore:
+			best, bestScore, ambiguous = file, score, false
+		case score == bestScore && score > 0:
+			ambiguous = true
+		}
+	}
+	if bestScore == 0 || ambiguous {
+		return "", false
+	}
+	return best, true
+}
+
+// commonSuffix counts the equal trailing path components
+func commonSuffix(a, b []string) int {
+	count := 0
+	for i, j := len(a)-1, len(b)-1; i >= 0 && j >= 0 && a[i] == b[j]; i, j = i-1, j-1 {
+		count++
+	}
+	return count
+}
+
+// plausibleChanges splits changes into those touching a located file near a frame line
+// and the rest. Without locations every change is kept.
+func plausibleChanges(changes []hephaestus.Change, locations []sourceLocation) ([]hephaestus.Change, []hephaestus.Change) {
+	if len(locations) == 0 {
+		return changes, nil
+	}
+
+	var kept, rejected []hephaestus.Change
+	for _, change := range changes {
+		if changeIsPlausible(change, locations) {
+			kept = append(kept, change)
+		} else {
+			rejected = append(rejected, change)
+		}
+	}
+	return kept, rejected
+}
+
+// changeIsPlausible reports whether a change targets a located file near one of its frame lines
+func changeIsPlausible(change hephaestus.Change, locations []sourceLocation) bool {
+	filePath := strings.TrimPrefix(path.Clean(change.FilePath), "/")
+	for _, location := range locations {
+		if location.path != filePath {
+			continue
+		}
+		// Whole file rewrites and files without line information cannot be narrowed down
+		if change.StartLine <= 0 || len(location.lines) == 0 {
+			return true
+		}
+		end := change.EndLine
+		if end < change.StartLine {
+			end = change.StartLine
+		}
+		for _, line := range location.lines {
+			if line >= change.StartLine-plausibleLineDistance && line <= end+plausibleLineDistance {
+				return true
+			}
+		}
+		return false
+	}
+	return false
+}
+
+// discardImplausibleChanges drops solution changes that do not match the trace
+func (n *Node) discardImplausibleChanges(solution *hephaestus.Solution, locations []sourceLocation) {
+	kept, rejected := plausibleChanges(solution.CodeChanges, locations)
+	for _, change := range rejected {
+		n.currentLogger().Warn("Discarded code change outside the stack trace",
+			zap.String("solution_id", solution.ID),
+			zap.String("file_path", change.FilePath),
+			zap.Int("start_line", change.StartLine),
+			zap.Int("end_line", change.EndLine),
+		)
+	}
+	solution.CodeChanges = kept
+}
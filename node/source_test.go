@@ -0,0 +1,145 @@
+package node
+
+import (
+	"testing"
+
+	"github.com/HoyeonS/hephaestus/pkg/hephaestus"
+	"github.com/HoyeonS/hephaestus/pkg/hephaestus/stacktrace"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocateSources(t *testing.T) {
+	files := []string{
+		"cmd/shop/main.go",
+		"internal/orders/service.go",
+		"internal/billing/service.go",
+		"src/main/java/com/acme/shop/Orders.java",
+	}
+
+	tests := []struct {
+		name   string
+		frames []stacktrace.Frame
+		limit  int
+		want   []sourceLocation
+	}{
+		{
+			name: "absolute build paths match by suffix",
+			frames: []stacktrace.Frame{
+				{File: "/src/shop/internal/orders/service.go", Line: 42},
+				{File: "/src/shop/cmd/shop/main.go", Line: 18},
+				{File: "/src/shop/internal/orders/service.go", Line: 80},
+			},
+			limit: 5,
+			want: []sourceLocation{
+				{path: "internal/orders/service.go", lines: []int{42, 80}},
+				{path: "cmd/shop/main.go", lines: []int{18}},
+			},
+		},
+		{
+			name:   "java package paths",
+			frames: []stacktrace.Frame{{File: "com/acme/shop/Orders.java", Line: 17}},
+			limit:  5,
+			want:   []sourceLocation{{path: "src/main/java/com/acme/shop/Orders.java", lines: []int{17}}},
+		},
+		{
+			name:   "ambiguous and unknown files are skipped",
+			frames: []stacktrace.Frame{{File: "service.go", Line: 1}, {File: "/usr/lib/go/src/runtime/panic.go", Line: 9}, {Function: "native"}},
+			limit:  5,
+			want:   nil,
+		},
+		{
+			name: "limit",
+			frames: []stacktrace.Frame{
+				{File: "/build/internal/orders/service.go", Line: 42},
+				{File: "/build/cmd/shop/main.go", Line: 18},
+			},
+			limit: 1,
+			want:  []sourceLocation{{path: "internal/orders/service.go", lines: []int{42}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, locateSources(tt.frames, files, tt.limit))
+		})
+	}
+}
+
+func TestPlausibleChanges(t *testing.T) {
+	locations := []sourceLocation{
+		{path: "internal/orders/service.go", lines: []int{42, 200}},
+		{path: "cmd/shop/main.go"},
+	}
+
+	tests := []struct {
+		name   string
+		change hephaestus.Change
+		want   bool
+	}{
+		{name: "range around frame", change: hephaestus.Change{FilePath: "internal/orders/service.go", StartLine: 40, EndLine: 44}, want: true},
+		{name: "range near second frame", change: hephaestus.Change{FilePath: "/internal/orders/service.go", StartLine: 230, EndLine: 231}, want: true},
+		{name: "range far from frames", change: hephaestus.Change{FilePath: "internal/orders/service.go", StartLine: 120, EndLine: 125}, want: false},
+		{name: "whole file", change: hephaestus.Change{FilePath: "internal/orders/service.go"}, want: true},
+		{name: "file without lines", change: hephaestus.Change{FilePath: "cmd/shop/main.go", StartLine: 900}, want: true},
+		{name: "file outside trace", change: hephaestus.Change{FilePath: "internal/billing/service.go", StartLine: 42}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kept, rejected := plausibleChanges([]hephaestus.Change{tt.change}, locations)
+			if tt.want {
+				assert.Len(t, kept, 1)
+				assert.Empty(t, rejected)
+			} else {
+				assert.Empty(t, kept)
+				assert.Len(t, rejected, 1)
+			}
+		})
+	}
+
+	kept, rejected := plausibleChanges([]hephaestus.Change{{FilePath: "anything.go"}}, nil)
+	assert.Len(t, kept, 1)
+	assert.Empty(t, rejected)
+}
+
+func TestNode_FetchesSourcesFromTrace(t *testing.T) {
+	node := newTestNode(t, withLimits(hephaestus.LimitConfiguration{LogChunkLimit: 10, FileNodeCountLimit: 2}))
+
+	remote := &fakeRemoteRepository{files: []string{"cmd/shop/main.go", "internal/orders/service.go", "go.mod"}}
+	node.SetRemoteRepository(remote)
+
+	require.NoError(t, node.ProcessLog(hephaestus.LogEntry{
+		Level:   "error",
+		Message: "panic recovered",
+		ErrorTrace: "panic: boom\n\ngoroutine 1 [running]:\n" +
+			"github.com/acme/shop/internal/orders.(*Service).Place(0x0)\n" +
+			"\t/src/shop/internal/orders/service.go:42 +0x1d\n" +
+			"main.main()\n" +
+			"\t/src/shop/cmd/shop/main.go:18 +0x25\n",
+	}))
+
+	solution := <-node.GetSolutions()
+	assert.Equal(t, []string{"internal/orders/service.go", "cmd/shop/main.go"}, solution.SourceFiles)
+	assert.Equal(t, []string{"internal/orders/service.go", "cmd/shop/main.go"}, remote.fetched)
+}
+
+func TestNode_FetchesSourcesFromTraceWhenListingIsTruncated(t *testing.T) {
+	node := newTestNode(t)
+
+	remote := &fakeRemoteRepository{files: []string{"internal/orders/service.go", "go.mod"}, truncated: true}
+	node.SetRemoteRepository(remote)
+
+	require.NoError(t, node.ProcessLog(hephaestus.LogEntry{
+		Level:   "error",
+		Message: "panic recovered",
+		ErrorTrace: "panic: boom\n\ngoroutine 1 [running]:\n" +
+			"github.com/acme/shop/internal/orders.(*Service).Place(0x0)\n" +
+			"\t/src/shop/internal/orders/service.go:42 +0x1d\n",
+	}))
+
+	// Every trailing part of the frame path is tried, the one that exists is used
+	solution := <-node.GetSolutions()
+	assert.Equal(t, []string{"internal/orders/service.go"}, solution.SourceFiles)
+	assert.Equal(t, []string{"src/shop/internal/orders/service.go", "shop/internal/orders/service.go", "internal/orders/service.go", "orders/service.go", "service.go"}, remote.fetched)
+}
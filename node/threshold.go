@@ -0,0 +1,76 @@
+package node
+
+import (
+	"time"
+
+	"github.com/HoyeonS/hephaestus/pkg/hephaestus"
+)
+
+// thresholdMonitor counts entries at or above the threshold severity inside a sliding time window
+type thresholdMonitor struct {
+	level  hephaestus.Severity
+	count  int
+	window time.Duration
+	hits   []time.Time
+}
+
+// newThresholdMonitor creates a threshold monitor from the log processing settings
+func newThresholdMonitor(config hephaestus.LogProcessingConfiguration) *thresholdMonitor {
+	count := config.ThresholdCount
+	if count <= 0 {
+		count = 1
+	}
+
+	level := hephaestus.DefaultThresholdSeverity
+	if config.ThresholdLevel != "" {
+		// The level is validated with the configuration, an unknown value keeps the default
+		if parsed, err := hephaestus.ParseSeverity(config.ThresholdLevel); err == nil {
+			level = parsed
+		}
+	}
+
+	return &thresholdMonitor{
+		level:  level,
+		count:  count,
+		window: config.ThresholdWindow,
+		hits:   make([]time.Time, 0, count),
+	}
+}
+
+// matches reports whether an entry level is at or above the threshold severity
+func (m *thresholdMonitor) matches(level string) bool {
+	severity, err := hephaestus.ParseSeverity(level)
+	if err != nil {
+		return false
+	}
+	return severity.AtLeast(m.level)
+}
+
+// record counts a threshold entry seen at ts without evaluating the threshold, the hit
+// is taken into account by the next observe
+func (m *thresholdMonitor) record(ts time.Time) {
+	m.hits = append(m.hits, ts)
+}
+
+// observe records a threshold entry seen at ts and reports whether the threshold is reached.
+// The window is reset after it fires so the next trigger needs a fresh set of entries.
+func (m *thresholdMonitor) observe(ts time.Time) bool {
+	if m.window > 0 {
+		cutoff := ts.Add(-m.window)
+		kept := m.hits[:0]
+		for _, hit := range m.hits {
+			if hit.After(cutoff) {
+				kept = append(kept, hit)
+			}
+		}
+		m.hits = kept
+	}
+
+	m.hits = append(m.hits, ts)
+	if len(m.hits) < m.count {
+		return false
+	}
+
+	m.hits = m.hits[:0]
+	return true
+}
@@ -0,0 +1,85 @@
+package node
+
+import (
+	"testing"
+	"time"
+
+	"github.com/HoyeonS/hephaestus/pkg/hephaestus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestThresholdMonitor(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		config hephaestus.LogProcessingConfiguration
+		hits   []time.Duration
+		want   []bool
+	}{
+		{
+			name:   "default count triggers on first entry",
+			config: hephaestus.LogProcessingConfiguration{},
+			hits:   []time.Duration{0, time.Second},
+			want:   []bool{true, true},
+		},
+		{
+			name:   "count reached inside window",
+			config: hephaestus.LogProcessingConfiguration{ThresholdCount: 3, ThresholdWindow: time.Minute},
+			hits:   []time.Duration{0, 10 * time.Second, 20 * time.Second},
+			want:   []bool{false, false, true},
+		},
+		{
+			name:   "entries outside window are not counted",
+			config: hephaestus.LogProcessingConfiguration{ThresholdCount: 2, ThresholdWindow: time.Minute},
+			hits:   []time.Duration{0, 2 * time.Minute, 4 * time.Minute, 4*time.Minute + time.Second},
+			want:   []bool{false, false, false, true},
+		},
+		{
+			name:   "window resets after trigger",
+			config: hephaestus.LogProcessingConfiguration{ThresholdCount: 2, ThresholdWindow: time.Minute},
+			hits:   []time.Duration{0, time.Second, 2 * time.Second, 3 * time.Second},
+			want:   []bool{false, true, false, true},
+		},
+		{
+			name:   "zero window counts without expiry",
+			config: hephaestus.LogProcessingConfiguration{ThresholdCount: 2},
+			hits:   []time.Duration{0, 24 * time.Hour},
+			want:   []bool{false, true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			monitor := newThresholdMonitor(tt.config)
+			for i, offset := range tt.hits {
+				assert.Equal(t, tt.want[i], monitor.observe(base.Add(offset)), "hit %d", i)
+			}
+		})
+	}
+}
+
+func TestThresholdMonitor_Matches(t *testing.T) {
+	tests := []struct {
+		name      string
+		threshold string
+		level     string
+		want      bool
+	}{
+		{name: "equal level", threshold: "error", level: "error", want: true},
+		{name: "higher level", threshold: "error", level: "fatal", want: true},
+		{name: "alias above threshold", threshold: "warn", level: "ERR", want: true},
+		{name: "syslog severity", threshold: "error", level: "2", want: true},
+		{name: "lower level", threshold: "error", level: "warning", want: false},
+		{name: "unknown level", threshold: "error", level: "loud", want: false},
+		{name: "default threshold", threshold: "", level: "panic", want: true},
+		{name: "default threshold below", threshold: "", level: "info", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			monitor := newThresholdMonitor(hephaestus.LogProcessingConfiguration{ThresholdLevel: tt.threshold})
+			assert.Equal(t, tt.want, monitor.matches(tt.level))
+		})
+	}
+}
@@ -0,0 +1,387 @@
+package pattern
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/HoyeonS/hephaestus/pkg/hephaestus"
+	"github.com/HoyeonS/hephaestus/pkg/hephaestus/fingerprint"
+)
+
+// Wildcard marks a parameter slot in a template
+const Wildcard = "<*>"
+
+// Defaults used when a configuration value is not positive
+const (
+	DefaultDepth               = 4
+	DefaultSimilarityThreshold = 0.4
+	DefaultMaxChildren         = 100
+	DefaultMaxTemplates        = 1000
+)
+
+// Config tunes the template miner
+type Config struct {
+	// Depth of the parse tree including the root and the token count layer
+	Depth int
+	// SimilarityThreshold is the share of matching tokens required to join a template
+	SimilarityThreshold float64
+	// MaxChildren bounds the children of a tree node, further tokens share a wildcard child
+	MaxChildren int
+	// MaxTemplates bounds the templates kept, the least recently seen one is evicted first
+	MaxTemplates int
+}
+
+// Template is a mined log template with parameter slots
+type Template struct {
+	ID        int
+	Tokens    []string
+	Count     int
+	FirstSeen time.Time
+	LastSeen  time.Time
+}
+
+// Pattern returns the template text with parameters shown as wildcards
+func (t *Template) Pattern() string {
+	return strings.Join(t.Tokens, " ")
+}
+
+// treeNode is an inner node of the parse tree, leaves hold the templates. parent and
+// key locate the node so it can be pruned once empty, roots have no parent.
+type treeNode struct {
+	parent    *treeNode
+	key       string
+	children  map[string]*treeNode
+	templates []*Template
+}
+
+func newTreeNode(parent *treeNode, key string) *treeNode {
+	return &treeNode{parent: parent, key: key, children: make(map[string]*treeNode)}
+}
+
+// Miner learns log templates online with a fixed depth parse tree (Drain).
+// Messages are routed by token count and their leading tokens to a small group of
+// templates and join the most similar one, turning differing tokens into parameters.
+type Miner struct {
+	mu sync.Mutex
+
+	config    Config
+	root      map[int]*treeNode
+	templates map[int]*Template
+	leaves    map[int]*treeNode
+	nextID    int
+	total     int
+}
+
+// NewMiner creates a template miner
+func NewMiner(config Config) *Miner {
+	if config.Depth < 3 {
+		config.Depth = DefaultDepth
+	}
+	if config.SimilarityThreshold <= 0 {
+		config.SimilarityThreshold = DefaultSimilarityThreshold
+	}
+	if config.MaxChildren <= 0 {
+		config.MaxChildren = DefaultMaxChildren
+	}
+	if config.MaxTemplates <= 0 {
+		config.MaxTemplates = DefaultMaxTemplates
+	}
+
+	return &Miner{
+		config:    config,
+		root:      make(map[int]*treeNode),
+		templates: make(map[int]*Template),
+		leaves:    make(map[int]*treeNode),
+	}
+}
+
+// Add mines a message seen at ts and returns a copy of the template it joined
+func (m *Miner) Add(message string, ts time.Time) Template {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	tokens := tokenize(message)
+	leaf := m.leaf(tokens, true)
+
+	template := m.bestMatch(leaf, tokens)
+	if template == nil {
+		m.nextID++
+		template = &Template{ID: m.nextID, Tokens: tokens, FirstSeen: ts}
+		leaf.templates = append(leaf.templates, template)
+		m.templates[template.ID] = template
+		m.leaves[template.ID] = leaf
+	} else {
+		for i, token := range tokens {
+			if template.Tokens[i] != token {
+				template.Tokens[i] = Wildcard
+			}
+		}
+	}
+
+	template.Count++
+	if ts.After(template.LastSeen) {
+		template.LastSeen = ts
+	}
+	m.total++
+
+	if len(m.templates) > m.config.MaxTemplates {
+		m.evict(template.ID)
+	}
+
+	return copyTemplate(template)
+}
+
+// Match returns the template a message belongs to without learning from it
+func (m *Miner) Match(message string) (Template, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	template := m.match(tokenize(message))
+	if template == nil {
+		return Template{}, false
+	}
+	return copyTemplate(template), true
+}
+
+// match finds the most specific template whose constant tokens all equal tokens.
+// Callers must hold m.mu.
+func (m *Miner) match(tokens []string) *Template {
+	leaf := m.leaf(tokens, false)
+	if leaf == nil {
+		return nil
+	}
+
+	var best *Template
+	bestParams := 0
+	for _, template := range leaf.templates {
+		params, ok := matchesConstants(template.Tokens, tokens)
+		if !ok {
+			continue
+		}
+		if best == nil || params < bestParams {
+			best, bestParams = template, params
+		}
+	}
+	return best
+}
+
+// Templates returns copies of the mined templates ordered by ID
+func (m *Miner) Templates() []Template {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	templates := make([]Template, 0, len(m.templates))
+	for _, template := range m.templates {
+		templates = append(templates, copyTemplate(template))
+	}
+	sort.Slice(templates, func(i, j int) bool { return templates[i].ID < templates[j].ID })
+	return templates
+}
+
+// Summarize groups entries by template and compares their mix with everything else
+// the miner has seen. The most frequent limit templates are returned, a non-positive
+// limit returns all of them. Entries without a template are skipped.
+func (m *Miner) Summarize(entries []hephaestus.LogEntry, limit int) []hephaestus.LogTemplate {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	counts := make(map[int]int)
+	matched := 0
+	for _, entry := range entries {
+		if template := m.match(tokenize(entry.Message)); template != nil {
+			counts[template.ID]++
+			matched++
+		}
+	}
+	if matched == 0 {
+		return nil
+	}
+
+	baseline := m.total - matched
+	summaries := make([]hephaestus.LogTemplate, 0, len(counts))
+	for id, count := range counts {
+		template := m.templates[id]
+		summary := hephaestus.LogTemplate{
+			ID:      id,
+			Pattern: template.Pattern(),
+			Count:   count,
+			Total:   template.Count,
+			Share:   float64(count) / float64(matched),
+			New:     template.Count <= count,
+		}
+		if baseline > 0 && template.Count > count {
+			summary.BaselineShare = float64(template.Count-count) / float64(baseline)
+		}
+		summaries = append(summaries, summary)
+	}
+
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].Count != summaries[j].Count {
+			return summaries[i].Count > summaries[j].Count
+		}
+		return summaries[i].ID < summaries[j].ID
+	})
+	if limit > 0 && len(summaries) > limit {
+		summaries = summaries[:limit]
+	}
+	return summaries
+}
+
+// leaf walks the parse tree to the template group for tokens, creating missing nodes
+// when create is set. Callers must hold m.mu.
+func (m *Miner) leaf(tokens []string, create bool) *treeNode {
+	current, ok := m.root[len(tokens)]
+	if !ok {
+		if !create {
+			return nil
+		}
+		current = newTreeNode(nil, "")
+		m.root[len(tokens)] = current
+	}
+
+	for i := 0; i < m.config.Depth-2 && i < len(tokens); i++ {
+		key := routingKey(tokens[i])
+		if next, ok := current.children[key]; ok {
+			current = next
+			continue
+		}
+
+		// Unknown tokens get their own child while there is room, afterwards they share the wildcard child
+		if !create {
+			key = Wildcard
+		} else if len(current.children) >= m.config.MaxChildren {
+			key = Wildcard
+		}
+		next, ok := current.children[key]
+		if !ok {
+			if !create {
+				return nil
+			}
+			next = newTreeNode(current, key)
+			current.children[key] = next
+		}
+		current = next
+	}
+	return current
+}
+
+// bestMatch returns the most similar template in a group above the threshold.
+// Ties prefer the template with more parameters. Callers must hold m.mu.
+func (m *Miner) bestMatch(leaf *treeNode, tokens []string) *Template {
+	var best *Template
+	bestSimilarity, bestParams := -1.0, -1
+	for _, template := range leaf.templates {
+		similarity, params := similarity(template.Tokens, tokens)
+		if similarity > bestSimilarity || (similarity == bestSimilarity && params > bestParams) {
+			best, bestSimilarity, bestParams = template, similarity, params
+		}
+	}
+	if best == nil || bestSimilarity < m.config.SimilarityThreshold {
+		return nil
+	}
+	return best
+}
+
+// evict removes the least recently seen template other than keep along with the tree
+// nodes left empty, and stops counting its messages. Callers must hold m.mu.
+func (m *Miner) evict(keep int) {
+	var oldest *Template
+	for id, template := range m.templates {
+		if id == keep {
+			continue
+		}
+		if oldest == nil || template.LastSeen.Before(oldest.LastSeen) {
+			oldest = template
+		}
+	}
+	if oldest == nil {
+		return
+	}
+
+	leaf := m.leaves[oldest.ID]
+	for i, template := range leaf.templates {
+		if template == oldest {
+			leaf.templates = append(leaf.templates[:i], leaf.templates[i+1:]...)
+			break
+		}
+	}
+	delete(m.templates, oldest.ID)
+	delete(m.leaves, oldest.ID)
+	m.total -= oldest.Count
+
+	// Prune the branch up to the first node still in use so its slots can be reused
+	node := leaf
+	for len(node.templates) == 0 && len(node.children) == 0 {
+		if node.parent == nil {
+			delete(m.root, len(oldest.Tokens))
+			break
+		}
+		delete(node.parent.children, node.key)
+		node = node.parent
+	}
+}
+
+// similarity returns the share of template tokens equal to the message tokens and the
+// number of parameters in the template
+func similarity(template, tokens []string) (float64, int) {
+	if len(template) == 0 {
+		return 1, 0
+	}
+
+	equal, params := 0, 0
+	for i, token := range template {
+		if token == Wildcard {
+			params++
+			continue
+		}
+		if token == tokens[i] {
+			equal++
+		}
+	}
+	return float64(equal) / float64(len(template)), params
+}
+
+// matchesConstants reports whether every constant template token equals the message
+// token at its position and returns the number of parameters in the template
+func matchesConstants(template, tokens []string) (int, bool) {
+	params := 0
+	for i, token := range template {
+		if token == Wildcard {
+			params++
+			continue
+		}
+		if token != tokens[i] {
+			return 0, false
+		}
+	}
+	return params, true
+}
+
+// tokenize splits a message into tokens with volatile values masked as parameters
+func tokenize(message string) []string {
+	tokens := strings.Fields(fingerprint.Normalize(message))
+	for i, token := range tokens {
+		switch token {
+		case fingerprint.TimestampPlaceholder, fingerprint.UUIDPlaceholder, fingerprint.HexPlaceholder, fingerprint.NumberPlaceholder:
+			tokens[i] = Wildcard
+		}
+	}
+	return tokens
+}
+
+// routingKey returns the tree key for a token, parameters share the wildcard key
+func routingKey(token string) string {
+	if strings.Contains(token, "<") {
+		return Wildcard
+	}
+	return token
+}
+
+// copyTemplate returns a copy safe to hand out without the lock
+func copyTemplate(template *Template) Template {
+	copied := *template
+	copied.Tokens = append([]string(nil), template.Tokens...)
+	return copied
+}
@@ -0,0 +1,181 @@
+package pattern
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/HoyeonS/hephaestus/pkg/hephaestus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiner_Add(t *testing.T) {
+	tests := []struct {
+		name     string
+		messages []string
+		want     []string
+	}{
+		{
+			name: "numbers become parameters",
+			messages: []string{
+				"connected to db in 12 ms",
+				"connected to db in 7 ms",
+			},
+			want: []string{"connected to db in <*> ms"},
+		},
+		{
+			name: "differing tokens become parameters",
+			messages: []string{
+				"login succeeded for alice",
+				"login succeeded for bob",
+				"login succeeded for carol",
+			},
+			want: []string{"login succeeded for <*>"},
+		},
+		{
+			name: "leading tokens route to separate groups",
+			messages: []string{
+				"user alice logged in",
+				"user bob logged in",
+			},
+			want: []string{"user alice logged in", "user bob logged in"},
+		},
+		{
+			name: "different shapes stay apart",
+			messages: []string{
+				"cache miss for key orders",
+				"payment declined by provider stripe",
+				"cache miss for key users",
+			},
+			want: []string{"cache miss for key <*>", "payment declined by provider stripe"},
+		},
+		{
+			name: "token count separates templates",
+			messages: []string{
+				"request failed",
+				"request failed with timeout",
+			},
+			want: []string{"request failed", "request failed with timeout"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			miner := NewMiner(Config{})
+			for _, message := range tt.messages {
+				miner.Add(message, time.Now())
+			}
+
+			var patterns []string
+			for _, template := range miner.Templates() {
+				patterns = append(patterns, template.Pattern())
+			}
+			assert.Equal(t, tt.want, patterns)
+		})
+	}
+}
+
+func TestMiner_Match(t *testing.T) {
+	miner := NewMiner(Config{})
+	miner.Add("login succeeded for alice", time.Now())
+	added := miner.Add("login succeeded for bob", time.Now())
+
+	matched, ok := miner.Match("login succeeded for dave")
+	require.True(t, ok)
+	assert.Equal(t, added.ID, matched.ID)
+	assert.Equal(t, 2, matched.Count, "match does not learn")
+
+	_, ok = miner.Match("disk full on /dev/sda1")
+	assert.False(t, ok)
+}
+
+func TestMiner_MaxTemplates(t *testing.T) {
+	miner := NewMiner(Config{MaxTemplates: 2})
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	miner.Add("alpha happened", base)
+	miner.Add("beta went wrong here", base.Add(time.Second))
+	miner.Add("gamma", base.Add(2*time.Second))
+
+	var patterns []string
+	for _, template := range miner.Templates() {
+		patterns = append(patterns, template.Pattern())
+	}
+	assert.Equal(t, []string{"beta went wrong here", "gamma"}, patterns)
+	_, ok := miner.Match("alpha happened")
+	assert.False(t, ok)
+}
+
+func TestMiner_EvictionPrunesTree(t *testing.T) {
+	miner := NewMiner(Config{MaxTemplates: 2, MaxChildren: 2})
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	miner.Add("alpha service started", base)
+	miner.Add("alpha service started", base)
+	miner.Add("beta service started", base.Add(time.Second))
+	miner.Add("gamma service started", base.Add(2*time.Second))
+
+	// The evicted branch is pruned and its messages no longer count
+	assert.NotContains(t, miner.root[3].children, "alpha")
+	assert.Contains(t, miner.root[3].children, "beta")
+	assert.Equal(t, 2, miner.total)
+
+	// Once every template of a token count is evicted its whole tree is gone
+	miner.Add("delta", base.Add(3*time.Second))
+	miner.Add("epsilon", base.Add(4*time.Second))
+	assert.NotContains(t, miner.root, 3)
+	assert.Equal(t, 2, miner.total)
+}
+
+func TestMiner_MaxChildren(t *testing.T) {
+	miner := NewMiner(Config{MaxChildren: 2})
+	for _, name := range []string{"alpha", "beta", "gamma", "delta"} {
+		miner.Add(name+" service started", time.Now())
+	}
+
+	// Once the first layer is full, later names share the wildcard branch and merge
+	var patterns []string
+	for _, template := range miner.Templates() {
+		patterns = append(patterns, template.Pattern())
+	}
+	assert.Equal(t, []string{"alpha service started", "beta service started", "<*> service started"}, patterns)
+}
+
+func TestMiner_Summarize(t *testing.T) {
+	miner := NewMiner(Config{})
+
+	var batch []hephaestus.LogEntry
+	for i := 0; i < 8; i++ {
+		miner.Add(fmt.Sprintf("served request in %d ms", i), time.Now())
+	}
+	for i := 0; i < 2; i++ {
+		entry := hephaestus.LogEntry{Message: fmt.Sprintf("served request in %d ms", i)}
+		miner.Add(entry.Message, time.Now())
+		batch = append(batch, entry)
+	}
+	for i := 0; i < 3; i++ {
+		entry := hephaestus.LogEntry{Message: fmt.Sprintf("connection reset by peer %d", i)}
+		miner.Add(entry.Message, time.Now())
+		batch = append(batch, entry)
+	}
+	batch = append(batch, hephaestus.LogEntry{Message: "never mined"})
+
+	summaries := miner.Summarize(batch, 0)
+	require.Len(t, summaries, 2)
+
+	assert.Equal(t, "connection reset by peer <*>", summaries[0].Pattern)
+	assert.Equal(t, 3, summaries[0].Count)
+	assert.True(t, summaries[0].New)
+	assert.InDelta(t, 0.6, summaries[0].Share, 1e-9)
+	assert.Zero(t, summaries[0].BaselineShare)
+
+	assert.Equal(t, "served request in <*> ms", summaries[1].Pattern)
+	assert.Equal(t, 2, summaries[1].Count)
+	assert.Equal(t, 10, summaries[1].Total)
+	assert.False(t, summaries[1].New)
+	assert.InDelta(t, 1.0, summaries[1].BaselineShare, 1e-9)
+
+	assert.Len(t, miner.Summarize(batch, 1), 1)
+	assert.Nil(t, miner.Summarize([]hephaestus.LogEntry{{Message: "never mined"}}, 0))
+}
@@ -51,6 +51,12 @@ var (
 
 	// ErrOperationTimeout indicates operation is timed out
 	ErrOperationTimeout = errors.New("operation timed out error")
+
+	// ErrInvalidTransition indicates a node status transition outside the lifecycle
+	ErrInvalidTransition = errors.New("invalid status transition")
+
+	// ErrTruncated indicates a listing too large to be returned whole
+	ErrTruncated = errors.New("listing truncated")
 )
 
 // ModelError represents a model provider error
@@ -99,6 +105,47 @@ func (e *NodeError) Unwrap() error {
 	return e.Err
 }
 
+// LogEntryError reports a log entry of a batch that could not be processed
+type LogEntryError struct {
+	// Index is the position of the entry in the submitted batch
+	Index int
+	Err   error
+}
+
+func (e *LogEntryError) Error() string {
+	return fmt.Sprintf("log entry %d: %v", e.Index, e.Err)
+}
+
+func (e *LogEntryError) Unwrap() error {
+	return e.Err
+}
+
+// BatchError reports the entries of a log batch that failed while the rest were processed
+type BatchError struct {
+	Total    int
+	Failures []*LogEntryError
+	// Dropped is the number of entries skipped by sampling or rate limiting
+	Dropped int
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("%d of %d log entries failed, first: %v", len(e.Failures), e.Total, e.Failures[0])
+}
+
+func (e *BatchError) Unwrap() []error {
+	errs := make([]error, len(e.Failures))
+	for i, failure := range e.Failures {
+		errs[i] = failure
+	}
+	return errs
+}
+
+// Accepted returns the number of batch entries that were processed, neither failed
+// nor dropped
+func (e *BatchError) Accepted() int {
+	return e.Total - len(e.Failures) - e.Dropped
+}
+
 // IsProviderError checks if the error is from an external provider
 func IsProviderError(err error) bool {
 	var modelErr *ModelError
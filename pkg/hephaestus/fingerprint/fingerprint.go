@@ -0,0 +1,87 @@
+// Package fingerprint derives stable identifiers for incidents from log entries.
+// Two entries share a fingerprint when their messages only differ in volatile values
+// such as numbers, UUIDs, hex IDs or timestamps and their top stack frames match.
+package fingerprint
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"strings"
+
+	"github.com/HoyeonS/hephaestus/pkg/hephaestus"
+	"github.com/HoyeonS/hephaestus/pkg/hephaestus/stacktrace"
+)
+
+// DefaultFrameDepth is the number of top stack frames included in a fingerprint
+const DefaultFrameDepth = 3
+
+// Placeholders substituted for volatile values
+const (
+	TimestampPlaceholder = "<ts>"
+	UUIDPlaceholder      = "<uuid>"
+	HexPlaceholder       = "<hex>"
+	NumberPlaceholder    = "<num>"
+)
+
+var (
+	timestampPattern = regexp.MustCompile(`\d{4}-\d{2}-\d{2}(?:[T ]\d{2}:\d{2}:\d{2}(?:[.,]\d+)?(?:Z|[+-]\d{2}:?\d{2})?)?|\b\d{2}:\d{2}:\d{2}(?:[.,]\d+)?\b`)
+	uuidPattern      = regexp.MustCompile(`(?i)\b[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\b`)
+	hexPattern       = regexp.MustCompile(`(?i)\b0x[0-9a-f]+\b|\b[0-9a-f]{8,}\b`)
+	numberPattern    = regexp.MustCompile(`\d+(?:\.\d+)?`)
+	spacePattern     = regexp.MustCompile(`\s+`)
+)
+
+// Normalize masks the volatile values in a message so occurrences of the same
+// problem compare equal
+func Normalize(message string) string {
+	normalized := timestampPattern.ReplaceAllString(message, TimestampPlaceholder)
+	normalized = uuidPattern.ReplaceAllString(normalized, UUIDPlaceholder)
+	normalized = hexPattern.ReplaceAllStringFunc(normalized, func(match string) string {
+		lower := strings.ToLower(match)
+		if strings.HasPrefix(lower, "0x") {
+			return HexPlaceholder
+		}
+		// Plain words made of hex letters and plain numbers are not IDs
+		if !strings.ContainsAny(lower, "0123456789") || !strings.ContainsAny(lower, "abcdef") {
+			return match
+		}
+		return HexPlaceholder
+	})
+	normalized = numberPattern.ReplaceAllString(normalized, NumberPlaceholder)
+	return strings.TrimSpace(spacePattern.ReplaceAllString(normalized, " "))
+}
+
+// Frames returns the symbols of up to depth frames from the top of an error trace.
+// Symbols leave out file lines so they survive line shifts between releases.
+func Frames(trace string, depth int) []string {
+	if depth <= 0 {
+		return nil
+	}
+
+	var frames []string
+	for _, frame := range stacktrace.Parse(trace).Frames() {
+		frames = append(frames, frame.Symbol())
+		if len(frames) == depth {
+			break
+		}
+	}
+	return frames
+}
+
+// Compute returns the fingerprint of a log entry from its normalized message and
+// its top stack frames
+func Compute(entry hephaestus.LogEntry) string {
+	return FromParts(Normalize(entry.Message), Frames(entry.ErrorTrace, DefaultFrameDepth))
+}
+
+// FromParts hashes a normalized message and stack frames into a fingerprint
+func FromParts(message string, frames []string) string {
+	hash := sha256.New()
+	hash.Write([]byte(message))
+	for _, frame := range frames {
+		hash.Write([]byte{'\n'})
+		hash.Write([]byte(frame))
+	}
+	return hex.EncodeToString(hash.Sum(nil))[:16]
+}
@@ -0,0 +1,127 @@
+package fingerprint
+
+import (
+	"testing"
+
+	"github.com/HoyeonS/hephaestus/pkg/hephaestus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    string
+	}{
+		{
+			name:    "numbers",
+			message: "retry 3 of 5 failed after 1.5s",
+			want:    "retry <num> of <num> failed after <num>s",
+		},
+		{
+			name:    "uuid",
+			message: "order 3f2504e0-4f89-11d3-9a0c-0305e82c3301 not found",
+			want:    "order <uuid> not found",
+		},
+		{
+			name:    "hex ids",
+			message: "object 5f3a9c2be81d at 0xc000123abc released",
+			want:    "object <hex> at <hex> released",
+		},
+		{
+			name:    "timestamps",
+			message: "lease expired at 2024-01-02T15:04:05.123Z (checked 15:04:06)",
+			want:    "lease expired at <ts> (checked <ts>)",
+		},
+		{
+			name:    "hex letter words are kept",
+			message: "deadbeef  facade\tfailed",
+			want:    "deadbeef facade failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Normalize(tt.message))
+		})
+	}
+}
+
+func TestFrames(t *testing.T) {
+	tests := []struct {
+		name  string
+		trace string
+		depth int
+		want  []string
+	}{
+		{
+			name: "go",
+			trace: "goroutine 1 [running]:\n" +
+				"main.(*Server).handle(0xc000010000, 0x1)\n" +
+				"\t/app/server.go:42 +0x1d\n" +
+				"main.main()\n" +
+				"\t/app/main.go:10 +0x25\n",
+			depth: 3,
+			want:  []string{"main.(*Server).handle", "main.main"},
+		},
+		{
+			name: "java",
+			trace: "java.lang.NullPointerException: boom\n" +
+				"\tat com.acme.Orders.place(Orders.java:17)\n" +
+				"\tat com.acme.Api.post(Api.java:88)\n" +
+				"\tat com.acme.Main.main(Main.java:5)\n",
+			depth: 2,
+			want:  []string{"com.acme.Orders.place", "com.acme.Api.post"},
+		},
+		{
+			name: "python",
+			trace: "Traceback (most recent call last):\n" +
+				"  File \"app.py\", line 12, in handler\n" +
+				"    charge(order)\n" +
+				"  File \"billing.py\", line 40, in charge\n" +
+				"ValueError: bad amount\n",
+			depth: 3,
+			want:  []string{"billing.charge", "app.handler"},
+		},
+		{
+			name: "javascript",
+			trace: "TypeError: x is undefined\n" +
+				"    at render (/app/view.js:10:5)\n" +
+				"    at /app/index.js:3:1\n",
+			depth: 3,
+			want:  []string{"render", "/app/index.js"},
+		},
+		{
+			name:  "no depth",
+			trace: "main.main()\n",
+			depth: 0,
+			want:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Frames(tt.trace, tt.depth))
+		})
+	}
+}
+
+func TestCompute(t *testing.T) {
+	first := hephaestus.LogEntry{
+		Message:    "user 42 request 3f2504e0-4f89-11d3-9a0c-0305e82c3301 timed out",
+		ErrorTrace: "main.handle(0x1)\n\t/app/main.go:10 +0x1d\n",
+	}
+	second := hephaestus.LogEntry{
+		Message:    "user 7 request 9b2e4c10-1a2b-4c3d-8e9f-001122334455 timed out",
+		ErrorTrace: "main.handle(0x2)\n\t/app/main.go:12 +0x2f\n",
+	}
+	otherFrame := hephaestus.LogEntry{
+		Message:    first.Message,
+		ErrorTrace: "main.serve(0x1)\n\t/app/main.go:10 +0x1d\n",
+	}
+
+	assert.Len(t, Compute(first), 16)
+	assert.Equal(t, Compute(first), Compute(second))
+	assert.NotEqual(t, Compute(first), Compute(otherFrame))
+	assert.NotEqual(t, Compute(first), Compute(hephaestus.LogEntry{Message: "disk full"}))
+}
@@ -0,0 +1,84 @@
+package hephaestus
+
+import (
+	"context"
+	"time"
+)
+
+// SolutionHandler consumes solutions generated by a node
+type SolutionHandler interface {
+	HandleSolution(ctx context.Context, solution *Solution) error
+}
+
+// SolutionHandlerFunc adapts an ordinary function to a SolutionHandler
+type SolutionHandlerFunc func(ctx context.Context, solution *Solution) error
+
+// HandleSolution calls f(ctx, solution)
+func (f SolutionHandlerFunc) HandleSolution(ctx context.Context, solution *Solution) error {
+	return f(ctx, solution)
+}
+
+// RemoteRepositoryService applies solution changes to a remote repository
+type RemoteRepositoryService interface {
+	// CreatePullRequest commits the changes to a new branch, opens a pull request and returns its URL
+	CreatePullRequest(ctx context.Context, title, body string, changes []Change) (string, error)
+	// ListFiles returns the file paths in the repository relative to the base directory,
+	// or ErrTruncated when the repository is too large to list
+	ListFiles(ctx context.Context) ([]string, error)
+	// FetchFiles returns the contents of the files keyed by path, missing files are left out
+	FetchFiles(ctx context.Context, paths []string) (map[string]string, error)
+}
+
+// MetricsCollectionService records node level metrics
+type MetricsCollectionService interface {
+	// InitializeNodeMetrics starts tracking a node, the other methods fail for untracked nodes
+	InitializeNodeMetrics(ctx context.Context, nodeID string) error
+	// CleanupNodeMetrics stops tracking a node and removes its series
+	CleanupNodeMetrics(ctx context.Context, nodeID string) error
+	// RecordChannelDrop records an item dropped from a node output channel
+	RecordChannelDrop(ctx context.Context, nodeID, channel, reason string) error
+	// RecordIncident records a solution generated for an incident and its occurrences
+	RecordIncident(ctx context.Context, nodeID, fingerprint string, occurrences int) error
+	// RecordIngestionDrop records a log entry dropped by sampling or rate limiting
+	RecordIngestionDrop(ctx context.Context, nodeID, level, reason string) error
+	// RecordNodeStatusChange records a node lifecycle transition to status
+	RecordNodeStatusChange(ctx context.Context, nodeID string, status string) error
+}
+
+// LogStore is a persisted log store searched for entries correlated with a trigger
+type LogStore interface {
+	// FindCorrelated returns the stored entries whose context value for key equals value
+	FindCorrelated(ctx context.Context, key, value string) ([]LogEntry, error)
+}
+
+// ClientNode is a client node hosted by a NodeManager
+type ClientNode interface {
+	Start(ctx context.Context) error
+	Stop(ctx context.Context) error
+	Status() NodeStatus
+	ProcessLog(entry LogEntry) error
+	ProcessLogs(entries []LogEntry) (BatchResult, error)
+	GetSolutions() <-chan *Solution
+	GetErrors() <-chan error
+	Subscribe(buffer int) (<-chan NodeStatusEvent, func())
+}
+
+// NodeInfo describes a node hosted by a NodeManager
+type NodeInfo struct {
+	NodeID       string        `json:"node_id"`
+	Mode         OperationMode `json:"mode"`
+	Status       NodeStatus    `json:"status"`
+	RegisteredAt time.Time     `json:"registered_at"`
+}
+
+// NodeManager hosts many client nodes in one process keyed by node ID
+type NodeManager interface {
+	// RegisterNode creates and starts a node from its configuration
+	RegisterNode(ctx context.Context, config *ClientNodeConfiguration) (ClientNode, error)
+	// DeregisterNode stops a node, draining its in-flight flows within ctx, and removes it
+	DeregisterNode(ctx context.Context, nodeID string) error
+	// GetNode returns a registered node
+	GetNode(nodeID string) (ClientNode, error)
+	// ListNodes returns the registered nodes ordered by node ID
+	ListNodes() []NodeInfo
+}
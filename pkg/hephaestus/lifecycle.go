@@ -0,0 +1,45 @@
+package hephaestus
+
+import (
+	"fmt"
+	"time"
+)
+
+// nodeStatusTransitions lists the statuses each status may move to.
+//
+//	initializing → operational ⇄ processing → draining → stopped
+//
+// Running nodes may become degraded and recover, and any live node may fail into
+// error. Stopping is always possible and stopped is final.
+var nodeStatusTransitions = map[NodeStatus][]NodeStatus{
+	NodeStatusInitializing: {NodeStatusOperational, NodeStatusDraining, NodeStatusError},
+	NodeStatusOperational:  {NodeStatusProcessing, NodeStatusDegraded, NodeStatusDraining, NodeStatusError},
+	NodeStatusProcessing:   {NodeStatusOperational, NodeStatusDegraded, NodeStatusDraining, NodeStatusError},
+	NodeStatusDegraded:     {NodeStatusOperational, NodeStatusProcessing, NodeStatusDraining, NodeStatusError},
+	NodeStatusDraining:     {NodeStatusStopped, NodeStatusError},
+	NodeStatusError:        {NodeStatusOperational, NodeStatusDraining},
+	NodeStatusStopped:      {},
+}
+
+// ValidateNodeStatusTransition checks that a node may move from one status to another
+func ValidateNodeStatusTransition(from, to NodeStatus) error {
+	allowed, known := nodeStatusTransitions[from]
+	if !known {
+		return fmt.Errorf("%w: unknown status %q", ErrInvalidTransition, from)
+	}
+	for _, status := range allowed {
+		if status == to {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s to %s", ErrInvalidTransition, from, to)
+}
+
+// NodeStatusEvent describes a node status transition
+type NodeStatusEvent struct {
+	NodeID    string     `json:"node_id"`
+	From      NodeStatus `json:"from"`
+	To        NodeStatus `json:"to"`
+	Reason    string     `json:"reason"`
+	Timestamp time.Time  `json:"timestamp"`
+}
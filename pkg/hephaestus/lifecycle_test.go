@@ -0,0 +1,40 @@
+package hephaestus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateNodeStatusTransition(t *testing.T) {
+	tests := []struct {
+		from    NodeStatus
+		to      NodeStatus
+		wantErr bool
+	}{
+		{from: NodeStatusInitializing, to: NodeStatusOperational},
+		{from: NodeStatusOperational, to: NodeStatusProcessing},
+		{from: NodeStatusProcessing, to: NodeStatusOperational},
+		{from: NodeStatusProcessing, to: NodeStatusDraining},
+		{from: NodeStatusOperational, to: NodeStatusDegraded},
+		{from: NodeStatusDegraded, to: NodeStatusOperational},
+		{from: NodeStatusDraining, to: NodeStatusStopped},
+		{from: NodeStatusError, to: NodeStatusDraining},
+		{from: NodeStatusInitializing, to: NodeStatusProcessing, wantErr: true},
+		{from: NodeStatusDraining, to: NodeStatusOperational, wantErr: true},
+		{from: NodeStatusOperational, to: NodeStatusStopped, wantErr: true},
+		{from: NodeStatusStopped, to: NodeStatusOperational, wantErr: true},
+		{from: NodeStatus("paused"), to: NodeStatusOperational, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.from)+"->"+string(tt.to), func(t *testing.T) {
+			err := ValidateNodeStatusTransition(tt.from, tt.to)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidTransition)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package hephaestus
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Severity represents a normalized log severity. Higher values are more severe.
+type Severity int
+
+const (
+	SeverityUnknown Severity = iota
+	SeverityTrace
+	SeverityDebug
+	SeverityInfo
+	SeverityWarn
+	SeverityError
+	SeverityFatal
+	SeverityPanic
+)
+
+// DefaultThresholdSeverity is used when no threshold level is configured
+const DefaultThresholdSeverity = SeverityError
+
+var severityNames = map[Severity]string{
+	SeverityUnknown: "unknown",
+	SeverityTrace:   "trace",
+	SeverityDebug:   "debug",
+	SeverityInfo:    "info",
+	SeverityWarn:    "warn",
+	SeverityError:   "error",
+	SeverityFatal:   "fatal",
+	SeverityPanic:   "panic",
+}
+
+// severityAliases maps lower-cased level names used by common loggers onto the ladder
+var severityAliases = map[string]Severity{
+	"trace":         SeverityTrace,
+	"trc":           SeverityTrace,
+	"finest":        SeverityTrace,
+	"verbose":       SeverityTrace,
+	"debug":         SeverityDebug,
+	"dbg":           SeverityDebug,
+	"fine":          SeverityDebug,
+	"finer":         SeverityDebug,
+	"info":          SeverityInfo,
+	"inf":           SeverityInfo,
+	"information":   SeverityInfo,
+	"informational": SeverityInfo,
+	"notice":        SeverityInfo,
+	"warn":          SeverityWarn,
+	"wrn":           SeverityWarn,
+	"warning":       SeverityWarn,
+	"error":         SeverityError,
+	"err":           SeverityError,
+	"eror":          SeverityError,
+	"severe":        SeverityError,
+	"dpanic":        SeverityError,
+	"fatal":         SeverityFatal,
+	"crit":          SeverityFatal,
+	"critical":      SeverityFatal,
+	"alert":         SeverityFatal,
+	"panic":         SeverityPanic,
+	"emerg":         SeverityPanic,
+	"emergency":     SeverityPanic,
+}
+
+// String returns the canonical name of the severity
+func (s Severity) String() string {
+	if name, ok := severityNames[s]; ok {
+		return name
+	}
+	return severityNames[SeverityUnknown]
+}
+
+// AtLeast reports whether the severity is known and at or above the threshold
+func (s Severity) AtLeast(threshold Severity) bool {
+	return s != SeverityUnknown && s >= threshold
+}
+
+// ParseSeverity normalizes a level string onto the severity ladder.
+// Level names are matched case-insensitively and numeric values are read as syslog severities.
+func ParseSeverity(level string) (Severity, error) {
+	normalized := strings.ToLower(strings.TrimSpace(level))
+	if severity, ok := severityAliases[normalized]; ok {
+		return severity, nil
+	}
+
+	if code, err := strconv.Atoi(normalized); err == nil {
+		if severity := SeverityFromSyslog(code); severity != SeverityUnknown {
+			return severity, nil
+		}
+	}
+
+	return SeverityUnknown, fmt.Errorf("%w: unknown log level %q", ErrInvalidArgument, level)
+}
+
+// SeverityFromSyslog maps an RFC 5424 severity code (0-7) onto the severity ladder
+func SeverityFromSyslog(code int) Severity {
+	switch code {
+	case 0:
+		return SeverityPanic
+	case 1, 2:
+		return SeverityFatal
+	case 3:
+		return SeverityError
+	case 4:
+		return SeverityWarn
+	case 5, 6:
+		return SeverityInfo
+	case 7:
+		return SeverityDebug
+	default:
+		return SeverityUnknown
+	}
+}
@@ -0,0 +1,48 @@
+package hephaestus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSeverity(t *testing.T) {
+	tests := []struct {
+		level   string
+		want    Severity
+		wantErr bool
+	}{
+		{level: "error", want: SeverityError},
+		{level: "ERR", want: SeverityError},
+		{level: " Warning ", want: SeverityWarn},
+		{level: "CRITICAL", want: SeverityFatal},
+		{level: "panic", want: SeverityPanic},
+		{level: "notice", want: SeverityInfo},
+		{level: "0", want: SeverityPanic},
+		{level: "3", want: SeverityError},
+		{level: "7", want: SeverityDebug},
+		{level: "8", want: SeverityUnknown, wantErr: true},
+		{level: "loud", want: SeverityUnknown, wantErr: true},
+		{level: "", want: SeverityUnknown, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.level, func(t *testing.T) {
+			got, err := ParseSeverity(tt.level)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidArgument)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSeverityAtLeast(t *testing.T) {
+	assert.True(t, SeverityFatal.AtLeast(SeverityError))
+	assert.True(t, SeverityError.AtLeast(SeverityError))
+	assert.False(t, SeverityWarn.AtLeast(SeverityError))
+	assert.False(t, SeverityUnknown.AtLeast(SeverityUnknown))
+	assert.Equal(t, "warn", SeverityWarn.String())
+}
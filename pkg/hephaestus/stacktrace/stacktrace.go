@@ -0,0 +1,379 @@
+// Package stacktrace parses error traces into typed frames.
+// Go panics and goroutine dumps, Java exceptions with their cause chains, Python
+// tracebacks and V8 (Node.js) stack traces are recognized. Traces in any other format
+// parse to an empty trace of unknown language rather than failing.
+package stacktrace
+
+import (
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Language identifies the runtime that produced a trace
+type Language string
+
+const (
+	LanguageUnknown    Language = "unknown"
+	LanguageGo         Language = "go"
+	LanguageJava       Language = "java"
+	LanguagePython     Language = "python"
+	LanguageJavaScript Language = "javascript"
+)
+
+// Frame is a single call site in a trace
+type Frame struct {
+	// Function is the function or method name without its module
+	Function string `json:"function,omitempty"`
+	// Module is the Go package path, Java class, or Python module the function belongs to
+	Module string `json:"module,omitempty"`
+	// File is the source file path as reported by the runtime. Java files are
+	// qualified with their package directories.
+	File   string `json:"file,omitempty"`
+	Line   int    `json:"line,omitempty"`
+	Column int    `json:"column,omitempty"`
+}
+
+// Symbol returns the location independent identity of the frame
+func (f Frame) Symbol() string {
+	switch {
+	case f.Function != "" && f.Module != "":
+		return f.Module + "." + f.Function
+	case f.Function != "":
+		return f.Function
+	default:
+		return f.File
+	}
+}
+
+// Section is one exception of a cause chain or one goroutine of a dump.
+// Frames are ordered innermost call first.
+type Section struct {
+	Title  string  `json:"title,omitempty"`
+	Frames []Frame `json:"frames,omitempty"`
+}
+
+// Trace is a parsed error trace. The first section is the error that surfaced,
+// later sections are its causes or the other goroutines.
+type Trace struct {
+	Language Language  `json:"language"`
+	Message  string    `json:"message,omitempty"`
+	Sections []Section `json:"sections,omitempty"`
+}
+
+// Frames returns the frames of every section in order
+func (t *Trace) Frames() []Frame {
+	var frames []Frame
+	for _, section := range t.Sections {
+		frames = append(frames, section.Frames...)
+	}
+	return frames
+}
+
+var (
+	goLocationPattern = regexp.MustCompile(`^(\S+\.go):(\d+)(?: \+0x[0-9a-f]+)?$`)
+	goHeaderPattern   = regexp.MustCompile(`^goroutine \d+ \[[^\]]+\]:$`)
+	goCreatedPattern  = regexp.MustCompile(`^created by (\S+?)(?: in goroutine \d+)?$`)
+
+	javaFramePattern    = regexp.MustCompile(`^at (?:[\w.$-]+/)*([\w.$<>]+)\.([\w$<>]+)\(([^)]*)\)$`)
+	javaLocationPattern = regexp.MustCompile(`\(\S+\.(?:java|kt|scala|groovy):\d+\)|\((?:Native Method|Unknown Source)\)`)
+
+	pythonFramePattern = regexp.MustCompile(`^File "([^"]+)", line (\d+)(?:, in (.+))?$`)
+
+	jsFramePattern         = regexp.MustCompile(`^at (?:async )?(?:new )?(.+?) \((.+?)(?::(\d+))?(?::(\d+))?\)$`)
+	jsBareFramePattern     = regexp.MustCompile(`^at (?:async )?(.+?):(\d+):(\d+)$`)
+	jsLocationPattern      = regexp.MustCompile(`^\s*at .+:\d+:\d+\)?$`)
+	pythonTracebackPattern = regexp.MustCompile(`(?m)^Traceback \(most recent call last\):$|^\s*File "[^"]+", line \d+`)
+)
+
+// Parse parses an error trace. It never fails, unknown formats yield a trace of
+// unknown language with the first line as its message and no frames.
+func Parse(trace string) *Trace {
+	lines := strings.Split(strings.ReplaceAll(trace, "\r\n", "\n"), "\n")
+
+	switch Detect(trace) {
+	case LanguageGo:
+		return parseGo(lines)
+	case LanguageJava:
+		return parseJava(lines)
+	case LanguagePython:
+		return parsePython(lines)
+	case LanguageJavaScript:
+		return parseJavaScript(lines)
+	default:
+		return &Trace{Language: LanguageUnknown, Message: firstLine(lines)}
+	}
+}
+
+// Detect guesses the language that produced a trace
+func Detect(trace string) Language {
+	var goHint, javaHint, jsHint bool
+	if pythonTracebackPattern.MatchString(trace) {
+		return LanguagePython
+	}
+	for _, line := range strings.Split(trace, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case goHeaderPattern.MatchString(line), goLocationPattern.MatchString(line):
+			goHint = true
+		case javaLocationPattern.MatchString(line):
+			javaHint = true
+		case jsLocationPattern.MatchString(line):
+			jsHint = true
+		}
+	}
+
+	switch {
+	case goHint:
+		return LanguageGo
+	case javaHint:
+		return LanguageJava
+	case jsHint:
+		return LanguageJavaScript
+	default:
+		return LanguageUnknown
+	}
+}
+
+// parseGo parses a Go panic or goroutine dump
+func parseGo(lines []string) *Trace {
+	t := &Trace{Language: LanguageGo}
+	var current *Section
+	var pending string
+
+	for _, raw := range lines {
+		line := strings.TrimSpace(raw)
+		switch {
+		case line == "":
+			continue
+		case goHeaderPattern.MatchString(line):
+			t.Sections = append(t.Sections, Section{Title: strings.TrimSuffix(line, ":")})
+			current = &t.Sections[len(t.Sections)-1]
+			pending = ""
+		case strings.HasPrefix(line, "panic: ") || strings.HasPrefix(line, "fatal error: "):
+			if t.Message == "" {
+				t.Message = line
+			}
+		case goLocationPattern.MatchString(line):
+			if pending == "" {
+				continue
+			}
+			match := goLocationPattern.FindStringSubmatch(line)
+			number, _ := strconv.Atoi(match[2])
+			module, function := splitGoFunction(pending)
+			frame := Frame{Function: function, Module: module, File: match[1], Line: number}
+			if current == nil {
+				t.Sections = append(t.Sections, Section{})
+				current = &t.Sections[len(t.Sections)-1]
+			}
+			current.Frames = append(current.Frames, frame)
+			pending = ""
+		default:
+			if match := goCreatedPattern.FindStringSubmatch(line); match != nil {
+				pending = match[1]
+			} else if i := strings.LastIndex(line, "("); i > 0 && strings.HasSuffix(line, ")") {
+				pending = line[:i]
+			} else {
+				pending = ""
+			}
+		}
+	}
+
+	if t.Message == "" {
+		t.Message = firstLine(lines)
+	}
+	return t
+}
+
+// splitGoFunction splits a qualified Go function into its package path and name
+func splitGoFunction(qualified string) (string, string) {
+	slash := strings.LastIndex(qualified, "/")
+	dot := strings.Index(qualified[slash+1:], ".")
+	if dot < 0 {
+		return "", qualified
+	}
+	dot += slash + 1
+	return qualified[:dot], qualified[dot+1:]
+}
+
+// parseJava parses a Java exception with its "Caused by" and "Suppressed" chain
+func parseJava(lines []string) *Trace {
+	t := &Trace{Language: LanguageJava}
+	var current *Section
+
+	for _, raw := range lines {
+		line := strings.TrimSpace(raw)
+		switch {
+		case line == "", strings.HasPrefix(line, "..."):
+			continue
+		case strings.HasPrefix(line, "at "):
+			match := javaFramePattern.FindStringSubmatch(line)
+			if match == nil {
+				continue
+			}
+			if current == nil {
+				t.Sections = append(t.Sections, Section{})
+				current = &t.Sections[len(t.Sections)-1]
+			}
+			current.Frames = append(current.Frames, javaFrame(match[1], match[2], match[3]))
+		default:
+			title := line
+			for _, prefix := range []string{"Caused by: ", "Suppressed: "} {
+				title = strings.TrimPrefix(title, prefix)
+			}
+			if strings.HasPrefix(title, "Exception in thread ") {
+				if i := strings.Index(title[len("Exception in thread \""):], "\" "); i >= 0 {
+					title = title[len("Exception in thread \"")+i+2:]
+				}
+			}
+			if t.Message == "" {
+				t.Message = title
+			}
+			t.Sections = append(t.Sections, Section{Title: title})
+			current = &t.Sections[len(t.Sections)-1]
+		}
+	}
+	return t
+}
+
+// javaFrame builds a frame from a class, method and source location
+func javaFrame(class, method, location string) Frame {
+	frame := Frame{Function: method, Module: class}
+
+	file, number, found := strings.Cut(location, ":")
+	if !strings.Contains(file, ".") {
+		// Native Method and Unknown Source carry no file
+		return frame
+	}
+	if found {
+		frame.Line, _ = strconv.Atoi(number)
+	}
+	if i := strings.LastIndex(class, "."); i > 0 {
+		file = strings.ReplaceAll(class[:i], ".", "/") + "/" + file
+	}
+	frame.File = file
+	return frame
+}
+
+// parsePython parses a Python traceback including chained exceptions
+func parsePython(lines []string) *Trace {
+	t := &Trace{Language: LanguagePython}
+	var sections []Section
+	var current *Section
+
+	for _, raw := range lines {
+		line := strings.TrimSpace(raw)
+		indented := strings.HasPrefix(raw, " ") || strings.HasPrefix(raw, "\t")
+		switch {
+		case line == "":
+			continue
+		case line == "Traceback (most recent call last):":
+			sections = append(sections, Section{})
+			current = &sections[len(sections)-1]
+		case strings.HasPrefix(line, "File \""):
+			match := pythonFramePattern.FindStringSubmatch(line)
+			if match == nil {
+				continue
+			}
+			if current == nil {
+				sections = append(sections, Section{})
+				current = &sections[len(sections)-1]
+			}
+			number, _ := strconv.Atoi(match[2])
+			module := strings.TrimSuffix(path.Base(match[1]), ".py")
+			current.Frames = append(current.Frames, Frame{Function: match[3], Module: module, File: match[1], Line: number})
+		case indented:
+			// Source line of the previous frame
+		case strings.HasPrefix(line, "During handling of the above exception"),
+			strings.HasPrefix(line, "The above exception was the direct cause"):
+			current = nil
+		default:
+			if current != nil && current.Title == "" {
+				current.Title = line
+			}
+		}
+	}
+
+	// Python prints the innermost call and the surfacing exception last
+	for i := len(sections) - 1; i >= 0; i-- {
+		section := sections[i]
+		for l, r := 0, len(section.Frames)-1; l < r; l, r = l+1, r-1 {
+			section.Frames[l], section.Frames[r] = section.Frames[r], section.Frames[l]
+		}
+		t.Sections = append(t.Sections, section)
+	}
+	if len(t.Sections) > 0 {
+		t.Message = t.Sections[0].Title
+	}
+	return t
+}
+
+// parseJavaScript parses a V8 stack trace
+func parseJavaScript(lines []string) *Trace {
+	t := &Trace{Language: LanguageJavaScript}
+	var current *Section
+
+	for _, raw := range lines {
+		line := strings.TrimSpace(raw)
+		switch {
+		case line == "":
+			continue
+		case strings.HasPrefix(line, "at "):
+			frame, ok := jsFrame(line)
+			if !ok {
+				continue
+			}
+			if current == nil {
+				t.Sections = append(t.Sections, Section{})
+				current = &t.Sections[len(t.Sections)-1]
+			}
+			current.Frames = append(current.Frames, frame)
+		default:
+			if t.Message == "" {
+				t.Message = line
+			}
+			if current == nil || len(current.Frames) > 0 {
+				t.Sections = append(t.Sections, Section{Title: line})
+				current = &t.Sections[len(t.Sections)-1]
+			}
+		}
+	}
+	return t
+}
+
+// jsFrame parses a V8 frame line
+func jsFrame(line string) (Frame, bool) {
+	if match := jsBareFramePattern.FindStringSubmatch(line); match != nil && !strings.Contains(match[1], " (") {
+		number, _ := strconv.Atoi(match[2])
+		column, _ := strconv.Atoi(match[3])
+		return Frame{File: trimFileScheme(match[1]), Line: number, Column: column}, true
+	}
+	match := jsFramePattern.FindStringSubmatch(line)
+	if match == nil {
+		return Frame{}, false
+	}
+
+	frame := Frame{Function: match[1]}
+	if match[3] != "" {
+		frame.File = trimFileScheme(match[2])
+		frame.Line, _ = strconv.Atoi(match[3])
+		frame.Column, _ = strconv.Atoi(match[4])
+	}
+	return frame, true
+}
+
+// trimFileScheme removes a file URL scheme from ES module paths
+func trimFileScheme(file string) string {
+	return strings.TrimPrefix(file, "file://")
+}
+
+// firstLine returns the first non-empty line
+func firstLine(lines []string) string {
+	for _, line := range lines {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
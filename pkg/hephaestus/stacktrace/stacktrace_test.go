@@ -0,0 +1,130 @@
+package stacktrace
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse_Go(t *testing.T) {
+	trace := "panic: runtime error: invalid memory address or nil pointer dereference\n" +
+		"[signal SIGSEGV: segmentation violation code=0x1 addr=0x0 pc=0x4a1b2c]\n" +
+		"\n" +
+		"goroutine 7 [running]:\n" +
+		"github.com/acme/shop/internal/orders.(*Service).Place(0x0, {0xc0000a2000, 0x5})\n" +
+		"\t/src/shop/internal/orders/service.go:42 +0x1d\n" +
+		"main.main()\n" +
+		"\t/src/shop/cmd/shop/main.go:18 +0x25\n" +
+		"\n" +
+		"goroutine 1 [chan receive]:\n" +
+		"main.wait(...)\n" +
+		"\t/src/shop/cmd/shop/main.go:30\n" +
+		"created by main.start in goroutine 1\n" +
+		"\t/src/shop/cmd/shop/main.go:25 +0x3c\n"
+
+	parsed := Parse(trace)
+	assert.Equal(t, LanguageGo, parsed.Language)
+	assert.Equal(t, "panic: runtime error: invalid memory address or nil pointer dereference", parsed.Message)
+	require.Len(t, parsed.Sections, 2)
+	assert.Equal(t, "goroutine 7 [running]", parsed.Sections[0].Title)
+	assert.Equal(t, []Frame{
+		{Function: "(*Service).Place", Module: "github.com/acme/shop/internal/orders", File: "/src/shop/internal/orders/service.go", Line: 42},
+		{Function: "main", Module: "main", File: "/src/shop/cmd/shop/main.go", Line: 18},
+	}, parsed.Sections[0].Frames)
+	assert.Equal(t, []Frame{
+		{Function: "wait", Module: "main", File: "/src/shop/cmd/shop/main.go", Line: 30},
+		{Function: "start", Module: "main", File: "/src/shop/cmd/shop/main.go", Line: 25},
+	}, parsed.Sections[1].Frames)
+	assert.Equal(t, "github.com/acme/shop/internal/orders.(*Service).Place", parsed.Frames()[0].Symbol())
+}
+
+func TestParse_Java(t *testing.T) {
+	trace := "Exception in thread \"main\" java.lang.IllegalStateException: order failed\n" +
+		"\tat com.acme.shop.Orders.place(Orders.java:17)\n" +
+		"\tat com.acme.shop.Api$Handler.post(Api.java:88)\n" +
+		"\tat java.base/java.lang.Thread.run(Thread.java:829)\n" +
+		"Caused by: java.sql.SQLException: connection refused\n" +
+		"\tat org.db.Pool.get(Native Method)\n" +
+		"\t... 3 more\n"
+
+	parsed := Parse(trace)
+	assert.Equal(t, LanguageJava, parsed.Language)
+	assert.Equal(t, "java.lang.IllegalStateException: order failed", parsed.Message)
+	require.Len(t, parsed.Sections, 2)
+	assert.Equal(t, []Frame{
+		{Function: "place", Module: "com.acme.shop.Orders", File: "com/acme/shop/Orders.java", Line: 17},
+		{Function: "post", Module: "com.acme.shop.Api$Handler", File: "com/acme/shop/Api.java", Line: 88},
+		{Function: "run", Module: "java.lang.Thread", File: "java/lang/Thread.java", Line: 829},
+	}, parsed.Sections[0].Frames)
+	assert.Equal(t, "java.sql.SQLException: connection refused", parsed.Sections[1].Title)
+	assert.Equal(t, []Frame{{Function: "get", Module: "org.db.Pool"}}, parsed.Sections[1].Frames)
+}
+
+func TestParse_Python(t *testing.T) {
+	trace := "Traceback (most recent call last):\n" +
+		"  File \"/app/db.py\", line 10, in connect\n" +
+		"    sock.connect(addr)\n" +
+		"ConnectionRefusedError: [Errno 111] Connection refused\n" +
+		"\n" +
+		"The above exception was the direct cause of the following exception:\n" +
+		"\n" +
+		"Traceback (most recent call last):\n" +
+		"  File \"/app/api.py\", line 5, in handler\n" +
+		"    charge(order)\n" +
+		"  File \"/app/billing.py\", line 40, in charge\n" +
+		"    connect()\n" +
+		"RuntimeError: charge failed\n"
+
+	parsed := Parse(trace)
+	assert.Equal(t, LanguagePython, parsed.Language)
+	assert.Equal(t, "RuntimeError: charge failed", parsed.Message)
+	require.Len(t, parsed.Sections, 2)
+	assert.Equal(t, []Frame{
+		{Function: "charge", Module: "billing", File: "/app/billing.py", Line: 40},
+		{Function: "handler", Module: "api", File: "/app/api.py", Line: 5},
+	}, parsed.Sections[0].Frames)
+	assert.Equal(t, "ConnectionRefusedError: [Errno 111] Connection refused", parsed.Sections[1].Title)
+	assert.Equal(t, "billing.charge", parsed.Frames()[0].Symbol())
+}
+
+func TestParse_JavaScript(t *testing.T) {
+	trace := "TypeError: Cannot read properties of undefined (reading 'id')\n" +
+		"    at render (/app/src/view.js:10:5)\n" +
+		"    at async Server.handle (file:///app/src/server.mjs:22:7)\n" +
+		"    at /app/src/index.js:3:1\n" +
+		"    at Array.forEach (<anonymous>)\n" +
+		"    at Module._compile (node:internal/modules/cjs/loader:1105:14)\n"
+
+	parsed := Parse(trace)
+	assert.Equal(t, LanguageJavaScript, parsed.Language)
+	assert.Equal(t, "TypeError: Cannot read properties of undefined (reading 'id')", parsed.Message)
+	require.Len(t, parsed.Sections, 1)
+	assert.Equal(t, []Frame{
+		{Function: "render", File: "/app/src/view.js", Line: 10, Column: 5},
+		{Function: "Server.handle", File: "/app/src/server.mjs", Line: 22, Column: 7},
+		{File: "/app/src/index.js", Line: 3, Column: 1},
+		{Function: "Array.forEach"},
+		{Function: "Module._compile", File: "node:internal/modules/cjs/loader", Line: 1105, Column: 14},
+	}, parsed.Sections[0].Frames)
+}
+
+func TestParse_Unknown(t *testing.T) {
+	tests := []struct {
+		name  string
+		trace string
+		want  string
+	}{
+		{name: "empty", trace: "", want: ""},
+		{name: "free text", trace: "\nsomething bad happened\nsee logs\n", want: "something bad happened"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed := Parse(tt.trace)
+			assert.Equal(t, LanguageUnknown, parsed.Language)
+			assert.Equal(t, tt.want, parsed.Message)
+			assert.Empty(t, parsed.Frames())
+		})
+	}
+}
@@ -2,6 +2,11 @@ package hephaestus
 
 import (
 	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
 	"time"
 )
 
@@ -12,6 +17,9 @@ type SystemConfiguration struct {
 
 	// Limit Settings
 	LimitConfiguration LimitConfiguration `json:"limit" yaml:"limit"`
+
+	// Syslog Receiver Settings
+	SyslogConfiguration SyslogConfiguration `json:"syslog" yaml:"syslog"`
 }
 
 // ModelConfiguration contains model settings
@@ -25,20 +33,269 @@ type ModelConfiguration struct {
 type LimitConfiguration struct {
 	LogChunkLimit      int `json:"log_chunk_limit" yaml:"log_chunk_limit"`
 	FileNodeCountLimit int `json:"file_node_count_limit" yaml:"file_node_count_limit"`
+	// LogChunkByteLimit bounds the total payload bytes buffered per node, zero disables the limit
+	LogChunkByteLimit int64 `json:"log_chunk_byte_limit" yaml:"log_chunk_byte_limit"`
+	// LogBatchLimit bounds the entries accepted in one batch, zero disables the limit
+	LogBatchLimit int `json:"log_batch_limit" yaml:"log_batch_limit"`
+}
+
+// SyslogConfiguration contains the syslog receiver settings. Messages are routed to
+// the node of the first matching route, or to the default node.
+type SyslogConfiguration struct {
+	// UDPAddress is the address to receive datagrams on, empty disables UDP
+	UDPAddress string `json:"udp_address" yaml:"udp_address"`
+	// TCPAddress is the address to accept connections on, empty disables TCP
+	TCPAddress string `json:"tcp_address" yaml:"tcp_address"`
+	// MaxMessageSize bounds the bytes of a message, zero uses 64 KiB
+	MaxMessageSize int `json:"max_message_size" yaml:"max_message_size"`
+	// MaxConnections bounds the open TCP connections, zero uses 1024
+	MaxConnections int `json:"max_connections" yaml:"max_connections"`
+	// IdleTimeout closes a TCP connection that sends no message for this long, zero
+	// uses 5 minutes
+	IdleTimeout time.Duration `json:"idle_timeout" yaml:"idle_timeout"`
+	Routes      []SyslogRoute `json:"routes" yaml:"routes"`
+	// DefaultNodeID receives the messages no route matches, empty drops them
+	DefaultNodeID string `json:"default_node_id" yaml:"default_node_id"`
+}
+
+// SyslogRoute sends the messages whose hostname and app-name match its glob patterns
+// to a node. An empty pattern matches every message.
+type SyslogRoute struct {
+	Hostname string `json:"hostname" yaml:"hostname"`
+	AppName  string `json:"app_name" yaml:"app_name"`
+	NodeID   string `json:"node_id" yaml:"node_id"`
 }
 
 // ClientConfiguration represents the client side Hephaestus Node Level configuration
 type ClientNodeConfiguration struct {
+	// NodeID identifies the node in metrics and events
+	NodeID string `json:"node_id" yaml:"node_id"`
+
+	// Operation Mode
+	Mode OperationMode `json:"mode" yaml:"mode"`
+
 	// Log Processing Settings
 	LogProcessingConfiguration LogProcessingConfiguration `json:"log" yaml:"log"`
 
 	// Remote Repository Settings
 	RemoteRepositoryConfiguration RemoteRepositoryConfiguration `json:"remote-repository" yaml:"remote-repository"`
+
+	// Output Channel Settings
+	BackpressureConfiguration BackpressureConfiguration `json:"backpressure" yaml:"backpressure"`
+
+	// Write-Ahead Log Settings
+	WriteAheadLogConfiguration WriteAheadLogConfiguration `json:"wal" yaml:"wal"`
+
+	// Redaction Settings
+	RedactionConfiguration RedactionConfiguration `json:"redaction" yaml:"redaction"`
+
+	// Ingestion Settings
+	IngestionConfiguration IngestionConfiguration `json:"ingestion" yaml:"ingestion"`
+
+	// Log Parsing Settings
+	ParserConfiguration ParserConfiguration `json:"parser" yaml:"parser"`
+
+	// File Tailing Settings
+	TailConfiguration TailConfiguration `json:"tail" yaml:"tail"`
 }
 
+// OperationMode selects how generated solutions are handled
+type OperationMode string
+
+const (
+	// OperationModeSuggest reports solutions without touching the remote repository
+	OperationModeSuggest OperationMode = "suggest"
+	// OperationModeDeploy opens a pull request on the remote repository for each solution
+	OperationModeDeploy OperationMode = "deploy"
+)
+
 // LogProcessingConfiguration contains log processing settings
 type LogProcessingConfiguration struct {
+	// ThresholdLevel is the level an entry must have to count towards the threshold
 	ThresholdLevel string `json:"threshold_level" yaml:"threshold_level"`
+	// ThresholdCount is the number of threshold entries required to trigger processing
+	ThresholdCount int `json:"threshold_count" yaml:"threshold_count"`
+	// ThresholdWindow is the sliding time window threshold entries are counted in
+	ThresholdWindow time.Duration `json:"threshold_window" yaml:"threshold_window"`
+	// CooldownPeriod is how long after a flow finishes matching entries with the same
+	// fingerprint keep attaching to its incident instead of starting a new flow
+	CooldownPeriod time.Duration `json:"cooldown_period" yaml:"cooldown_period"`
+	// MaxConcurrentFlows bounds the solution flows running at once, zero means unlimited
+	MaxConcurrentFlows int `json:"max_concurrent_flows" yaml:"max_concurrent_flows"`
+	// ContextBeforeEntries bounds the buffered entries captured before a trigger, not
+	// counting the trigger itself; zero keeps all
+	ContextBeforeEntries int `json:"context_before_entries" yaml:"context_before_entries"`
+	// ContextBeforeWindow only captures entries this close before a trigger, zero keeps all
+	ContextBeforeWindow time.Duration `json:"context_before_window" yaml:"context_before_window"`
+	// ContextAfterWindow is how long entries keep being captured after a trigger before the
+	// solution flow runs, zero runs it right away
+	ContextAfterWindow time.Duration `json:"context_after_window" yaml:"context_after_window"`
+	// ContextAfterEntries ends the after window early once this many entries were captured
+	ContextAfterEntries int `json:"context_after_entries" yaml:"context_after_entries"`
+	// CorrelationKeys are the LogEntry.Context keys that tie entries to the same trace or
+	// request. Nil uses DefaultCorrelationKeys, an empty list disables correlation.
+	CorrelationKeys []string `json:"correlation_keys" yaml:"correlation_keys"`
+	// FlowTimeout bounds each solution flow, after window included, zero means no deadline
+	FlowTimeout time.Duration `json:"flow_timeout" yaml:"flow_timeout"`
+}
+
+// DefaultCorrelationKeys are used when no correlation keys are configured
+var DefaultCorrelationKeys = []string{"trace_id", "request_id"}
+
+// BackpressurePolicy selects what a node does when an output channel is full
+type BackpressurePolicy string
+
+const (
+	// BackpressurePolicyBlock waits up to the block timeout for room and drops the item afterwards
+	BackpressurePolicyBlock BackpressurePolicy = "block"
+	// BackpressurePolicyDropNewest drops the item being sent
+	BackpressurePolicyDropNewest BackpressurePolicy = "drop_newest"
+	// BackpressurePolicyDropOldest drops the oldest queued item to make room
+	BackpressurePolicyDropOldest BackpressurePolicy = "drop_oldest"
+	// BackpressurePolicySpill queues items in a local disk directory until the consumer catches up
+	BackpressurePolicySpill BackpressurePolicy = "spill"
+)
+
+// BackpressureConfiguration contains the solution and error channel settings
+type BackpressureConfiguration struct {
+	Solution ChannelConfiguration `json:"solution" yaml:"solution"`
+	Error    ChannelConfiguration `json:"error" yaml:"error"`
+}
+
+// ChannelConfiguration contains the capacity and backpressure policy of an output channel
+type ChannelConfiguration struct {
+	Capacity       int                `json:"capacity" yaml:"capacity"`
+	Policy         BackpressurePolicy `json:"policy" yaml:"policy"`
+	BlockTimeout   time.Duration      `json:"block_timeout" yaml:"block_timeout"`
+	SpillDirectory string             `json:"spill_dir" yaml:"spill_dir"`
+}
+
+// SyncPolicy selects when write-ahead log appends are flushed to stable storage
+type SyncPolicy string
+
+const (
+	// SyncPolicyAlways fsyncs after every append
+	SyncPolicyAlways SyncPolicy = "always"
+	// SyncPolicyInterval fsyncs periodically in the background
+	SyncPolicyInterval SyncPolicy = "interval"
+	// SyncPolicyNone leaves flushing to the operating system
+	SyncPolicyNone SyncPolicy = "none"
+)
+
+// WriteAheadLogConfiguration contains the node write-ahead log settings
+type WriteAheadLogConfiguration struct {
+	Enabled      bool          `json:"enabled" yaml:"enabled"`
+	Directory    string        `json:"dir" yaml:"dir"`
+	SyncPolicy   SyncPolicy    `json:"sync_policy" yaml:"sync_policy"`
+	SyncInterval time.Duration `json:"sync_interval" yaml:"sync_interval"`
+	// SegmentSize is the size in bytes after which a new segment is started
+	SegmentSize int64 `json:"segment_size" yaml:"segment_size"`
+	// MaxSegments is the segment count that triggers compaction
+	MaxSegments int `json:"max_segments" yaml:"max_segments"`
+}
+
+// IngestionConfiguration contains the sampling and rate limiting applied to entries
+// before they are buffered. Entries at or above AlwaysKeepLevel bypass both.
+type IngestionConfiguration struct {
+	// SampleRates maps a level to the share of its entries that is kept, from 0 to 1.
+	// Levels without a rate keep every entry.
+	SampleRates map[string]float64 `json:"sample_rates" yaml:"sample_rates"`
+	// RateLimit is the number of entries per second the node accepts, zero means unlimited
+	RateLimit float64 `json:"rate_limit" yaml:"rate_limit"`
+	// RateBurst is the number of entries accepted at once above the rate, zero uses the rate
+	RateBurst int `json:"rate_burst" yaml:"rate_burst"`
+	// AlwaysKeepLevel is the level from which entries are always kept, empty uses the threshold level
+	AlwaysKeepLevel string `json:"always_keep_level" yaml:"always_keep_level"`
+}
+
+// LogFormat selects how raw log lines are parsed
+type LogFormat string
+
+const (
+	// LogFormatAuto detects the format of every line
+	LogFormatAuto LogFormat = "auto"
+	// LogFormatJSON parses JSON objects such as zap, logrus and slog JSON output
+	LogFormatJSON LogFormat = "json"
+	// LogFormatLogfmt parses key=value pairs such as logrus and slog text output
+	LogFormatLogfmt LogFormat = "logfmt"
+	// LogFormatText parses plain text lines with an optional timestamp and level prefix
+	LogFormatText LogFormat = "text"
+)
+
+// ParserConfiguration contains the settings used to turn raw log lines into entries.
+// Each field list overrides the default keys looked up, in order, for that entry field.
+// Keys may be dot separated paths into nested JSON objects.
+type ParserConfiguration struct {
+	Format          LogFormat `json:"format" yaml:"format"`
+	TimestampFields []string  `json:"timestamp_fields" yaml:"timestamp_fields"`
+	LevelFields     []string  `json:"level_fields" yaml:"level_fields"`
+	MessageFields   []string  `json:"message_fields" yaml:"message_fields"`
+	StackFields     []string  `json:"stack_fields" yaml:"stack_fields"`
+	// DefaultLevel is used for lines without a level, empty means info
+	DefaultLevel string `json:"default_level" yaml:"default_level"`
+}
+
+// TailConfiguration contains the settings used to follow log files
+type TailConfiguration struct {
+	// Paths are glob patterns of the files to follow, matched again on every poll
+	Paths []string `json:"paths" yaml:"paths"`
+	// PollInterval is how often files are checked for new lines, zero uses one second
+	PollInterval time.Duration `json:"poll_interval" yaml:"poll_interval"`
+	// OffsetFile persists read offsets so a restart resumes where it stopped, empty keeps them in memory
+	OffsetFile string `json:"offset_file" yaml:"offset_file"`
+	// StartAtEnd skips the existing content of files found on the first poll without a saved offset
+	StartAtEnd bool `json:"start_at_end" yaml:"start_at_end"`
+}
+
+// RedactionMode selects how redacted values are replaced
+type RedactionMode string
+
+const (
+	// RedactionModeMask replaces a value with a marker naming the rule that matched
+	RedactionModeMask RedactionMode = "mask"
+	// RedactionModeHash replaces a value with a keyed hash so equal values stay comparable
+	RedactionModeHash RedactionMode = "hash"
+)
+
+// RedactionDetectors are the built-in detectors, all of them run when none are configured
+var RedactionDetectors = []string{"email", "ip", "credit_card", "jwt", "aws_access_key", "aws_secret_key", "github_token", "bearer_token"}
+
+// RedactionConfiguration contains the settings of the redaction stage that scrubs entries
+// before they reach the model provider or the remote repository
+type RedactionConfiguration struct {
+	Enabled bool          `json:"enabled" yaml:"enabled"`
+	Mode    RedactionMode `json:"mode" yaml:"mode"`
+	// HashKey keys the hashes of hash mode and is required there, without it hashes of
+	// guessable values could be reversed
+	HashKey string `json:"hash_key" yaml:"hash_key"`
+	// Detectors lists the built-in detectors to run. Nil runs all of them, an empty list none.
+	Detectors []string        `json:"detectors" yaml:"detectors"`
+	Rules     []RedactionRule `json:"rules" yaml:"rules"`
+}
+
+// RedactionRule is a user-defined redaction rule. Pattern redacts regular expression
+// matches in messages, traces and context strings, KeyPath redacts a whole context value
+// addressed by dot separated keys such as "user.email".
+type RedactionRule struct {
+	Name    string `json:"name" yaml:"name"`
+	Pattern string `json:"pattern" yaml:"pattern"`
+	KeyPath string `json:"key_path" yaml:"key_path"`
+}
+
+// Redaction counts the values one rule redacted in one field
+type Redaction struct {
+	Rule string `json:"rule"`
+	// Field is message, error_trace or the context key path prefixed with context.
+	Field string `json:"field"`
+	Count int    `json:"count"`
+}
+
+// RedactionSummary is an audit of the redactions a node applied, it never holds the
+// redacted values themselves
+type RedactionSummary struct {
+	// Entries is the number of entries that had at least one value redacted
+	Entries    int         `json:"entries"`
+	Redactions []Redaction `json:"redactions"`
 }
 
 // Remote Repository Provider contains remote repository code base connection settings
@@ -61,6 +318,19 @@ type LogEntry struct {
 	ProcessedAt time.Time              `json:"processed_at"`
 }
 
+// BatchResult counts the entries of a processed log batch
+type BatchResult struct {
+	Total int
+	// Accepted entries were buffered and counted towards the trigger
+	Accepted int
+	// Dropped entries were skipped by sampling or rate limiting
+	Dropped int
+	// Unpersisted entries were accepted but the write-ahead log failed to store them,
+	// leaving the node degraded. They are lost if the node restarts before replaying
+	// them is possible; retrying them would process them twice.
+	Unpersisted int
+}
+
 // Solution represents a generated solution
 type Solution struct {
 	ID          string    `json:"id"`
@@ -69,6 +339,48 @@ type Solution struct {
 	CodeChanges []Change  `json:"code_changes"`
 	GeneratedAt time.Time `json:"generated_at"`
 	Confidence  float64   `json:"confidence"`
+	// PullRequestURL is set once the solution has been deployed as a pull request
+	PullRequestURL string `json:"pull_request_url,omitempty"`
+	// Fingerprint identifies the root cause the solution addresses, see package fingerprint
+	Fingerprint string `json:"fingerprint,omitempty"`
+	// Occurrences counts the matching entries attached to the incident, including the trigger
+	Occurrences int `json:"occurrences,omitempty"`
+	// Templates are the dominant log templates in the entries the solution was generated from
+	Templates []LogTemplate `json:"templates,omitempty"`
+	// SourceFiles are the repository files located from the stack trace
+	SourceFiles []string `json:"source_files,omitempty"`
+	// Evidence is the log window captured around the trigger
+	Evidence *Evidence `json:"evidence,omitempty"`
+}
+
+// Evidence is the log window captured around a trigger
+type Evidence struct {
+	TriggeredAt time.Time `json:"triggered_at"`
+	Trigger     LogEntry  `json:"trigger"`
+	// Before holds the entries preceding the trigger, oldest first
+	Before []LogEntry `json:"before,omitempty"`
+	// After holds the entries received during the after window, oldest first
+	After []LogEntry `json:"after,omitempty"`
+	// Correlated holds the entries sharing a correlation key with the trigger from the
+	// buffer, the after window and the log store, oldest first
+	Correlated []LogEntry `json:"correlated,omitempty"`
+}
+
+// LogTemplate summarizes a mined log template within a batch of entries
+type LogTemplate struct {
+	ID int `json:"id"`
+	// Pattern is the template text with parameters shown as <*>
+	Pattern string `json:"pattern"`
+	// Count is the number of batch entries matching the template
+	Count int `json:"count"`
+	// Total is the number of entries matching the template since the miner started
+	Total int `json:"total"`
+	// Share is the fraction of the batch matching the template
+	Share float64 `json:"share"`
+	// BaselineShare is the fraction of entries outside the batch matching the template
+	BaselineShare float64 `json:"baseline_share"`
+	// New is set when the template was only seen within the batch
+	New bool `json:"new"`
 }
 
 // Change represents a code change
@@ -81,14 +393,19 @@ type Change struct {
 	Description string `json:"description"`
 }
 
-// NodeStatus represents the node status
+// NodeStatus represents the node status, see ValidateNodeStatusTransition for the lifecycle
 type NodeStatus string
 
 const (
 	NodeStatusInitializing NodeStatus = "initializing"
 	NodeStatusOperational  NodeStatus = "operational"
 	NodeStatusProcessing   NodeStatus = "processing"
-	NodeStatusError        NodeStatus = "error"
+	// NodeStatusDegraded means the node keeps processing with reduced guarantees
+	NodeStatusDegraded NodeStatus = "degraded"
+	// NodeStatusDraining means the node rejects new entries while in-flight flows finish
+	NodeStatusDraining NodeStatus = "draining"
+	NodeStatusStopped  NodeStatus = "stopped"
+	NodeStatusError    NodeStatus = "error"
 )
 
 // ConfigurationValidationError represents a configuration validation error
@@ -101,12 +418,37 @@ func (e ConfigurationValidationError) Error() string {
 	return fmt.Sprintf("%s: %s", e.FieldName, e.ErrorMessage)
 }
 
+// ValidateLogEntry checks that a log entry carries a known level and a message or error trace
+func ValidateLogEntry(entry LogEntry) error {
+	if _, err := ParseSeverity(entry.Level); err != nil {
+		return err
+	}
+	if strings.TrimSpace(entry.Message) == "" && strings.TrimSpace(entry.ErrorTrace) == "" {
+		return fmt.Errorf("%w: log entry has neither a message nor an error trace", ErrInvalidArgument)
+	}
+	return nil
+}
+
 // ValidateSystemConfiguration validates the system configuration
 func ValidateSystemConfiguration(config *SystemConfiguration) error {
 	if config == nil {
 		return &ConfigurationValidationError{FieldName: "config", ErrorMessage: "configuration cannot be nil"}
 	}
 
+	if config.LimitConfiguration.LogChunkLimit < 0 {
+		return &ConfigurationValidationError{FieldName: "limit.log_chunk_limit", ErrorMessage: "log chunk limit cannot be negative"}
+	}
+	if config.LimitConfiguration.LogChunkByteLimit < 0 {
+		return &ConfigurationValidationError{FieldName: "limit.log_chunk_byte_limit", ErrorMessage: "log chunk byte limit cannot be negative"}
+	}
+	if config.LimitConfiguration.LogBatchLimit < 0 {
+		return &ConfigurationValidationError{FieldName: "limit.log_batch_limit", ErrorMessage: "log batch limit cannot be negative"}
+	}
+
+	if err := ValidateSyslogConfiguration(config.SyslogConfiguration); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -115,5 +457,248 @@ func ValidateClientNodeConfiguration(config *ClientNodeConfiguration) error {
 		return &ConfigurationValidationError{FieldName: "config", ErrorMessage: "configuration cannot be nil"}
 	}
 
+	switch config.Mode {
+	case "", OperationModeSuggest:
+	case OperationModeDeploy:
+		remote := config.RemoteRepositoryConfiguration
+		if remote.RemoteRepositoryOwner == "" || remote.RemoteRepositoryName == "" {
+			return &ConfigurationValidationError{FieldName: "remote-repository", ErrorMessage: "repository owner and name are required in deploy mode"}
+		}
+	default:
+		return &ConfigurationValidationError{FieldName: "mode", ErrorMessage: fmt.Sprintf("unknown operation mode %q", config.Mode)}
+	}
+
+	if err := validateChannelConfiguration("backpressure.solution", config.BackpressureConfiguration.Solution); err != nil {
+		return err
+	}
+	if err := validateChannelConfiguration("backpressure.error", config.BackpressureConfiguration.Error); err != nil {
+		return err
+	}
+
+	if err := validateWriteAheadLogConfiguration(config.WriteAheadLogConfiguration); err != nil {
+		return err
+	}
+
+	if err := validateRedactionConfiguration(config.RedactionConfiguration); err != nil {
+		return err
+	}
+
+	if err := validateIngestionConfiguration(config.IngestionConfiguration); err != nil {
+		return err
+	}
+
+	if err := ValidateParserConfiguration(config.ParserConfiguration); err != nil {
+		return err
+	}
+
+	if err := ValidateTailConfiguration(config.TailConfiguration); err != nil {
+		return err
+	}
+
+	if level := config.LogProcessingConfiguration.ThresholdLevel; level != "" {
+		if _, err := ParseSeverity(level); err != nil {
+			return &ConfigurationValidationError{FieldName: "log.threshold_level", ErrorMessage: err.Error()}
+		}
+	}
+	if config.LogProcessingConfiguration.ThresholdCount < 0 {
+		return &ConfigurationValidationError{FieldName: "log.threshold_count", ErrorMessage: "threshold count cannot be negative"}
+	}
+	if config.LogProcessingConfiguration.ThresholdWindow < 0 {
+		return &ConfigurationValidationError{FieldName: "log.threshold_window", ErrorMessage: "threshold window cannot be negative"}
+	}
+	if config.LogProcessingConfiguration.CooldownPeriod < 0 {
+		return &ConfigurationValidationError{FieldName: "log.cooldown_period", ErrorMessage: "cooldown period cannot be negative"}
+	}
+	if config.LogProcessingConfiguration.MaxConcurrentFlows < 0 {
+		return &ConfigurationValidationError{FieldName: "log.max_concurrent_flows", ErrorMessage: "max concurrent flows cannot be negative"}
+	}
+	if config.LogProcessingConfiguration.ContextBeforeEntries < 0 {
+		return &ConfigurationValidationError{FieldName: "log.context_before_entries", ErrorMessage: "context before entries cannot be negative"}
+	}
+	if config.LogProcessingConfiguration.ContextBeforeWindow < 0 {
+		return &ConfigurationValidationError{FieldName: "log.context_before_window", ErrorMessage: "context before window cannot be negative"}
+	}
+	if config.LogProcessingConfiguration.ContextAfterWindow < 0 {
+		return &ConfigurationValidationError{FieldName: "log.context_after_window", ErrorMessage: "context after window cannot be negative"}
+	}
+	for _, key := range config.LogProcessingConfiguration.CorrelationKeys {
+		if key == "" {
+			return &ConfigurationValidationError{FieldName: "log.correlation_keys", ErrorMessage: "correlation keys cannot be empty"}
+		}
+	}
+	if config.LogProcessingConfiguration.ContextAfterEntries < 0 {
+		return &ConfigurationValidationError{FieldName: "log.context_after_entries", ErrorMessage: "context after entries cannot be negative"}
+	}
+	if config.LogProcessingConfiguration.FlowTimeout < 0 {
+		return &ConfigurationValidationError{FieldName: "log.flow_timeout", ErrorMessage: "flow timeout cannot be negative"}
+	}
+
+	return nil
+}
+
+// validateChannelConfiguration validates an output channel configuration
+func validateChannelConfiguration(field string, config ChannelConfiguration) error {
+	if config.Capacity < 0 {
+		return &ConfigurationValidationError{FieldName: field + ".capacity", ErrorMessage: "capacity cannot be negative"}
+	}
+	if config.BlockTimeout < 0 {
+		return &ConfigurationValidationError{FieldName: field + ".block_timeout", ErrorMessage: "block timeout cannot be negative"}
+	}
+
+	switch config.Policy {
+	case "", BackpressurePolicyBlock, BackpressurePolicyDropNewest, BackpressurePolicyDropOldest:
+	case BackpressurePolicySpill:
+		if config.SpillDirectory == "" {
+			return &ConfigurationValidationError{FieldName: field + ".spill_dir", ErrorMessage: "spill directory is required for the spill policy"}
+		}
+	default:
+		return &ConfigurationValidationError{FieldName: field + ".policy", ErrorMessage: fmt.Sprintf("unknown backpressure policy %q", config.Policy)}
+	}
+
+	return nil
+}
+
+// validateWriteAheadLogConfiguration validates the write-ahead log configuration
+func validateWriteAheadLogConfiguration(config WriteAheadLogConfiguration) error {
+	if !config.Enabled {
+		return nil
+	}
+
+	if config.Directory == "" {
+		return &ConfigurationValidationError{FieldName: "wal.dir", ErrorMessage: "directory is required when the write-ahead log is enabled"}
+	}
+	switch config.SyncPolicy {
+	case "", SyncPolicyAlways, SyncPolicyInterval, SyncPolicyNone:
+	default:
+		return &ConfigurationValidationError{FieldName: "wal.sync_policy", ErrorMessage: fmt.Sprintf("unknown sync policy %q", config.SyncPolicy)}
+	}
+	if config.SyncInterval < 0 {
+		return &ConfigurationValidationError{FieldName: "wal.sync_interval", ErrorMessage: "sync interval cannot be negative"}
+	}
+	if config.SegmentSize < 0 {
+		return &ConfigurationValidationError{FieldName: "wal.segment_size", ErrorMessage: "segment size cannot be negative"}
+	}
+	if config.MaxSegments < 0 {
+		return &ConfigurationValidationError{FieldName: "wal.max_segments", ErrorMessage: "max segments cannot be negative"}
+	}
+
+	return nil
+}
+
+func validateRedactionConfiguration(config RedactionConfiguration) error {
+	if !config.Enabled {
+		return nil
+	}
+
+	switch config.Mode {
+	case "", RedactionModeMask, RedactionModeHash:
+	default:
+		return &ConfigurationValidationError{FieldName: "redaction.mode", ErrorMessage: fmt.Sprintf("unknown redaction mode %q", config.Mode)}
+	}
+	if config.Mode == RedactionModeHash && config.HashKey == "" {
+		return &ConfigurationValidationError{FieldName: "redaction.hash_key", ErrorMessage: "hash key is required in hash mode"}
+	}
+	for _, name := range config.Detectors {
+		if !slices.Contains(RedactionDetectors, name) {
+			return &ConfigurationValidationError{FieldName: "redaction.detectors", ErrorMessage: fmt.Sprintf("unknown detector %q", name)}
+		}
+	}
+	for i, rule := range config.Rules {
+		field := fmt.Sprintf("redaction.rules[%d]", i)
+		if rule.Name == "" {
+			return &ConfigurationValidationError{FieldName: field + ".name", ErrorMessage: "rule name is required"}
+		}
+		if (rule.Pattern == "") == (rule.KeyPath == "") {
+			return &ConfigurationValidationError{FieldName: field, ErrorMessage: "exactly one of pattern and key_path is required"}
+		}
+		if rule.Pattern != "" {
+			if _, err := regexp.Compile(rule.Pattern); err != nil {
+				return &ConfigurationValidationError{FieldName: field + ".pattern", ErrorMessage: err.Error()}
+			}
+		}
+	}
+
+	return nil
+}
+
+func validateIngestionConfiguration(config IngestionConfiguration) error {
+	for level, rate := range config.SampleRates {
+		if _, err := ParseSeverity(level); err != nil {
+			return &ConfigurationValidationError{FieldName: "ingestion.sample_rates", ErrorMessage: err.Error()}
+		}
+		if rate < 0 || rate > 1 {
+			return &ConfigurationValidationError{FieldName: "ingestion.sample_rates", ErrorMessage: fmt.Sprintf("sample rate for %q must be between 0 and 1", level)}
+		}
+	}
+	if config.RateLimit < 0 {
+		return &ConfigurationValidationError{FieldName: "ingestion.rate_limit", ErrorMessage: "rate limit cannot be negative"}
+	}
+	if config.RateBurst < 0 {
+		return &ConfigurationValidationError{FieldName: "ingestion.rate_burst", ErrorMessage: "rate burst cannot be negative"}
+	}
+	if level := config.AlwaysKeepLevel; level != "" {
+		if _, err := ParseSeverity(level); err != nil {
+			return &ConfigurationValidationError{FieldName: "ingestion.always_keep_level", ErrorMessage: err.Error()}
+		}
+	}
+
+	return nil
+}
+
+// ValidateParserConfiguration validates log parsing settings
+func ValidateParserConfiguration(config ParserConfiguration) error {
+	switch config.Format {
+	case "", LogFormatAuto, LogFormatJSON, LogFormatLogfmt, LogFormatText:
+	default:
+		return &ConfigurationValidationError{FieldName: "parser.format", ErrorMessage: fmt.Sprintf("unknown log format %q", config.Format)}
+	}
+	if level := config.DefaultLevel; level != "" {
+		if _, err := ParseSeverity(level); err != nil {
+			return &ConfigurationValidationError{FieldName: "parser.default_level", ErrorMessage: err.Error()}
+		}
+	}
+
+	return nil
+}
+
+// ValidateTailConfiguration validates file tailing settings
+func ValidateTailConfiguration(config TailConfiguration) error {
+	for _, pattern := range config.Paths {
+		if pattern == "" {
+			return &ConfigurationValidationError{FieldName: "tail.paths", ErrorMessage: "path cannot be empty"}
+		}
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return &ConfigurationValidationError{FieldName: "tail.paths", ErrorMessage: fmt.Sprintf("invalid pattern %q: %v", pattern, err)}
+		}
+	}
+	if config.PollInterval < 0 {
+		return &ConfigurationValidationError{FieldName: "tail.poll_interval", ErrorMessage: "poll interval cannot be negative"}
+	}
+
+	return nil
+}
+
+// ValidateSyslogConfiguration validates syslog receiver settings
+func ValidateSyslogConfiguration(config SyslogConfiguration) error {
+	if config.MaxMessageSize < 0 {
+		return &ConfigurationValidationError{FieldName: "syslog.max_message_size", ErrorMessage: "max message size cannot be negative"}
+	}
+	if config.MaxConnections < 0 {
+		return &ConfigurationValidationError{FieldName: "syslog.max_connections", ErrorMessage: "max connections cannot be negative"}
+	}
+	if config.IdleTimeout < 0 {
+		return &ConfigurationValidationError{FieldName: "syslog.idle_timeout", ErrorMessage: "idle timeout cannot be negative"}
+	}
+	for i, route := range config.Routes {
+		if route.NodeID == "" {
+			return &ConfigurationValidationError{FieldName: "syslog.routes", ErrorMessage: fmt.Sprintf("route %d has no node id", i)}
+		}
+		for _, pattern := range []string{route.Hostname, route.AppName} {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return &ConfigurationValidationError{FieldName: "syslog.routes", ErrorMessage: fmt.Sprintf("route %d has invalid pattern %q: %v", i, pattern, err)}
+			}
+		}
+	}
+
 	return nil
 }
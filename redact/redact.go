@@ -0,0 +1,385 @@
+// Package redact scrubs personal data and secrets from log entries before they leave
+// the process.
+package redact
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/HoyeonS/hephaestus/pkg/hephaestus"
+)
+
+// hashLength is the number of hex characters of a value hash kept in hash mode
+const hashLength = 16
+
+// detector finds values to redact in a string
+type detector struct {
+	name    string
+	pattern *regexp.Regexp
+	// group is the submatch that is redacted, zero redacts the whole match
+	group int
+	// valid filters out matches that only look like the value, nil accepts all
+	valid func(value string) bool
+}
+
+// builtinDetectors holds the built-in detectors by name
+var builtinDetectors = map[string]detector{
+	"email": {
+		pattern: regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`),
+	},
+	"ip": {
+		pattern: regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b|(?i:\b(?:[0-9a-f]{0,4}:){2,7}[0-9a-f]{0,4}\b)`),
+		valid:   validIP,
+	},
+	"credit_card": {
+		pattern: regexp.MustCompile(`\b(?:\d[ \-]?){12,18}\d\b`),
+		valid:   luhn,
+	},
+	"jwt": {
+		pattern: regexp.MustCompile(`\beyJ[A-Za-z0-9_\-]+\.[A-Za-z0-9_\-]+\.[A-Za-z0-9_\-]+`),
+	},
+	"aws_access_key": {
+		pattern: regexp.MustCompile(`\b(?:AKIA|ASIA|ABIA|ACCA)[0-9A-Z]{16}\b`),
+	},
+	"aws_secret_key": {
+		pattern: regexp.MustCompile(`(?i)aws_?secret_?(?:access_?)?key["']?\s*[:=]\s*["']?([A-Za-z0-9/+=]{40})`),
+		group:   1,
+	},
+	"github_token": {
+		pattern: regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,255}|github_pat_[A-Za-z0-9_]{22,255})\b`),
+	},
+	"bearer_token": {
+		pattern: regexp.MustCompile(`(?i)\bbearer\s+([A-Za-z0-9\-._~+/]+=*)`),
+		group:   1,
+	},
+}
+
+// detectorPriority orders the built-in detectors so the most specific claims a value first
+var detectorPriority = []string{"jwt", "github_token", "aws_access_key", "aws_secret_key", "bearer_token", "email", "credit_card", "ip"}
+
+// keyPathRule redacts the context value at a key path
+type keyPathRule struct {
+	name string
+	path string
+}
+
+// Redactor replaces sensitive values in log entries. It is safe for concurrent use.
+type Redactor struct {
+	mode      hephaestus.RedactionMode
+	hashKey   []byte
+	detectors []detector
+	keyPaths  []keyPathRule
+}
+
+// New creates a redactor from the redaction settings.
+// User-defined pattern rules take precedence over the built-in detectors.
+func New(config hephaestus.RedactionConfiguration) (*Redactor, error) {
+	r := &Redactor{
+		mode:    config.Mode,
+		hashKey: []byte(config.HashKey),
+	}
+	if r.mode == "" {
+		r.mode = hephaestus.RedactionModeMask
+	}
+	if r.mode == hephaestus.RedactionModeHash && len(r.hashKey) == 0 {
+		return nil, fmt.Errorf("%w: redaction hash mode requires a hash key", hephaestus.ErrInvalidConfig)
+	}
+
+	for _, rule := range config.Rules {
+		switch {
+		case rule.Pattern != "":
+			pattern, err := regexp.Compile(rule.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("%w: redaction rule %q: %v", hephaestus.ErrInvalidConfig, rule.Name, err)
+			}
+			r.detectors = append(r.detectors, detector{name: rule.Name, pattern: pattern})
+		case rule.KeyPath != "":
+			r.keyPaths = append(r.keyPaths, keyPathRule{name: rule.Name, path: rule.KeyPath})
+		}
+	}
+
+	enabled := config.Detectors
+	if enabled == nil {
+		enabled = hephaestus.RedactionDetectors
+	}
+	for _, name := range enabled {
+		if _, ok := builtinDetectors[name]; !ok {
+			return nil, fmt.Errorf("%w: unknown redaction detector %q", hephaestus.ErrInvalidConfig, name)
+		}
+	}
+	for _, name := range detectorPriority {
+		for _, e := range enabled {
+			if e == name {
+				d := builtinDetectors[name]
+				d.name = name
+				r.detectors = append(r.detectors, d)
+			}
+		}
+	}
+
+	return r, nil
+}
+
+// Redact returns a copy of entry with sensitive values replaced and the redactions
+// applied. The entry passed in, its context map included, is left untouched.
+func (r *Redactor) Redact(entry hephaestus.LogEntry) (hephaestus.LogEntry, []hephaestus.Redaction) {
+	counts := make(map[hephaestus.Redaction]int)
+
+	entry.Message = r.redactString(entry.Message, "message", counts)
+	entry.ErrorTrace = r.redactString(entry.ErrorTrace, "error_trace", counts)
+	if entry.Context != nil {
+		if redacted, changed := r.redactValue(entry.Context, "", counts); changed {
+			entry.Context = redacted.(map[string]interface{})
+		}
+	}
+
+	if len(counts) == 0 {
+		return entry, nil
+	}
+	redactions := make([]hephaestus.Redaction, 0, len(counts))
+	for redaction, count := range counts {
+		redaction.Count = count
+		redactions = append(redactions, redaction)
+	}
+	sort.Slice(redactions, func(i, j int) bool {
+		if redactions[i].Field != redactions[j].Field {
+			return redactions[i].Field < redactions[j].Field
+		}
+		return redactions[i].Rule < redactions[j].Rule
+	})
+	return entry, redactions
+}
+
+// span is a part of a string claimed by a detector
+type span struct {
+	start, end int
+	rule       string
+}
+
+// redactString replaces every detected value in s. Values are claimed by the first
+// detector matching them so overlapping matches are redacted once.
+func (r *Redactor) redactString(s, field string, counts map[hephaestus.Redaction]int) string {
+	if s == "" {
+		return s
+	}
+
+	var spans []span
+	for _, d := range r.detectors {
+		for _, match := range d.pattern.FindAllStringSubmatchIndex(s, -1) {
+			start, end := match[2*d.group], match[2*d.group+1]
+			if start < 0 || start == end {
+				continue
+			}
+			if d.valid != nil && !d.valid(s[start:end]) {
+				continue
+			}
+			if overlaps(spans, start, end) {
+				continue
+			}
+			spans = append(spans, span{start: start, end: end, rule: d.name})
+		}
+	}
+	if len(spans) == 0 {
+		return s
+	}
+
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+	var b strings.Builder
+	last := 0
+	for _, sp := range spans {
+		b.WriteString(s[last:sp.start])
+		b.WriteString(r.replacement(sp.rule, s[sp.start:sp.end]))
+		last = sp.end
+		counts[hephaestus.Redaction{Rule: sp.rule, Field: field}]++
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// redactValue redacts a context value found at path and reports whether it changed.
+// Maps and slices are copied rather than modified. Other values such as typed maps,
+// structs and errors are normalized first and only replaced when redacted.
+func (r *Redactor) redactValue(value interface{}, path string, counts map[hephaestus.Redaction]int) (interface{}, bool) {
+	switch v := value.(type) {
+	case string:
+		redacted := r.redactString(v, "context."+path, counts)
+		return redacted, redacted != v
+	case map[string]interface{}:
+		var copied map[string]interface{}
+		for key, item := range v {
+			itemPath := key
+			if path != "" {
+				itemPath = path + "." + key
+			}
+
+			redacted, changed := r.redactKeyPath(item, itemPath, counts)
+			if !changed {
+				redacted, changed = r.redactValue(item, itemPath, counts)
+			}
+			if !changed {
+				continue
+			}
+			if copied == nil {
+				copied = make(map[string]interface{}, len(v))
+				for k, i := range v {
+					copied[k] = i
+				}
+			}
+			copied[key] = redacted
+		}
+		if copied == nil {
+			return value, false
+		}
+		return copied, true
+	case []interface{}:
+		var copied []interface{}
+		for i, item := range v {
+			redacted, changed := r.redactValue(item, path, counts)
+			if !changed {
+				continue
+			}
+			if copied == nil {
+				copied = append([]interface{}(nil), v...)
+			}
+			copied[i] = redacted
+		}
+		if copied == nil {
+			return value, false
+		}
+		return copied, true
+	case []string:
+		var copied []string
+		for i, item := range v {
+			redacted := r.redactString(item, "context."+path, counts)
+			if redacted == item {
+				continue
+			}
+			if copied == nil {
+				copied = append([]string(nil), v...)
+			}
+			copied[i] = redacted
+		}
+		if copied == nil {
+			return value, false
+		}
+		return copied, true
+	default:
+		normalized, ok := normalize(value)
+		if !ok {
+			return value, false
+		}
+		return r.redactValue(normalized, path, counts)
+	}
+}
+
+// normalize converts a context value the detectors cannot walk into strings, maps and
+// slices: errors and Stringers become their text, other composite values are round
+// tripped through JSON. It reports false for numbers, booleans and nil, which hold
+// nothing to redact.
+func normalize(value interface{}) (interface{}, bool) {
+	switch v := value.(type) {
+	case error:
+		return v.Error(), true
+	case fmt.Stringer:
+		return v.String(), true
+	case []byte:
+		return string(v), true
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.String:
+		return rv.String(), true
+	case reflect.Map, reflect.Slice, reflect.Array, reflect.Struct, reflect.Pointer, reflect.Interface:
+		data, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Sprint(value), true
+		}
+		var decoded interface{}
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			return fmt.Sprint(value), true
+		}
+		return decoded, decoded != nil
+	default:
+		return nil, false
+	}
+}
+
+// redactKeyPath replaces a whole context value when a key path rule addresses it
+func (r *Redactor) redactKeyPath(value interface{}, path string, counts map[hephaestus.Redaction]int) (interface{}, bool) {
+	for _, rule := range r.keyPaths {
+		if rule.path == path {
+			counts[hephaestus.Redaction{Rule: rule.name, Field: "context." + path}]++
+			return r.replacement(rule.name, fmt.Sprint(value)), true
+		}
+	}
+	return value, false
+}
+
+// replacement returns the text a redacted value is replaced with
+func (r *Redactor) replacement(rule, value string) string {
+	if r.mode == hephaestus.RedactionModeHash {
+		mac := hmac.New(sha256.New, r.hashKey)
+		mac.Write([]byte(value))
+		return "[" + rule + ":" + hex.EncodeToString(mac.Sum(nil))[:hashLength] + "]"
+	}
+	return "[REDACTED:" + rule + "]"
+}
+
+// overlaps reports whether [start, end) overlaps a claimed span
+func overlaps(spans []span, start, end int) bool {
+	for _, sp := range spans {
+		if start < sp.end && sp.start < end {
+			return true
+		}
+	}
+	return false
+}
+
+// validIP accepts IPv4 addresses and IPv6 addresses with at least three groups, which
+// keeps clock times and short colon separated tokens out
+func validIP(value string) bool {
+	if net.ParseIP(value) == nil {
+		return false
+	}
+	if !strings.Contains(value, ":") {
+		return true
+	}
+
+	groups := 0
+	for _, group := range strings.Split(value, ":") {
+		if group != "" {
+			groups++
+		}
+	}
+	return groups >= 3
+}
+
+// luhn reports whether a card number candidate passes the Luhn checksum
+func luhn(value string) bool {
+	sum, digits := 0, 0
+	for i := len(value) - 1; i >= 0; i-- {
+		c := value[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if digits%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		digits++
+	}
+	return digits >= 13 && digits <= 19 && sum%10 == 0
+}
@@ -2,7 +2,15 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/HoyeonS/hephaestus/pkg/hephaestus"
 	"github.com/google/go-github/v45/github"
@@ -19,6 +27,12 @@ type RemoteService struct {
 
 	// Active repositories
 	repository Repository
+
+	// treeMu guards the file list of the base branch, cached by the commit it was read at
+	treeMu        sync.Mutex
+	treeSHA       string
+	treeFiles     []string
+	treeTruncated bool
 }
 
 // Repository represents a remote repository instance
@@ -87,3 +101,329 @@ func (s *RemoteService) CreateRepository(ctx context.Context, nodeID string) err
 
 	return nil
 }
+
+// CreatePullRequest commits the changes to a new branch and opens a pull request against the configured branch
+func (s *RemoteService) CreatePullRequest(ctx context.Context, title, body string, changes []hephaestus.Change) (string, error) {
+	if s.remoteRepositoryClient == nil || s.config == nil {
+		return "", fmt.Errorf("remote service is not initialized")
+	}
+	if len(changes) == 0 {
+		return "", fmt.Errorf("%w: no changes to deploy", hephaestus.ErrInvalidArgument)
+	}
+
+	owner, name := s.config.RemoteRepositoryOwner, s.config.RemoteRepositoryName
+	base, err := s.baseBranch(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	baseRef, _, err := s.remoteRepositoryClient.Git.GetRef(ctx, owner, name, "refs/heads/"+base)
+	if err != nil {
+		return "", repositoryError("get_ref", "failed to resolve base branch", err)
+	}
+
+	branch := fmt.Sprintf("hephaestus/fix-%d", time.Now().UnixNano())
+	_, _, err = s.remoteRepositoryClient.Git.CreateRef(ctx, owner, name, &github.Reference{
+		Ref:    github.String("refs/heads/" + branch),
+		Object: &github.GitObject{SHA: baseRef.GetObject().SHA},
+	})
+	if err != nil {
+		return "", repositoryError("create_branch", "failed to create branch", err)
+	}
+
+	prURL, err := s.openPullRequest(ctx, branch, base, title, body, changes)
+	if err != nil {
+		// Do not leave a half committed branch behind, even when ctx is done
+		if _, deleteErr := s.remoteRepositoryClient.Git.DeleteRef(context.WithoutCancel(ctx), owner, name, "refs/heads/"+branch); deleteErr != nil {
+			err = errors.Join(err, repositoryError("delete_branch", fmt.Sprintf("failed to delete branch %s", branch), deleteErr))
+		}
+		return "", err
+	}
+	return prURL, nil
+}
+
+// openPullRequest commits the changes to the branch, one commit per file, and opens the
+// pull request from it
+func (s *RemoteService) openPullRequest(ctx context.Context, branch, base, title, body string, changes []hephaestus.Change) (string, error) {
+	var paths []string
+	byPath := make(map[string][]hephaestus.Change)
+	for _, change := range changes {
+		filePath := s.resolvePath(change.FilePath)
+		if _, ok := byPath[filePath]; !ok {
+			paths = append(paths, filePath)
+		}
+		byPath[filePath] = append(byPath[filePath], change)
+	}
+
+	for _, filePath := range paths {
+		if err := s.commitFile(ctx, branch, filePath, byPath[filePath]); err != nil {
+			return "", err
+		}
+	}
+
+	pr, _, err := s.remoteRepositoryClient.PullRequests.Create(ctx, s.config.RemoteRepositoryOwner, s.config.RemoteRepositoryName, &github.NewPullRequest{
+		Title: github.String(title),
+		Head:  github.String(branch),
+		Base:  github.String(base),
+		Body:  github.String(body),
+	})
+	if err != nil {
+		return "", repositoryError("create_pull_request", "failed to create pull request", err)
+	}
+
+	return pr.GetHTMLURL(), nil
+}
+
+// ListFiles returns the file paths on the base branch relative to the configured base
+// directory. The list is cached until the branch moves to another commit. A tree too
+// large for GitHub to return whole fails with hephaestus.ErrTruncated.
+func (s *RemoteService) ListFiles(ctx context.Context) ([]string, error) {
+	if s.remoteRepositoryClient == nil || s.config == nil {
+		return nil, fmt.Errorf("remote service is not initialized")
+	}
+
+	base, err := s.baseBranch(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	owner, name := s.config.RemoteRepositoryOwner, s.config.RemoteRepositoryName
+	baseRef, _, err := s.remoteRepositoryClient.Git.GetRef(ctx, owner, name, "refs/heads/"+base)
+	if err != nil {
+		return nil, repositoryError("get_ref", "failed to resolve base branch", err)
+	}
+	sha := baseRef.GetObject().GetSHA()
+
+	s.treeMu.Lock()
+	cached, files, truncated := s.treeSHA == sha, s.treeFiles, s.treeTruncated
+	s.treeMu.Unlock()
+	if !cached {
+		files, truncated, err = s.listTree(ctx, sha)
+		if err != nil {
+			return nil, err
+		}
+		s.treeMu.Lock()
+		s.treeSHA, s.treeFiles, s.treeTruncated = sha, files, truncated
+		s.treeMu.Unlock()
+	}
+
+	if truncated {
+		return nil, repositoryError("get_tree", "failed to list repository files", fmt.Errorf("%w: tree of %s", hephaestus.ErrTruncated, base))
+	}
+	return slices.Clone(files), nil
+}
+
+// listTree reads the files of the tree at a commit and reports whether GitHub left
+// some out
+func (s *RemoteService) listTree(ctx context.Context, sha string) ([]string, bool, error) {
+	tree, _, err := s.remoteRepositoryClient.Git.GetTree(ctx, s.config.RemoteRepositoryOwner, s.config.RemoteRepositoryName, sha, true)
+	if err != nil {
+		return nil, false, repositoryError("get_tree", "failed to list repository files", err)
+	}
+	if tree.GetTruncated() {
+		return nil, true, nil
+	}
+
+	prefix := strings.Trim(s.config.BaseDirectory, "/")
+	if prefix != "" {
+		prefix += "/"
+	}
+
+	files := make([]string, 0, len(tree.Entries))
+	for _, entry := range tree.Entries {
+		if entry.GetType() != "blob" || !strings.HasPrefix(entry.GetPath(), prefix) {
+			continue
+		}
+		files = append(files, strings.TrimPrefix(entry.GetPath(), prefix))
+	}
+	return files, false, nil
+}
+
+// FetchFiles reads files from the base branch.
+// Paths are relative to the configured base directory, files that do not exist are left out.
+func (s *RemoteService) FetchFiles(ctx context.Context, paths []string) (map[string]string, error) {
+	if s.remoteRepositoryClient == nil || s.config == nil {
+		return nil, fmt.Errorf("remote service is not initialized")
+	}
+
+	base, err := s.baseBranch(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	owner, name := s.config.RemoteRepositoryOwner, s.config.RemoteRepositoryName
+	contents := make(map[string]string, len(paths))
+	for _, filePath := range paths {
+		resolved := s.resolvePath(filePath)
+		file, _, resp, err := s.remoteRepositoryClient.Repositories.GetContents(ctx, owner, name, resolved, &github.RepositoryContentGetOptions{Ref: base})
+		if err != nil {
+			if resp != nil && resp.StatusCode == http.StatusNotFound {
+				continue
+			}
+			return nil, repositoryError("get_contents", fmt.Sprintf("failed to read %s", resolved), err)
+		}
+		if file == nil {
+			// A directory listing
+			continue
+		}
+
+		content, err := file.GetContent()
+		if err != nil {
+			return nil, repositoryError("get_contents", fmt.Sprintf("failed to decode %s", resolved), err)
+		}
+		contents[filePath] = content
+	}
+	return contents, nil
+}
+
+// baseBranch returns the configured branch or the repository default branch
+func (s *RemoteService) baseBranch(ctx context.Context) (string, error) {
+	if s.config.RemoteRepositoryBranch != "" {
+		return s.config.RemoteRepositoryBranch, nil
+	}
+
+	repo, _, err := s.remoteRepositoryClient.Repositories.Get(ctx, s.config.RemoteRepositoryOwner, s.config.RemoteRepositoryName)
+	if err != nil {
+		return "", repositoryError("get_repository", "failed to resolve default branch", err)
+	}
+	return repo.GetDefaultBranch(), nil
+}
+
+// commitFile applies the changes of one file on the branch in a single commit. The file
+// is read once and every change applies to that copy, a file that does not exist is
+// created with the content of the last change.
+func (s *RemoteService) commitFile(ctx context.Context, branch, filePath string, changes []hephaestus.Change) error {
+	owner, name := s.config.RemoteRepositoryOwner, s.config.RemoteRepositoryName
+	message := fmt.Sprintf("Update %s", filePath)
+	if len(changes) == 1 && changes[0].Description != "" {
+		message = changes[0].Description
+	}
+
+	file, _, resp, err := s.remoteRepositoryClient.Repositories.GetContents(ctx, owner, name, filePath, &github.RepositoryContentGetOptions{Ref: branch})
+	if err != nil {
+		if resp == nil || resp.StatusCode != http.StatusNotFound {
+			return repositoryError("get_contents", fmt.Sprintf("failed to read %s", filePath), err)
+		}
+
+		_, _, err = s.remoteRepositoryClient.Repositories.CreateFile(ctx, owner, name, filePath, &github.RepositoryContentFileOptions{
+			Message: github.String(message),
+			Content: []byte(changes[len(changes)-1].NewContent),
+			Branch:  github.String(branch),
+		})
+		if err != nil {
+			return repositoryError("create_file", fmt.Sprintf("failed to create %s", filePath), err)
+		}
+		return nil
+	}
+
+	content, err := file.GetContent()
+	if err != nil {
+		return repositoryError("get_contents", fmt.Sprintf("failed to decode %s", filePath), err)
+	}
+
+	updated, err := applyChanges(content, changes)
+	if err != nil {
+		return repositoryError("apply_change", fmt.Sprintf("failed to apply changes to %s", filePath), err)
+	}
+
+	_, _, err = s.remoteRepositoryClient.Repositories.UpdateFile(ctx, owner, name, filePath, &github.RepositoryContentFileOptions{
+		Message: github.String(message),
+		Content: []byte(updated),
+		SHA:     file.SHA,
+		Branch:  github.String(branch),
+	})
+	if err != nil {
+		return repositoryError("update_file", fmt.Sprintf("failed to update %s", filePath), err)
+	}
+	return nil
+}
+
+// resolvePath joins a change path with the configured base directory
+func (s *RemoteService) resolvePath(filePath string) string {
+	if s.config.BaseDirectory == "" {
+		return strings.TrimPrefix(filePath, "/")
+	}
+	return strings.TrimPrefix(path.Join(s.config.BaseDirectory, filePath), "/")
+}
+
+// applyChanges applies the changes of one file to its content. Whole file changes
+// apply first, line ranged changes then apply from the bottom up so that each one
+// still finds the lines it was written against. Overlapping ranges are rejected.
+func applyChanges(content string, changes []hephaestus.Change) (string, error) {
+	var ranged []hephaestus.Change
+	for _, change := range changes {
+		if change.StartLine <= 0 {
+			content = change.NewContent
+			continue
+		}
+		ranged = append(ranged, change)
+	}
+
+	sort.SliceStable(ranged, func(i, j int) bool { return ranged[i].StartLine > ranged[j].StartLine })
+	for i, change := range ranged {
+		if i > 0 && endLine(change) >= ranged[i-1].StartLine {
+			return "", fmt.Errorf("%w: line ranges %d-%d and %d-%d overlap", hephaestus.ErrInvalidArgument,
+				change.StartLine, endLine(change), ranged[i-1].StartLine, endLine(ranged[i-1]))
+		}
+		var err error
+		if content, err = applyChange(content, change); err != nil {
+			return "", err
+		}
+	}
+	return content, nil
+}
+
+// applyChange replaces the change line range in content with the new content.
+// A change without a start line replaces the whole file.
+func applyChange(content string, change hephaestus.Change) (string, error) {
+	if change.StartLine <= 0 {
+		return change.NewContent, nil
+	}
+
+	trailingNewline := strings.HasSuffix(content, "\n")
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+
+	end := endLine(change)
+	if end > len(lines) {
+		return "", fmt.Errorf("%w: line range %d-%d exceeds %d lines", hephaestus.ErrInvalidArgument, change.StartLine, end, len(lines))
+	}
+
+	current := strings.Join(lines[change.StartLine-1:end], "\n")
+	if change.OldContent != "" && strings.TrimSpace(current) != strings.TrimSpace(change.OldContent) {
+		return "", fmt.Errorf("%w: content at lines %d-%d does not match", hephaestus.ErrInvalidArgument, change.StartLine, end)
+	}
+
+	replacement := strings.Split(strings.TrimSuffix(change.NewContent, "\n"), "\n")
+	if change.NewContent == "" {
+		replacement = nil
+	}
+
+	updated := make([]string, 0, len(lines)-(end-change.StartLine+1)+len(replacement))
+	updated = append(updated, lines[:change.StartLine-1]...)
+	updated = append(updated, replacement...)
+	updated = append(updated, lines[end:]...)
+
+	result := strings.Join(updated, "\n")
+	if trailingNewline {
+		result += "\n"
+	}
+	return result, nil
+}
+
+// endLine returns the last line a ranged change replaces
+func endLine(change hephaestus.Change) int {
+	if change.EndLine < change.StartLine {
+		return change.StartLine
+	}
+	return change.EndLine
+}
+
+// repositoryError wraps a GitHub API failure
+func repositoryError(operation, message string, err error) error {
+	return &hephaestus.RemoteRepositoryError{
+		Provider:  "github",
+		Operation: operation,
+		Message:   message,
+		Err:       err,
+	}
+}
@@ -2,11 +2,19 @@ package repository
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 
+	"github.com/HoyeonS/hephaestus/pkg/hephaestus"
 	"github.com/google/go-github/v45/github"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // Mock GitHub client
@@ -77,6 +85,251 @@ func TestInitialize(t *testing.T) {
 	})
 }
 
+func TestApplyChange(t *testing.T) {
+	content := "package main\n\nfunc main() {\n\tpanic(\"boom\")\n}\n"
+
+	tests := []struct {
+		name    string
+		change  hephaestus.Change
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "replace whole file",
+			change: hephaestus.Change{NewContent: "package main\n"},
+			want:   "package main\n",
+		},
+		{
+			name:   "replace single line",
+			change: hephaestus.Change{StartLine: 4, EndLine: 4, OldContent: "panic(\"boom\")", NewContent: "\treturn"},
+			want:   "package main\n\nfunc main() {\n\treturn\n}\n",
+		},
+		{
+			name:   "replace range with more lines",
+			change: hephaestus.Change{StartLine: 3, EndLine: 5, NewContent: "func main() {\n\tlog.Println(\"ok\")\n\treturn\n}"},
+			want:   "package main\n\nfunc main() {\n\tlog.Println(\"ok\")\n\treturn\n}\n",
+		},
+		{
+			name:    "old content mismatch",
+			change:  hephaestus.Change{StartLine: 4, OldContent: "return", NewContent: "\treturn"},
+			wantErr: true,
+		},
+		{
+			name:    "range out of bounds",
+			change:  hephaestus.Change{StartLine: 4, EndLine: 9, NewContent: "\treturn"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := applyChange(content, tt.change)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, hephaestus.ErrInvalidArgument)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestApplyChanges(t *testing.T) {
+	content := "a\nb\nc\nd\n"
+
+	got, err := applyChanges(content, []hephaestus.Change{
+		{StartLine: 1, NewContent: "A1\nA2"},
+		{StartLine: 3, EndLine: 4, OldContent: "c\nd", NewContent: "C"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "A1\nA2\nb\nC\n", got)
+
+	_, err = applyChanges(content, []hephaestus.Change{
+		{StartLine: 1, EndLine: 2, NewContent: "x"},
+		{StartLine: 2, EndLine: 3, NewContent: "y"},
+	})
+	assert.ErrorIs(t, err, hephaestus.ErrInvalidArgument)
+}
+
+func TestCreatePullRequest(t *testing.T) {
+	var updated []byte
+	var pull github.NewPullRequest
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/test-owner/test-repo/git/ref/heads/main", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(github.Reference{Object: &github.GitObject{SHA: github.String("base-sha")}})
+	})
+	mux.HandleFunc("/repos/test-owner/test-repo/git/refs", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(github.Reference{})
+	})
+	mux.HandleFunc("/repos/test-owner/test-repo/contents/src/main.go", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			json.NewEncoder(w).Encode(github.RepositoryContent{
+				Type:     github.String("file"),
+				Encoding: github.String("base64"),
+				SHA:      github.String("file-sha"),
+				Content:  github.String(base64.StdEncoding.EncodeToString([]byte("a\nb\nc\n"))),
+			})
+		case http.MethodPut:
+			var opts github.RepositoryContentFileOptions
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&opts))
+			updated = opts.Content
+			json.NewEncoder(w).Encode(github.RepositoryContentResponse{})
+		}
+	})
+	mux.HandleFunc("/repos/test-owner/test-repo/pulls", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&pull))
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(github.PullRequest{HTMLURL: github.String("https://github.com/test-owner/test-repo/pull/1")})
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+
+	service := &RemoteService{
+		remoteRepositoryClient: client,
+		config: &hephaestus.RemoteRepositoryConfiguration{
+			RemoteRepositoryOwner:  "test-owner",
+			RemoteRepositoryName:   "test-repo",
+			RemoteRepositoryBranch: "main",
+			BaseDirectory:          "src",
+		},
+	}
+
+	prURL, err := service.CreatePullRequest(context.Background(), "fix: boom", "body", []hephaestus.Change{
+		{FilePath: "main.go", StartLine: 2, EndLine: 2, OldContent: "b", NewContent: "B"},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "https://github.com/test-owner/test-repo/pull/1", prURL)
+	assert.Equal(t, "a\nB\nc\n", string(updated))
+	assert.Equal(t, "main", pull.GetBase())
+	assert.Contains(t, pull.GetHead(), "hephaestus/fix-")
+}
+
+func TestCreatePullRequest_SeveralChangesToOneFile(t *testing.T) {
+	var reads int
+	var updates [][]byte
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/test-owner/test-repo/git/ref/heads/main", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(github.Reference{Object: &github.GitObject{SHA: github.String("base-sha")}})
+	})
+	mux.HandleFunc("/repos/test-owner/test-repo/git/refs", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(github.Reference{})
+	})
+	mux.HandleFunc("/repos/test-owner/test-repo/contents/main.go", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			reads++
+			json.NewEncoder(w).Encode(github.RepositoryContent{
+				Type:     github.String("file"),
+				Encoding: github.String("base64"),
+				SHA:      github.String("file-sha"),
+				Content:  github.String(base64.StdEncoding.EncodeToString([]byte("a\nb\nc\nd\ne\n"))),
+			})
+		case http.MethodPut:
+			var opts github.RepositoryContentFileOptions
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&opts))
+			updates = append(updates, opts.Content)
+			json.NewEncoder(w).Encode(github.RepositoryContentResponse{})
+		}
+	})
+	mux.HandleFunc("/repos/test-owner/test-repo/pulls", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(github.PullRequest{HTMLURL: github.String("https://github.com/test-owner/test-repo/pull/1")})
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+
+	service := &RemoteService{
+		remoteRepositoryClient: client,
+		config: &hephaestus.RemoteRepositoryConfiguration{
+			RemoteRepositoryOwner:  "test-owner",
+			RemoteRepositoryName:   "test-repo",
+			RemoteRepositoryBranch: "main",
+		},
+	}
+
+	// The first change adds a line, the second still refers to the original line 4
+	_, err := service.CreatePullRequest(context.Background(), "fix: boom", "body", []hephaestus.Change{
+		{FilePath: "main.go", StartLine: 2, EndLine: 2, OldContent: "b", NewContent: "b1\nb2"},
+		{FilePath: "main.go", StartLine: 4, EndLine: 4, OldContent: "d", NewContent: "D"},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, reads)
+	require.Len(t, updates, 1)
+	assert.Equal(t, "a\nb1\nb2\nc\nD\ne\n", string(updates[0]))
+}
+
+func TestCreatePullRequest_DeletesBranchOnFailure(t *testing.T) {
+	var created, deleted string
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/test-owner/test-repo/git/ref/heads/main", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(github.Reference{Object: &github.GitObject{SHA: github.String("base-sha")}})
+	})
+	mux.HandleFunc("/repos/test-owner/test-repo/git/refs", func(w http.ResponseWriter, r *http.Request) {
+		var ref github.Reference
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&ref))
+		created = ref.GetRef()
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(github.Reference{})
+	})
+	mux.HandleFunc("/repos/test-owner/test-repo/git/refs/", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodDelete, r.Method)
+		deleted = "refs/" + strings.TrimPrefix(r.URL.Path, "/repos/test-owner/test-repo/git/refs/")
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("/repos/test-owner/test-repo/contents/main.go", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(github.RepositoryContent{
+			Type:     github.String("file"),
+			Encoding: github.String("base64"),
+			SHA:      github.String("file-sha"),
+			Content:  github.String(base64.StdEncoding.EncodeToString([]byte("a\n"))),
+		})
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+
+	service := &RemoteService{
+		remoteRepositoryClient: client,
+		config: &hephaestus.RemoteRepositoryConfiguration{
+			RemoteRepositoryOwner:  "test-owner",
+			RemoteRepositoryName:   "test-repo",
+			RemoteRepositoryBranch: "main",
+		},
+	}
+
+	// The change does not fit the file, so nothing is committed
+	_, err := service.CreatePullRequest(context.Background(), "fix: boom", "body", []hephaestus.Change{
+		{FilePath: "main.go", StartLine: 3, EndLine: 3, NewContent: "c"},
+	})
+
+	var repoErr *hephaestus.RemoteRepositoryError
+	require.ErrorAs(t, err, &repoErr)
+	assert.Equal(t, "apply_change", repoErr.Operation)
+	assert.Contains(t, created, "refs/heads/hephaestus/fix-")
+	assert.Equal(t, created, deleted)
+}
+
+func TestCreatePullRequest_NotInitialized(t *testing.T) {
+	_, err := NewRemoteService().CreatePullRequest(context.Background(), "title", "body", nil)
+	assert.Error(t, err)
+}
+
 // 	t.Run("Missing auth token", func(t *testing.T) {
 // 		invalidConfig := validConfig
 // 		invalidConfig.AuthToken = ""
@@ -298,3 +551,100 @@ func TestInitialize(t *testing.T) {
 // 		assert.Contains(t, err.Error(), "repository not found for node")
 // 	})
 // }
+
+func TestListAndFetchFiles(t *testing.T) {
+	var treeReads int
+	baseSHA := "sha-1"
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/test-owner/test-repo/git/ref/heads/main", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(github.Reference{Object: &github.GitObject{SHA: github.String(baseSHA)}})
+	})
+	mux.HandleFunc("/repos/test-owner/test-repo/git/trees/", func(w http.ResponseWriter, r *http.Request) {
+		treeReads++
+		assert.Equal(t, "/repos/test-owner/test-repo/git/trees/"+baseSHA, r.URL.Path)
+		assert.Equal(t, "1", r.URL.Query().Get("recursive"))
+		json.NewEncoder(w).Encode(github.Tree{Entries: []*github.TreeEntry{
+			{Path: github.String("src"), Type: github.String("tree")},
+			{Path: github.String("src/main.go"), Type: github.String("blob")},
+			{Path: github.String("src/orders/service.go"), Type: github.String("blob")},
+			{Path: github.String("docs/readme.md"), Type: github.String("blob")},
+		}})
+	})
+	mux.HandleFunc("/repos/test-owner/test-repo/contents/src/main.go", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "main", r.URL.Query().Get("ref"))
+		json.NewEncoder(w).Encode(github.RepositoryContent{
+			Type:     github.String("file"),
+			Encoding: github.String("base64"),
+			Content:  github.String(base64.StdEncoding.EncodeToString([]byte("package main\n"))),
+		})
+	})
+	mux.HandleFunc("/repos/test-owner/test-repo/contents/src/missing.go", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+
+	service := &RemoteService{
+		remoteRepositoryClient: client,
+		config: &hephaestus.RemoteRepositoryConfiguration{
+			RemoteRepositoryOwner:  "test-owner",
+			RemoteRepositoryName:   "test-repo",
+			RemoteRepositoryBranch: "main",
+			BaseDirectory:          "src",
+		},
+	}
+
+	files, err := service.ListFiles(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"main.go", "orders/service.go"}, files)
+
+	// The tree is read again only once the branch moves
+	_, err = service.ListFiles(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, treeReads)
+	baseSHA = "sha-2"
+	_, err = service.ListFiles(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, treeReads)
+
+	contents, err := service.FetchFiles(context.Background(), []string{"main.go", "missing.go"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"main.go": "package main\n"}, contents)
+}
+
+func TestListFiles_TruncatedTree(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/test-owner/test-repo/git/ref/heads/main", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(github.Reference{Object: &github.GitObject{SHA: github.String("sha-1")}})
+	})
+	mux.HandleFunc("/repos/test-owner/test-repo/git/trees/sha-1", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(github.Tree{
+			Entries:   []*github.TreeEntry{{Path: github.String("main.go"), Type: github.String("blob")}},
+			Truncated: github.Bool(true),
+		})
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+
+	service := &RemoteService{
+		remoteRepositoryClient: client,
+		config: &hephaestus.RemoteRepositoryConfiguration{
+			RemoteRepositoryOwner:  "test-owner",
+			RemoteRepositoryName:   "test-repo",
+			RemoteRepositoryBranch: "main",
+		},
+	}
+
+	_, err := service.ListFiles(context.Background())
+	var repoErr *hephaestus.RemoteRepositoryError
+	require.ErrorAs(t, err, &repoErr)
+	assert.Equal(t, "get_tree", repoErr.Operation)
+	assert.ErrorIs(t, err, hephaestus.ErrTruncated)
+}
@@ -2,6 +2,7 @@ package server
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
 
@@ -14,7 +15,9 @@ import (
 // Server implements the HephaestusService gRPC server
 type Server struct {
 	pb.UnimplementedHephaestusServiceServer
-	clientNode Node
+	nodeManager      hephaestus.NodeManager
+	modelService     hephaestus.ModelService
+	metricsCollector hephaestus.MetricsCollectionService
 }
 
 // NewServer creates a new instance of the HephaestusService server
@@ -48,11 +51,11 @@ func (s *Server) Start(address string) error {
 func (s *Server) RegisterNode(ctx context.Context, req *pb.RegisterNodeRequest) (*pb.RegisterNodeResponse, error) {
 	logger.Info(ctx, "Registering node", logger.Field("node_id", req.NodeId))
 
-	node := &hephaestus.Node{
+	config := &hephaestus.ClientNodeConfiguration{
 		NodeID: req.NodeId,
 	}
 
-	if err := s.nodeManager.RegisterNode(ctx, node); err != nil {
+	if _, err := s.nodeManager.RegisterNode(ctx, config); err != nil {
 		logger.Error(ctx, "Failed to register node", logger.Field("error", err))
 		return &pb.RegisterNodeResponse{
 			Status: "error",
@@ -90,6 +93,80 @@ func (s *Server) ProcessLogEntry(ctx context.Context, req *pb.ProcessLogEntryReq
 	}, nil
 }
 
+// ProcessLogBatch processes a batch of log entries from a node.
+// Entries rejected by the node are reported by their position in the request, the
+// status is "partial" when some entries were accepted and "error" when none were.
+func (s *Server) ProcessLogBatch(ctx context.Context, req *pb.ProcessLogBatchRequest) (*pb.ProcessLogBatchResponse, error) {
+	logger.Info(ctx, "Processing log batch", logger.Field("node_id", req.NodeId), logger.Field("entries", len(req.LogEntries)))
+
+	entries := make([]hephaestus.LogEntry, len(req.LogEntries))
+	for i, entry := range req.LogEntries {
+		entries[i] = hephaestus.LogEntry{
+			Level:      entry.LogLevel,
+			Message:    entry.Message,
+			ErrorTrace: entry.ErrorTrace,
+		}
+		// A missing timestamp is left zero so the node stamps it on receipt
+		if entry.Timestamp != nil {
+			entries[i].Timestamp = entry.Timestamp.AsTime()
+		}
+		// Correlation and redaction key paths read the context
+		if entry.Context != nil {
+			entries[i].Context = entry.Context.AsMap()
+		}
+	}
+
+	node, err := s.nodeManager.GetNode(req.NodeId)
+	if err != nil {
+		logger.Error(ctx, "Failed to find node", logger.Field("error", err))
+		return &pb.ProcessLogBatchResponse{
+			Status: "error",
+			Error:  err.Error(),
+		}, nil
+	}
+
+	// Entries dropped by sampling or rate limiting are neither accepted nor rejected
+	result, err := node.ProcessLogs(entries)
+	if err == nil {
+		return &pb.ProcessLogBatchResponse{
+			Status:      "success",
+			Accepted:    int32(result.Accepted),
+			Dropped:     int32(result.Dropped),
+			Unpersisted: int32(result.Unpersisted),
+		}, nil
+	}
+
+	var batchErr *hephaestus.BatchError
+	if !errors.As(err, &batchErr) {
+		logger.Error(ctx, "Failed to process log batch", logger.Field("error", err))
+		return &pb.ProcessLogBatchResponse{
+			Status: "error",
+			Error:  err.Error(),
+		}, nil
+	}
+
+	rejected := make([]*pb.RejectedLogEntry, len(batchErr.Failures))
+	for i, failure := range batchErr.Failures {
+		rejected[i] = &pb.RejectedLogEntry{
+			Index: int32(failure.Index),
+			Error: failure.Err.Error(),
+		}
+	}
+	// A batch without a single accepted entry failed as a whole
+	status := "partial"
+	if batchErr.Accepted() == 0 {
+		status = "error"
+	}
+	return &pb.ProcessLogBatchResponse{
+		Status:      status,
+		Accepted:    int32(batchErr.Accepted()),
+		Dropped:     int32(batchErr.Dropped),
+		Unpersisted: int32(result.Unpersisted),
+		Rejected:    rejected,
+		Error:       batchErr.Error(),
+	}, nil
+}
+
 // GetSolutionProposal generates a solution proposal for a log entry
 func (s *Server) GetSolutionProposal(ctx context.Context, req *pb.GetSolutionProposalRequest) (*pb.GetSolutionProposalResponse, error) {
 	logger.Info(ctx, "Generating solution proposal", logger.Field("node_id", req.NodeId))
@@ -0,0 +1,517 @@
+package wal
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/HoyeonS/hephaestus/pkg/hephaestus"
+)
+
+const (
+	// DefaultSegmentSize is the segment size used when none is configured
+	DefaultSegmentSize int64 = 16 << 20
+	// DefaultMaxSegments is the segment count that triggers compaction when none is configured
+	DefaultMaxSegments = 8
+	// DefaultSyncInterval is the background fsync interval for the interval sync policy
+	DefaultSyncInterval = time.Second
+
+	segmentSuffix = ".wal"
+	headerSize    = 9
+)
+
+// record kinds stored in the frame header
+const (
+	kindData byte = iota
+	kindCheckpointBegin
+	kindCheckpointEnd
+)
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// errCorrupt marks a torn or corrupted frame
+var errCorrupt = errors.New("corrupt record")
+
+// Log is a segmented append-only log of opaque records.
+// Each record is framed with its kind, length and CRC so a torn write at the tail is
+// detected and discarded on open. Checkpoint rewrites the live state into a fresh
+// segment and removes every older segment.
+type Log struct {
+	mu       sync.Mutex
+	dir      string
+	config   hephaestus.WriteAheadLogConfiguration
+	segments []uint64
+	file     *os.File
+	size     int64
+	dirty    bool
+	closed   bool
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// Open opens or creates a write-ahead log in the configured directory
+func Open(config hephaestus.WriteAheadLogConfiguration) (*Log, error) {
+	if config.Directory == "" {
+		return nil, fmt.Errorf("%w: write-ahead log directory is required", hephaestus.ErrInvalidConfig)
+	}
+	if config.SegmentSize <= 0 {
+		config.SegmentSize = DefaultSegmentSize
+	}
+	if config.MaxSegments <= 0 {
+		config.MaxSegments = DefaultMaxSegments
+	}
+	if config.SyncPolicy == "" {
+		config.SyncPolicy = hephaestus.SyncPolicyAlways
+	}
+	if config.SyncInterval <= 0 {
+		config.SyncInterval = DefaultSyncInterval
+	}
+
+	if err := os.MkdirAll(config.Directory, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create write-ahead log directory: %v", err)
+	}
+
+	l := &Log{dir: config.Directory, config: config}
+	if err := l.recover(); err != nil {
+		return nil, err
+	}
+
+	if config.SyncPolicy == hephaestus.SyncPolicyInterval {
+		l.stop = make(chan struct{})
+		l.done = make(chan struct{})
+		go l.syncLoop()
+	}
+
+	return l, nil
+}
+
+// recover loads the segment list, drops failed checkpoints and truncates a torn tail
+func (l *Log) recover() error {
+	segments, err := listSegments(l.dir)
+	if err != nil {
+		return err
+	}
+
+	// A checkpoint segment without its end marker is a compaction that never finished
+	kept := segments[:0]
+	for _, index := range segments {
+		info, err := scanSegment(l.segmentPath(index))
+		if err != nil {
+			return err
+		}
+		if info.checkpointBegin && !info.checkpointEnd {
+			if err := os.Remove(l.segmentPath(index)); err != nil {
+				return fmt.Errorf("failed to remove incomplete checkpoint: %v", err)
+			}
+			continue
+		}
+		kept = append(kept, index)
+	}
+	l.segments = kept
+
+	if len(l.segments) == 0 {
+		return l.openSegment(1)
+	}
+
+	last := l.segments[len(l.segments)-1]
+	info, err := scanSegment(l.segmentPath(last))
+	if err != nil {
+		return err
+	}
+	if err := os.Truncate(l.segmentPath(last), info.validSize); err != nil {
+		return fmt.Errorf("failed to truncate torn segment: %v", err)
+	}
+
+	file, err := os.OpenFile(l.segmentPath(last), os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open segment: %v", err)
+	}
+	l.file = file
+	l.size = info.validSize
+	return nil
+}
+
+// Append writes a record to the active segment, starting a new segment when it is full
+func (l *Log) Append(data []byte) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.closed {
+		return fmt.Errorf("write-ahead log is closed: %w", hephaestus.ErrUnavailable)
+	}
+
+	if l.size >= l.config.SegmentSize {
+		if err := l.rollSegment(); err != nil {
+			return err
+		}
+	}
+
+	if err := l.write(kindData, data); err != nil {
+		return err
+	}
+
+	switch l.config.SyncPolicy {
+	case hephaestus.SyncPolicyAlways:
+		return l.file.Sync()
+	case hephaestus.SyncPolicyInterval:
+		l.dirty = true
+	}
+	return nil
+}
+
+// Replay calls fn for every live record in append order.
+// Replay starts at the most recent complete checkpoint; anything before it has been compacted.
+func (l *Log) Replay(fn func(data []byte) error) error {
+	l.mu.Lock()
+	segments := append([]uint64(nil), l.segments...)
+	l.mu.Unlock()
+
+	start := 0
+	for i := len(segments) - 1; i >= 0; i-- {
+		info, err := scanSegment(l.segmentPath(segments[i]))
+		if err != nil {
+			return err
+		}
+		if info.checkpointBegin && info.checkpointEnd {
+			start = i
+			break
+		}
+	}
+
+	for _, index := range segments[start:] {
+		err := readSegment(l.segmentPath(index), func(kind byte, data []byte) error {
+			if kind != kindData {
+				return nil
+			}
+			return fn(data)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Checkpoint writes records describing the complete live state to a new segment
+// and removes every older segment
+func (l *Log) Checkpoint(records [][]byte) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.closed {
+		return fmt.Errorf("write-ahead log is closed: %w", hephaestus.ErrUnavailable)
+	}
+
+	previous := l.segments
+	previousFile, previousSize := l.file, l.size
+	index := previous[len(previous)-1] + 1
+
+	if err := l.openSegment(index); err != nil {
+		return err
+	}
+
+	err := l.writeCheckpoint(records)
+	if err != nil {
+		// Fall back to the previous segment; the partial checkpoint is ignored on replay
+		l.file.Close()
+		os.Remove(l.segmentPath(index))
+		l.segments = previous
+		l.file, l.size = previousFile, previousSize
+		return fmt.Errorf("failed to write checkpoint: %v", err)
+	}
+
+	previousFile.Close()
+	for _, old := range previous {
+		if err := os.Remove(l.segmentPath(old)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove compacted segment: %v", err)
+		}
+	}
+	l.segments = []uint64{index}
+
+	return syncDir(l.dir)
+}
+
+// writeCheckpoint writes the checkpoint frames and syncs them. Callers must hold l.mu.
+func (l *Log) writeCheckpoint(records [][]byte) error {
+	if err := l.write(kindCheckpointBegin, nil); err != nil {
+		return err
+	}
+	for _, record := range records {
+		if err := l.write(kindData, record); err != nil {
+			return err
+		}
+	}
+	if err := l.write(kindCheckpointEnd, nil); err != nil {
+		return err
+	}
+	return l.file.Sync()
+}
+
+// SegmentCount returns the number of segments on disk
+func (l *Log) SegmentCount() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.segments)
+}
+
+// NeedsCompaction reports whether the segment count exceeds the configured maximum
+func (l *Log) NeedsCompaction() bool {
+	return l.SegmentCount() > l.config.MaxSegments
+}
+
+// Sync flushes the active segment to stable storage
+func (l *Log) Sync() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.closed {
+		return nil
+	}
+	l.dirty = false
+	return l.file.Sync()
+}
+
+// Close syncs and closes the log
+func (l *Log) Close() error {
+	l.mu.Lock()
+	if l.closed {
+		l.mu.Unlock()
+		return nil
+	}
+	l.closed = true
+	l.mu.Unlock()
+
+	if l.stop != nil {
+		close(l.stop)
+		<-l.done
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.file.Sync(); err != nil {
+		l.file.Close()
+		return err
+	}
+	return l.file.Close()
+}
+
+// syncLoop fsyncs dirty segments for the interval sync policy
+func (l *Log) syncLoop() {
+	defer close(l.done)
+
+	ticker := time.NewTicker(l.config.SyncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			l.mu.Lock()
+			if l.dirty && !l.closed {
+				l.file.Sync()
+				l.dirty = false
+			}
+			l.mu.Unlock()
+		case <-l.stop:
+			return
+		}
+	}
+}
+
+// write frames a record onto the active segment. Callers must hold l.mu.
+func (l *Log) write(kind byte, data []byte) error {
+	frame := make([]byte, headerSize+len(data))
+	frame[0] = kind
+	binary.BigEndian.PutUint32(frame[1:5], uint32(len(data)))
+	binary.BigEndian.PutUint32(frame[5:9], checksum(kind, data))
+	copy(frame[headerSize:], data)
+
+	n, err := l.file.Write(frame)
+	l.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("failed to append record: %v", err)
+	}
+	return nil
+}
+
+// rollSegment closes the active segment and starts the next one. Callers must hold l.mu.
+func (l *Log) rollSegment() error {
+	if err := l.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync segment: %v", err)
+	}
+	if err := l.file.Close(); err != nil {
+		return fmt.Errorf("failed to close segment: %v", err)
+	}
+	return l.openSegment(l.segments[len(l.segments)-1] + 1)
+}
+
+// openSegment creates a new segment and makes it active. Callers must hold l.mu.
+func (l *Log) openSegment(index uint64) error {
+	file, err := os.OpenFile(l.segmentPath(index), os.O_WRONLY|os.O_CREATE|os.O_EXCL|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create segment: %v", err)
+	}
+
+	l.file = file
+	l.size = 0
+	l.segments = append(l.segments, index)
+	return syncDir(l.dir)
+}
+
+// segmentPath returns the file path of a segment
+func (l *Log) segmentPath(index uint64) string {
+	return filepath.Join(l.dir, fmt.Sprintf("%020d%s", index, segmentSuffix))
+}
+
+// segmentInfo summarizes a segment scan
+type segmentInfo struct {
+	validSize       int64
+	checkpointBegin bool
+	checkpointEnd   bool
+}
+
+// scanSegment finds the valid prefix of a segment and its checkpoint markers
+func scanSegment(path string) (segmentInfo, error) {
+	var info segmentInfo
+	first := true
+	err := readSegmentFrames(path, func(kind byte, data []byte, end int64) error {
+		if first && kind == kindCheckpointBegin {
+			info.checkpointBegin = true
+		}
+		if kind == kindCheckpointEnd {
+			info.checkpointEnd = true
+		}
+		first = false
+		info.validSize = end
+		return nil
+	})
+	return info, err
+}
+
+// readSegment calls fn for every valid frame of a segment, stopping at the first torn frame
+func readSegment(path string, fn func(kind byte, data []byte) error) error {
+	return readSegmentFrames(path, func(kind byte, data []byte, end int64) error {
+		return fn(kind, data)
+	})
+}
+
+// readSegmentFrames decodes frames and reports the offset after each one
+func readSegmentFrames(path string, fn func(kind byte, data []byte, end int64) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open segment: %v", err)
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to read segment: %v", err)
+	}
+
+	reader := bufio.NewReader(file)
+	var offset int64
+	header := make([]byte, headerSize)
+	for {
+		kind, data, err := readFrame(reader, header, stat.Size()-offset)
+		if err == io.EOF || errors.Is(err, errCorrupt) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read segment: %v", err)
+		}
+
+		offset += int64(headerSize + len(data))
+		if err := fn(kind, data, offset); err != nil {
+			return err
+		}
+	}
+}
+
+// readFrame reads a single frame of at most remaining bytes, reporting errCorrupt for
+// torn or mismatching frames. The length is checked before the payload is allocated so
+// a corrupted header cannot claim more memory than the segment holds.
+func readFrame(reader io.Reader, header []byte, remaining int64) (byte, []byte, error) {
+	if _, err := io.ReadFull(reader, header); err != nil {
+		if err == io.EOF {
+			return 0, nil, io.EOF
+		}
+		if err == io.ErrUnexpectedEOF {
+			return 0, nil, errCorrupt
+		}
+		return 0, nil, err
+	}
+
+	kind := header[0]
+	length := binary.BigEndian.Uint32(header[1:5])
+	sum := binary.BigEndian.Uint32(header[5:9])
+	if kind > kindCheckpointEnd || int64(length) > remaining-headerSize {
+		return 0, nil, errCorrupt
+	}
+
+	data := make([]byte, length)
+	if _, err := io.ReadFull(reader, data); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return 0, nil, errCorrupt
+		}
+		return 0, nil, err
+	}
+	if checksum(kind, data) != sum {
+		return 0, nil, errCorrupt
+	}
+
+	return kind, data, nil
+}
+
+// checksum covers the record kind and payload
+func checksum(kind byte, data []byte) uint32 {
+	crc := crc32.Update(0, crcTable, []byte{kind})
+	return crc32.Update(crc, crcTable, data)
+}
+
+// listSegments returns the segment indexes in a directory in ascending order
+func listSegments(dir string) ([]uint64, error) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list segments: %v", err)
+	}
+
+	var segments []uint64
+	for _, file := range files {
+		name := file.Name()
+		if file.IsDir() || !strings.HasSuffix(name, segmentSuffix) {
+			continue
+		}
+		index, err := strconv.ParseUint(strings.TrimSuffix(name, segmentSuffix), 10, 64)
+		if err != nil {
+			continue
+		}
+		segments = append(segments, index)
+	}
+	sort.Slice(segments, func(i, j int) bool { return segments[i] < segments[j] })
+
+	return segments, nil
+}
+
+// syncDir flushes directory entries so segment creation and removal survive a crash
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	// Directory sync is best effort, some platforms do not support it
+	d.Sync()
+	return nil
+}
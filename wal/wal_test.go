@@ -0,0 +1,144 @@
+package wal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/HoyeonS/hephaestus/pkg/hephaestus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func replayAll(t *testing.T, l *Log) []string {
+	var records []string
+	require.NoError(t, l.Replay(func(data []byte) error {
+		records = append(records, string(data))
+		return nil
+	}))
+	return records
+}
+
+func TestLog_AppendAndReplay(t *testing.T) {
+	config := hephaestus.WriteAheadLogConfiguration{Directory: t.TempDir()}
+
+	l, err := Open(config)
+	require.NoError(t, err)
+	require.NoError(t, l.Append([]byte("a")))
+	require.NoError(t, l.Append([]byte("b")))
+	require.NoError(t, l.Close())
+
+	reopened, err := Open(config)
+	require.NoError(t, err)
+	defer reopened.Close()
+
+	require.NoError(t, reopened.Append([]byte("c")))
+	assert.Equal(t, []string{"a", "b", "c"}, replayAll(t, reopened))
+}
+
+func TestLog_TornTailIsDiscarded(t *testing.T) {
+	config := hephaestus.WriteAheadLogConfiguration{Directory: t.TempDir(), SyncPolicy: hephaestus.SyncPolicyNone}
+
+	l, err := Open(config)
+	require.NoError(t, err)
+	require.NoError(t, l.Append([]byte("complete")))
+	require.NoError(t, l.Close())
+
+	// Simulate a crash in the middle of a write
+	segment := filepath.Join(config.Directory, fmt.Sprintf("%020d%s", 1, segmentSuffix))
+	file, err := os.OpenFile(segment, os.O_WRONLY|os.O_APPEND, 0600)
+	require.NoError(t, err)
+	_, err = file.Write([]byte{kindData, 0, 0, 0, 10, 1, 2})
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	reopened, err := Open(config)
+	require.NoError(t, err)
+	defer reopened.Close()
+
+	require.NoError(t, reopened.Append([]byte("after")))
+	assert.Equal(t, []string{"complete", "after"}, replayAll(t, reopened))
+}
+
+func TestLog_OversizedFrameIsCorrupt(t *testing.T) {
+	config := hephaestus.WriteAheadLogConfiguration{Directory: t.TempDir(), SyncPolicy: hephaestus.SyncPolicyNone}
+
+	l, err := Open(config)
+	require.NoError(t, err)
+	require.NoError(t, l.Append([]byte("complete")))
+	require.NoError(t, l.Close())
+
+	// A corrupted header claiming a 4 GiB payload is discarded like a torn write
+	segment := filepath.Join(config.Directory, fmt.Sprintf("%020d%s", 1, segmentSuffix))
+	file, err := os.OpenFile(segment, os.O_WRONLY|os.O_APPEND, 0600)
+	require.NoError(t, err)
+	_, err = file.Write([]byte{kindData, 0xff, 0xff, 0xff, 0xff, 0, 0, 0, 0, 'x'})
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	reopened, err := Open(config)
+	require.NoError(t, err)
+	defer reopened.Close()
+
+	assert.Equal(t, []string{"complete"}, replayAll(t, reopened))
+}
+
+func TestLog_SegmentRollAndCheckpoint(t *testing.T) {
+	config := hephaestus.WriteAheadLogConfiguration{
+		Directory:   t.TempDir(),
+		SegmentSize: 20,
+		MaxSegments: 2,
+		SyncPolicy:  hephaestus.SyncPolicyInterval,
+	}
+
+	l, err := Open(config)
+	require.NoError(t, err)
+	defer l.Close()
+
+	for i := 0; i < 6; i++ {
+		require.NoError(t, l.Append([]byte(fmt.Sprintf("record-%d", i))))
+	}
+	assert.Equal(t, 3, l.SegmentCount())
+	assert.True(t, l.NeedsCompaction())
+
+	require.NoError(t, l.Checkpoint([][]byte{[]byte("state")}))
+	assert.Equal(t, 1, l.SegmentCount())
+	assert.False(t, l.NeedsCompaction())
+
+	require.NoError(t, l.Append([]byte("next")))
+	assert.Equal(t, []string{"state", "next"}, replayAll(t, l))
+
+	segments, err := listSegments(config.Directory)
+	require.NoError(t, err)
+	assert.Len(t, segments, l.SegmentCount())
+}
+
+func TestLog_IncompleteCheckpointIsIgnored(t *testing.T) {
+	config := hephaestus.WriteAheadLogConfiguration{Directory: t.TempDir()}
+
+	l, err := Open(config)
+	require.NoError(t, err)
+	require.NoError(t, l.Append([]byte("a")))
+	require.NoError(t, l.Close())
+
+	// A checkpoint that crashed before writing its end marker
+	segment := filepath.Join(config.Directory, fmt.Sprintf("%020d%s", 2, segmentSuffix))
+	frame := make([]byte, headerSize)
+	frame[0] = kindCheckpointBegin
+	crc := checksum(kindCheckpointBegin, nil)
+	frame[5], frame[6], frame[7], frame[8] = byte(crc>>24), byte(crc>>16), byte(crc>>8), byte(crc)
+	require.NoError(t, os.WriteFile(segment, frame, 0600))
+
+	reopened, err := Open(config)
+	require.NoError(t, err)
+	defer reopened.Close()
+
+	assert.Equal(t, 1, reopened.SegmentCount())
+	assert.Equal(t, []string{"a"}, replayAll(t, reopened))
+}
+
+func TestOpen_RequiresDirectory(t *testing.T) {
+	_, err := Open(hephaestus.WriteAheadLogConfiguration{})
+	assert.ErrorIs(t, err, hephaestus.ErrInvalidConfig)
+}